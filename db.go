@@ -7,7 +7,9 @@ package orm
 import (
 	"context"
 	"database/sql"
-	"strings"
+
+	"github.com/issue9/orm/model"
+	"github.com/issue9/orm/sqlbuilder"
 )
 
 // DB 数据库操作实例。
@@ -15,8 +17,10 @@ type DB struct {
 	stdDB       *sql.DB
 	dialect     Dialect
 	tablePrefix string
-	replacer    *strings.Replacer
 	sql         *SQL
+
+	// 由 Dialect.QuoteTuple() 返回的引号对
+	leftQuote, rightQuote byte
 }
 
 // NewDB 声明一个新的 DB 实例。
@@ -36,17 +40,19 @@ func NewDBWithStdDB(db *sql.DB, tablePrefix string, dialect Dialect) (*DB, error
 		stdDB:       db,
 		dialect:     dialect,
 		tablePrefix: tablePrefix,
-		replacer: strings.NewReplacer(
-			"#", tablePrefix,
-			"{", string(l),
-			"}", string(r),
-		),
+		leftQuote:   l,
+		rightQuote:  r,
 	}
 	inst.sql = &SQL{engine: inst}
 
 	return inst, nil
 }
 
+// 替换 query 中的 # 和 {} 占位符
+func (db *DB) replace(query string) string {
+	return sqlbuilder.ReplaceQuotes(query, db.leftQuote, db.rightQuote, db.tablePrefix)
+}
+
 // Close 关闭当前数据库，释放所有的链接。
 //
 // 关闭之后，之前通过 DB.StdDB() 返回的实例也将失效。
@@ -68,18 +74,12 @@ func (db *DB) Dialect() Dialect {
 // Query 执行一条查询语句，并返回相应的 sql.Rows 实例。
 // 具体参数说明可参考 Engine 接口文档。
 func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return db.stdDB.Query(query, args...)
+	return db.QueryContext(context.Background(), query, args...)
 }
 
 // QueryContext 执行一条查询语句，并返回相应的 sql.Rows 实例。
 func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	query = db.replacer.Replace(query)
+	query = db.replace(query)
 	query, err := db.dialect.SQL(query)
 	if err != nil {
 		return nil, err
@@ -90,18 +90,12 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{
 
 // Exec 执行 SQL 语句。
 func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return db.stdDB.Exec(query, args...)
+	return db.ExecContext(context.Background(), query, args...)
 }
 
 // ExecContext 执行 SQL 语句。
 func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	query = db.replacer.Replace(query)
+	query = db.replace(query)
 	query, err := db.dialect.SQL(query)
 	if err != nil {
 		return nil, err
@@ -112,18 +106,12 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}
 
 // Prepare 预编译查询语句。
 func (db *DB) Prepare(query string) (*sql.Stmt, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return db.stdDB.Prepare(query)
+	return db.PrepareContext(context.Background(), query)
 }
 
 // PrepareContext 预编译查询语句。
 func (db *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
-	query = db.replacer.Replace(query)
+	query = db.replace(query)
 	query, err := db.dialect.SQL(query)
 	if err != nil {
 		return nil, err
@@ -169,10 +157,18 @@ func (db *DB) Count(v interface{}) (int64, error) {
 	return count(db, v)
 }
 
-// Create 创建一张表。
+// Create 创建一张表，表已经存在时不作任何操作。
 func (db *DB) Create(v interface{}) error {
+	return db.CreateTable(v, true)
+}
+
+// CreateTable 创建一张表
+//
+// ifNotExists 为 false 时，若表已经存在则会返回错误，
+// 可以避免在不知情的情况下误用了一张已经存在的表。
+func (db *DB) CreateTable(v interface{}, ifNotExists bool) error {
 	if !db.Dialect().TransactionalDDL() {
-		return create(db, v)
+		return create(db, v, ifNotExists)
 	}
 
 	tx, err := db.Begin()
@@ -180,7 +176,7 @@ func (db *DB) Create(v interface{}) error {
 		return err
 	}
 
-	if err = create(tx, v); err != nil {
+	if err = create(tx, v, ifNotExists); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -188,6 +184,16 @@ func (db *DB) Create(v interface{}) error {
 	return tx.Commit()
 }
 
+// ReverseTable 以数据库中表的当前状态生成 v 的 Model
+//
+// 返回的 Model 不会被缓存，自增列的起始值为该表中自增列下一个值，
+// 以此 Model 通过 Dialect.CreateTableSQL 重新创建的表，自增列不会从头开始计数，
+// 可用于以新表替换旧表等场景。
+// 若当前数据库不支持读取自增列的值，则返回 sqlbuilder.ErrNotSupported。
+func (db *DB) ReverseTable(v interface{}) (*model.Model, error) {
+	return reverseTable(db, v)
+}
+
 // Drop 删除一张表。
 func (db *DB) Drop(v interface{}) error {
 	return drop(db, v)
@@ -5,8 +5,10 @@
 package orm_test
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/conv"
@@ -14,6 +16,9 @@ import (
 	"github.com/issue9/orm/dialect"
 	"github.com/issue9/orm/fetch"
 	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/internal/sqltest"
+	"github.com/issue9/orm/model"
+	"github.com/issue9/orm/sqlbuilder"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
@@ -65,7 +70,7 @@ func newDB(a *assert.Assertion) *orm.DB {
 
 // table表中是否存在 size 条记录，若不是，则触发 error
 func hasCount(db orm.Engine, a *assert.Assertion, table string, size int) {
-	rows, err := db.Query("SELECT COUNT(*) as cnt FROM #" + table)
+	rows, err := db.Query("SELECT COUNT(*) as cnt FROM {#" + table + "}")
 	a.NotError(err).NotNil(rows)
 	defer func() {
 		a.NotError(rows.Close())
@@ -200,6 +205,78 @@ func TestDB_Update(t *testing.T) {
 	a.Equal(u2, &modeltest.UserInfo{UID: 2, FirstName: "firstName2", LastName: "lastName2", Sex: "sex2"})
 }
 
+type updatedObj struct {
+	ID      int64     `orm:"name(id);ai"`
+	Name    string    `orm:"name(name);len(20)"`
+	Updated time.Time `orm:"name(updated);updated"`
+}
+
+func TestDB_SQL_UpdateWhere(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	stmt, err := db.SQL().UpdateWhere(&updatedObj{})
+	a.NotError(err).NotNil(stmt)
+	query, args, err := stmt.Set("{name}", "n1").Where("{id}>?", 5).SQL()
+	a.NotError(err)
+	a.Equal(3, len(args))
+	sqltest.Equal(a, query, "UPDATE {#updatedObj} SET {name}=?,{updated}=? WHERE {id}>?")
+
+	// 非结构体
+	stmt, err = db.SQL().UpdateWhere(5)
+	a.Error(err).Nil(stmt)
+}
+
+type orderedObj struct {
+	Zone string `orm:"name(zone);len(20)"`
+	Age  int    `orm:"name(age)"`
+	Name string `orm:"name(name);len(20)"`
+}
+
+func TestDB_SQL_SelectWhere(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	stmt, err := db.SQL().SelectWhere(&updatedObj{}, "id", "name")
+	a.NotError(err).NotNil(stmt)
+	query, args, err := stmt.Where("{id}>?", 5).Desc("{id}").Limit(10, 20).SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{5, 10, 20})
+	sqltest.Equal(a, query, "SELECT {id},{name} FROM {#updatedObj} WHERE {id}>? ORDER BY {id} DESC LIMIT ? OFFSET ?")
+
+	// 所有列
+	stmt, err = db.SQL().SelectWhere(&updatedObj{})
+	a.NotError(err).NotNil(stmt)
+	query, _, err = stmt.SQL()
+	a.NotError(err)
+	sqltest.Equal(a, query, "SELECT {id},{name},{updated} FROM {#updatedObj}")
+
+	// 按字段的顺序查询所有列
+	stmt, err = db.SQL().SelectWhere(&orderedObj{})
+	a.NotError(err).NotNil(stmt)
+	query, _, err = stmt.SQL()
+	a.NotError(err)
+	sqltest.Equal(a, query, "SELECT {zone},{age},{name} FROM {#orderedObj}")
+
+	// 不存在的列
+	stmt, err = db.SQL().SelectWhere(&updatedObj{}, "id", "not-exists")
+	a.Error(err).Nil(stmt)
+
+	// 非结构体
+	stmt, err = db.SQL().SelectWhere(5)
+	a.Error(err).Nil(stmt)
+}
+
 func TestDB_Delete(t *testing.T) {
 	a := assert.New(t)
 
@@ -284,6 +361,431 @@ func TestDB_Truncate(t *testing.T) {
 	a.Equal(1, a1.ID)
 }
 
+func TestDB_ExistsTableSQL(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	exists := func(table string) int {
+		query, args := db.Dialect().ExistsTableSQL("", table)
+		rows, err := db.Query(query, args...)
+		a.NotError(err).NotNil(rows)
+		defer func() {
+			a.NotError(rows.Close())
+		}()
+
+		a.True(rows.Next())
+		var cnt int
+		a.NotError(rows.Scan(&cnt))
+		return cnt
+	}
+
+	a.Equal(exists("#user_info"), 1)
+	a.Equal(exists("#not_exists"), 0)
+}
+
+type noAI struct {
+	ID   int64  `orm:"name(id);pk"`
+	Name string `orm:"name(name);len(20)"`
+}
+
+func TestDB_ReverseTable(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	// 没有自增列，不需要读取数据库
+	m, err := db.ReverseTable(&noAI{})
+	a.NotError(err).NotNil(m)
+
+	if driver != "mysql" {
+		m, err = db.ReverseTable(&modeltest.Group{})
+		a.Equal(err, sqlbuilder.ErrNotSupported).Nil(m)
+		return
+	}
+
+	// mysql 8.0 之后需要关闭 information_schema 的缓存才能读取到实时的值
+	_, err = db.Exec("SET SESSION information_schema_stats_expiry=0")
+	a.NotError(err)
+
+	a.NotError(db.Create(&modeltest.Group{}))
+	for i := 0; i < 3; i++ {
+		_, err = db.Insert(&modeltest.Group{Name: "group"})
+		a.NotError(err)
+	}
+
+	m, err = db.ReverseTable(&modeltest.Group{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.AI.AIStart, int64(4))
+	cached, err := model.New(&modeltest.Group{})
+	a.NotError(err).NotEqual(cached.AI.AIStart, int64(4)) // 不影响缓存中的 Model
+
+	// 删除之后以 m 重新创建，自增列接着原来的值计数
+	a.NotError(db.Drop(&modeltest.Group{}))
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Group{}))
+	}()
+	sqls, err := db.Dialect().CreateTableSQL(m, false)
+	a.NotError(err)
+	for _, query := range sqls {
+		_, err = db.Exec(query)
+		a.NotError(err)
+	}
+
+	r, err := db.Insert(&modeltest.Group{Name: "group"})
+	a.NotError(err)
+	id, err := r.LastInsertId()
+	a.NotError(err).Equal(id, 4)
+}
+
+// 指定了 schema 的表，各数据库都使用其默认的 schema。
+type schemaUser struct {
+	ID   int64  `orm:"name(id);ai"`
+	Name string `orm:"name(name);len(20);index(index_name)"`
+}
+
+func (u *schemaUser) Meta() string {
+	switch driver {
+	case "mysql":
+		return "name(schema_users);schema(orm_test)"
+	case "postgres":
+		return "name(schema_users);schema(public)"
+	default:
+		return "name(schema_users);schema(main)"
+	}
+}
+
+func TestDB_schema(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer closeDB(a)
+
+	a.NotError(db.Create(&schemaUser{}))
+	defer func() {
+		a.NotError(db.Drop(&schemaUser{}))
+	}()
+
+	_, err := db.Insert(&schemaUser{Name: "u1"})
+	a.NotError(err)
+	_, err = db.Insert(&schemaUser{Name: "u2"})
+	a.NotError(err)
+	hasCount(db, a, "schema_users", 2)
+
+	u := &schemaUser{ID: 1}
+	a.NotError(db.Select(u))
+	a.Equal(u.Name, "u1")
+
+	_, err = db.Update(&schemaUser{ID: 1, Name: "u11"})
+	a.NotError(err)
+	u = &schemaUser{ID: 1}
+	a.NotError(db.Select(u))
+	a.Equal(u.Name, "u11")
+
+	_, err = db.Delete(&schemaUser{ID: 2})
+	a.NotError(err)
+	cnt, err := db.Count(&schemaUser{Name: "u11"})
+	a.NotError(err).Equal(cnt, 1)
+
+	a.NotError(db.Truncate(&schemaUser{}))
+	hasCount(db, a, "schema_users", 0)
+
+	// 清空之后，自增列从头开始
+	_, err = db.Insert(&schemaUser{Name: "u3"})
+	a.NotError(err)
+	u = &schemaUser{ID: 1}
+	a.NotError(db.Select(u))
+	a.Equal(u.Name, "u3")
+}
+
+func TestDB_Context(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, err := db.QueryContext(ctx, "SELECT * FROM #user_info")
+	a.NotError(err).NotNil(rows)
+	a.NotError(rows.Close())
+
+	// 已经取消的 ctx
+	cancel()
+	rows, err = db.QueryContext(ctx, "SELECT * FROM #user_info")
+	a.Error(err).Nil(rows)
+	_, err = db.ExecContext(ctx, "DELETE FROM #user_info")
+	a.Error(err)
+	tx, err := db.BeginTx(ctx, nil)
+	a.Error(err).Nil(tx)
+
+	tx, err = db.BeginTx(context.Background(), nil)
+	a.NotError(err).NotNil(tx)
+	a.NotError(tx.Rollback())
+}
+
+// 模拟不支持 LastInsertId() 的数据库
+type returningDialect struct {
+	orm.Dialect
+}
+
+func (d *returningDialect) LastInsertIDSupported() bool {
+	return false
+}
+
+func TestDB_Insert_returning(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+	if driver != "sqlite3" {
+		return
+	}
+
+	// RETURNING 需要 3.35.0 之后的 sqlite3
+	var version string
+	a.NotError(db.StdDB().QueryRow("SELECT sqlite_version()").Scan(&version))
+	if !d.(orm.VersionedDialect).SupportsFeature(orm.FeatureReturning, version) {
+		return
+	}
+
+	rdb, err := orm.NewDB(driver, dsn, prefix, &returningDialect{Dialect: d})
+	a.NotError(err).NotNil(rdb)
+	defer func() {
+		a.NotError(rdb.Drop(&modeltest.Group{}))
+		a.NotError(rdb.Close())
+	}()
+	a.NotError(rdb.Create(&modeltest.Group{}))
+
+	for i := int64(1); i <= 2; i++ {
+		r, err := rdb.Insert(&modeltest.Group{Name: "group"})
+		a.NotError(err).NotNil(r)
+		id, err := r.LastInsertId()
+		a.NotError(err).Equal(id, i)
+		cnt, err := r.RowsAffected()
+		a.NotError(err).Equal(cnt, 1)
+	}
+
+	// 指定了自增列的值，不需要 RETURNING
+	r, err := rdb.Insert(&modeltest.Group{ID: 10, Name: "group"})
+	a.NotError(err).NotNil(r)
+	hasCount(rdb, a, "groups", 3)
+}
+
+type bytesObj struct {
+	ID   int64  `orm:"name(id);ai"`
+	Data []byte `orm:"name(data);len(50)"`
+}
+
+// 切片等类型无法通过 == 判断零值
+func TestDB_Insert_uncomparable(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	a.NotError(db.Create(&bytesObj{}))
+	defer func() {
+		a.NotError(db.Drop(&bytesObj{}))
+	}()
+
+	r, err := db.Insert(&bytesObj{Data: []byte("abc")})
+	a.NotError(err).NotNil(r)
+
+	count, err := db.Count(&bytesObj{Data: []byte("abc")})
+	a.NotError(err).Equal(1, count)
+
+	obj := &bytesObj{ID: 1, Data: []byte("def")}
+	_, err = db.Update(obj)
+	a.NotError(err)
+	count, err = db.Count(&bytesObj{Data: []byte("def")})
+	a.NotError(err).Equal(1, count)
+}
+
+type serializeAttr struct {
+	Tags []string
+}
+
+type serializeObj struct {
+	ID   int64         `orm:"name(id);ai"`
+	JSON serializeAttr `orm:"name(json);serialize(json)"`
+	Gob  serializeAttr `orm:"name(gob);serialize(gob)"`
+}
+
+func TestDB_Insert_serialize(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	a.NotError(db.Create(&serializeObj{}))
+	defer func() {
+		a.NotError(db.Drop(&serializeObj{}))
+	}()
+
+	attr := serializeAttr{Tags: []string{"t1", "t2"}}
+	r, err := db.Insert(&serializeObj{JSON: attr, Gob: attr})
+	a.NotError(err).NotNil(r)
+
+	rows, err := db.Query("SELECT {json} FROM {#serializeObj}")
+	a.NotError(err).NotNil(rows)
+	data, err := fetch.ColumnString(true, "json", rows)
+	a.NotError(err)
+	a.NotError(rows.Close())
+	a.Equal(data, []string{`{"Tags":["t1","t2"]}`})
+
+	count, err := db.Count(&serializeObj{JSON: attr})
+	a.NotError(err).Equal(1, count)
+	count, err = db.Count(&serializeObj{Gob: attr})
+	a.NotError(err).Equal(1, count)
+
+	attr2 := serializeAttr{Tags: []string{"t3"}}
+	_, err = db.Update(&serializeObj{ID: 1, JSON: attr2, Gob: attr2})
+	a.NotError(err)
+	count, err = db.Count(&serializeObj{JSON: attr2, Gob: attr2})
+	a.NotError(err).Equal(1, count)
+}
+
+type mapObj struct {
+	ID   int64             `orm:"name(id);ai"`
+	Opts map[string]string `orm:"name(opts);serialize(json)"`
+}
+
+func TestDB_Insert_map(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	a.NotError(db.Create(&mapObj{}))
+	defer func() {
+		a.NotError(db.Drop(&mapObj{}))
+	}()
+
+	r, err := db.Insert(&mapObj{Opts: map[string]string{"x": "y", "a": "b"}})
+	a.NotError(err).NotNil(r)
+	r, err = db.Insert(&mapObj{Opts: map[string]string{"x": "z"}})
+	a.NotError(err).NotNil(r)
+
+	count, err := db.Count(&mapObj{Opts: map[string]string{"a": "b", "x": "y"}})
+	a.NotError(err).Equal(1, count)
+	count, err = db.Count(&mapObj{Opts: map[string]string{}})
+	a.NotError(err).Equal(0, count)
+}
+
+type pgArrayObj struct {
+	ID   int64    `orm:"name(id);ai"`
+	IDs  []int64  `orm:"name(ids);pgarray"`
+	Tags []string `orm:"name(tags);pgarray"`
+}
+
+func TestDB_Insert_pgarray(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	// 仅 postgres 支持创建数组类型的列，其它数据库以文本代替，用于检测写入的内容。
+	if driver == "postgres" {
+		a.NotError(db.Create(&pgArrayObj{}))
+	} else {
+		_, err := db.Exec("CREATE TABLE {#pgArrayObj}({id} INTEGER PRIMARY KEY, {ids} TEXT NOT NULL, {tags} TEXT NOT NULL)")
+		a.NotError(err)
+	}
+	defer func() {
+		a.NotError(db.Drop(&pgArrayObj{}))
+	}()
+
+	r, err := db.Insert(&pgArrayObj{IDs: []int64{1, 2, 3}, Tags: []string{"a,b", `c"d`}})
+	a.NotError(err).NotNil(r)
+
+	count, err := db.Count(&pgArrayObj{IDs: []int64{1, 2, 3}})
+	a.NotError(err).Equal(1, count)
+
+	if driver == "postgres" {
+		return
+	}
+
+	rows, err := db.Query("SELECT {ids},{tags} FROM {#pgArrayObj}")
+	a.NotError(err).NotNil(rows)
+	data, err := fetch.MapString(true, rows)
+	a.NotError(err)
+	a.NotError(rows.Close())
+	a.Equal(data[0]["ids"], "{1,2,3}").
+		Equal(data[0]["tags"], `{"a,b","c\"d"}`)
+}
+
+func TestDB_CreateTable(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	a.NotError(db.CreateTable(&modeltest.Group{}, false))
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Group{}))
+	}()
+
+	// 表已经存在
+	a.NotError(db.Create(&modeltest.Group{}))
+	a.NotError(db.CreateTable(&modeltest.Group{}, true))
+	a.Error(db.CreateTable(&modeltest.Group{}, false))
+
+	tx, err := db.Begin()
+	a.NotError(err).NotNil(tx)
+	a.Error(tx.CreateTable(&modeltest.Group{}, false))
+	a.NotError(tx.Rollback())
+}
+
+func TestDB_CreateViewSQL(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	query := db.Dialect().CreateViewSQL("user_view", "SELECT {uid} FROM {#user_info}", false)
+	_, err := db.Exec(query)
+	a.NotError(err)
+	defer func() {
+		_, err := db.Exec("DROP VIEW {#user_view}")
+		a.NotError(err)
+	}()
+
+	// 替换已经存在的视图
+	query = db.Dialect().CreateViewSQL("user_view", "SELECT {uid},{sex} FROM {#user_info}", true)
+	_, err = db.Exec(query)
+	a.NotError(err)
+
+	rows, err := db.Query("SELECT {sex} FROM {#user_view}")
+	a.NotError(err).NotNil(rows)
+	a.NotError(rows.Close())
+}
+
 func TestDB_Drop(t *testing.T) {
 	a := assert.New(t)
 
@@ -296,3 +798,44 @@ func TestDB_Drop(t *testing.T) {
 	r, err := db.Insert(&modeltest.Admin{})
 	a.Error(err).Nil(r)
 }
+
+// 表名和列名都为 SQL 关键字
+type keyword struct {
+	Select int64  `orm:"name(select);ai"`
+	From   string `orm:"name(from);len(20);index(index_from)"`
+	Group  int64  `orm:"name(group)"`
+}
+
+func (k *keyword) Meta() string {
+	return "name(order)"
+}
+
+func TestDB_keywords(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&keyword{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	a.NotError(db.Drop(&keyword{}))
+	a.NotError(db.Create(&keyword{}))
+
+	_, err := db.Insert(&keyword{From: "from", Group: 1})
+	a.NotError(err)
+
+	_, err = db.Update(&keyword{Select: 1, From: "from1", Group: 2})
+	a.NotError(err)
+
+	k := &keyword{Select: 1}
+	a.NotError(db.Select(k))
+	a.Equal(k, &keyword{Select: 1, From: "from1", Group: 2})
+
+	cnt, err := db.Count(&keyword{Group: 2})
+	a.NotError(err).Equal(cnt, 1)
+
+	a.NotError(db.Truncate(&keyword{}))
+	hasCount(db, a, "order", 0)
+}
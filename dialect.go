@@ -0,0 +1,51 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/issue9/orm/model"
+)
+
+// 通过 RegisterDialect 注册的 Dialect
+var (
+	dialects    = map[string]Dialect{}
+	dialectsMux sync.RWMutex
+)
+
+// RegisterDialect 以 name 为名称注册 Dialect 实例 d
+//
+// 之后可以通过 GetDialect 以名称获取该实例，方便通过配置文件等选择数据库。
+// dialect 包中的内置 Dialect 会以 Dialect.Name() 的返回值自动注册。
+// 与 database/sql.Register 相同，d 为 nil 或是 name 已经被注册时会 panic。
+//
+// d.Name() 会同时通过 model.RegisterDialectName 注册，以便在 type(dialect:type) 中使用。
+func RegisterDialect(name string, d Dialect) {
+	if d == nil {
+		panic("参数 d 不能为空")
+	}
+
+	dialectsMux.Lock()
+	defer dialectsMux.Unlock()
+
+	if _, found := dialects[name]; found {
+		panic(fmt.Sprintf("已经存在名为 %s 的 Dialect", name))
+	}
+	dialects[name] = d
+	model.RegisterDialectName(d.Name())
+}
+
+// GetDialect 获取通过 RegisterDialect 注册的 Dialect 实例
+//
+// 第二个返回值表示是否找到该名称的 Dialect。
+func GetDialect(name string) (Dialect, bool) {
+	dialectsMux.RLock()
+	defer dialectsMux.RUnlock()
+
+	d, found := dialects[name]
+	return d, found
+}
@@ -7,7 +7,15 @@ package dialect
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/issue9/orm"
@@ -25,6 +33,108 @@ var (
 	timeType    = reflect.TypeOf(time.Time{})
 )
 
+// 判断版本号 version 是否大于等于 min
+//
+// 版本号为以点分隔的数字，比如 5.7.8，每一段中数字之后的内容会被忽略，
+// 比如 8.0.16-log 等同于 8.0.16。version 为空表示最新版本，总是返回 true。
+func versionAtLeast(version, min string) bool {
+	if version == "" {
+		return true
+	}
+
+	v1 := strings.Split(version, ".")
+	v2 := strings.Split(min, ".")
+	for i := 0; i < len(v1) || i < len(v2); i++ {
+		n1, n2 := versionPart(v1, i), versionPart(v2, i)
+		if n1 != n2 {
+			return n1 > n2
+		}
+	}
+
+	return true
+}
+
+// 获取 parts[i] 中开头的数字部分，不存在则返回 0
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+
+	p := parts[i]
+	end := 0
+	for end < len(p) && p[end] >= '0' && p[end] <= '9' {
+		end++
+	}
+
+	n, err := strconv.Atoi(p[:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// 根据 features 判断是否支持该特性，features 的键名为特性名称，键值为支持该特性的最低版本。
+func supportsFeature(features map[string]string, feature, version string) bool {
+	min, found := features[feature]
+	return found && versionAtLeast(version, min)
+}
+
+// 用户注册的 Go 类型与 SQL 类型的对应关系，以 Dialect.Name() 作为键名。
+var (
+	types    = map[string]map[reflect.Type]string{}
+	typesMux sync.RWMutex
+)
+
+// RegisterType 为名称为 dialectName 的 Dialect 注册 Go 类型 t 对应的 SQL 类型
+//
+// 注册之后，所有类型为 t 的列都将直接使用 sqlType 作为其类型，
+// 而忽略 len、type 等属性对类型的影响。
+// dialectName 对应 Dialect.Name() 的返回值，同一类型重复注册会覆盖之前的值。
+func RegisterType(dialectName string, t reflect.Type, sqlType string) error {
+	if dialectName == "" {
+		return errors.New("参数 dialectName 不能为空")
+	}
+
+	if t == nil {
+		return errors.New("参数 t 不能为空")
+	}
+
+	if sqlType == "" {
+		return errors.New("参数 sqlType 不能为空")
+	}
+
+	typesMux.Lock()
+	defer typesMux.Unlock()
+
+	ts, found := types[dialectName]
+	if !found {
+		ts = map[reflect.Type]string{}
+		types[dialectName] = ts
+	}
+	ts[t] = sqlType
+
+	return nil
+}
+
+// 查找 col 中为当前数据库单独指定的类型或是通过 RegisterType 注册的类型，
+// 若存在则写入 buf，并返回 true。
+func registeredType(dialectName string, buf *sqlbuilder.SQLBuilder, col *model.Column) bool {
+	// 列中单独指定的类型优先于注册的类型
+	if typ, found := col.DialectTypes[dialectName]; found {
+		buf.WriteString(typ)
+		return true
+	}
+
+	typesMux.RLock()
+	typ, found := types[dialectName][col.GoType]
+	typesMux.RUnlock()
+
+	if found {
+		buf.WriteString(typ)
+	}
+	return found
+}
+
 type base interface {
 	orm.Dialect
 
@@ -32,10 +142,36 @@ type base interface {
 	sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error
 }
 
+// 将 sqlType 生成的类型以字符串的形式返回
+//
+// sqlType 还会输出字符集、排序规则等列的属性，这些属性并不属于类型，
+// 所以以去掉这些属性之后的副本生成类型。
+func typeForColumn(b base, col *model.Column) (string, error) {
+	if col != nil {
+		c := *col
+		c.Charset = ""
+		c.Collate = ""
+		col = &c
+	}
+
+	buf := sqlbuilder.New("")
+	if err := b.sqlType(buf, col); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// 自增列 col 是否指定了非默认的起始值或是步长，col 可以为 nil。
+func hasAIOptions(col *model.Column) bool {
+	return col != nil && col.IsAI() && !col.AutoRandom && (col.AIStart > 1 || col.AIStep > 1)
+}
+
 // 用于产生在 createTable 中使用的普通列信息表达式，不包含 autoincrement 和 primary key 的关键字。
 func createColSQL(b base, buf *sqlbuilder.SQLBuilder, col *model.Column) error {
 	// col_name VARCHAR(100) NOT NULL DEFAULT 'abc'
-	buf.WriteByte('{').WriteString(col.Name).WriteByte('}')
+	// col_name VARCHAR(100) NULL DEFAULT 'abc'
+	buf.Quote(col.Name)
 	buf.WriteByte(' ')
 
 	// 写入字段类型
@@ -43,28 +179,123 @@ func createColSQL(b base, buf *sqlbuilder.SQLBuilder, col *model.Column) error {
 		return err
 	}
 
-	if !col.Nullable {
+	if col.Generated != "" {
+		buf.WriteString(" GENERATED ALWAYS AS (").WriteString(col.Generated).WriteByte(')')
+		if col.GeneratedStored {
+			buf.WriteString(" STORED")
+		} else {
+			buf.WriteString(" VIRTUAL")
+		}
+	}
+
+	// 可以为 NULL 的列也显式地输出 NULL，
+	// 比如 mysql 中的 TIMESTAMP 在未指定的情况下可能会被当作 NOT NULL 处理。
+	// DEFAULT 必须在 NULL 或是 NOT NULL 之后。
+	if col.Nullable {
+		buf.WriteString(" NULL")
+	} else {
 		buf.WriteString(" NOT NULL")
 	}
 
 	if col.HasDefault {
-		buf.WriteString(" DEFAULT '").
-			WriteString(col.Default).
-			WriteByte('\'')
+		kw, isKeyword, err := timeDefault(b.Name(), col)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteString(" DEFAULT ")
+		if isKeyword {
+			buf.WriteString(kw)
+		} else {
+			buf.WriteString(b.QuoteString(col.Default))
+		}
 	}
 
 	return nil
 }
 
+// 各数据库中可以直接作为时间类型默认值的关键字，以 Dialect.Name() 作为键名。
+//
+// 这些关键字会原样输出，而不是被当作字符串处理。
+var timeDefaults = map[string][]string{
+	"mysql":    {"CURRENT_TIMESTAMP", "NOW()"},
+	"postgres": {"CURRENT_TIMESTAMP", "CURRENT_DATE", "LOCALTIMESTAMP", "NOW()"},
+	"sqlite3":  {"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME"},
+}
+
+// 时间类型的列 col 的默认值是否为 dialect 可识别的关键字
+//
+// 若默认值是其它数据库中的关键字，则返回错误，而不是将其当作普通的字符串输出。
+func timeDefault(dialect string, col *model.Column) (string, bool, error) {
+	if col.GoType != timeType {
+		return "", false, nil
+	}
+
+	kw := strings.ToUpper(strings.TrimSpace(col.Default))
+	for _, v := range timeDefaults[dialect] {
+		if v == kw {
+			return kw, true, nil
+		}
+	}
+
+	for _, kws := range timeDefaults {
+		for _, v := range kws {
+			if v == kw {
+				return "", false, fmt.Errorf("%s 不支持以 %s 作为默认值", dialect, col.Default)
+			}
+		}
+	}
+
+	return "", false, nil
+}
+
+// 检测 col.Type 是否为可用的类型，col.Type 为空表示未指定类型，直接返回 nil。
+//
+// 时间类型的列从 timeTypes 中查找，其它的从 stringTypes 中查找。
+func checkColumnType(col *model.Column, stringTypes, timeTypes []string) error {
+	if col.Type == "" {
+		return nil
+	}
+
+	types := stringTypes
+	if col.GoType == timeType {
+		types = timeTypes
+	}
+
+	for _, typ := range types {
+		if typ == col.Type {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("sqlType:不支持的类型:[%s]", col.Type)
+}
+
+// 写入时间类型的精度，即小数秒的位数，由 col.Len1 指定，0 表示不需要。
+func timePrecision(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
+	switch {
+	case col.Len1 == 0:
+		return nil
+	case col.Len1 < 0 || col.Len1 > 6:
+		return errors.New("sqlType:时间精度只能是 0-6")
+	}
+
+	buf.WriteByte('(').WriteInt(col.Len1).WriteByte(')')
+	return nil
+}
+
+// 为 CreateTableSQL 中与列相关的错误信息加上表名和列名
+func columnError(m *model.Model, col *model.Column, err error) error {
+	return fmt.Errorf("CreateTableSQL: table=%s col=%s: %v", m.Name, col.Name, err)
+}
+
 // create table 语句中 pk 约束的语句
 func createPKSQL(buf *sqlbuilder.SQLBuilder, cols []*model.Column, pkName string) {
 	// CONSTRAINT pk_name PRIMARY KEY (id,lastName)
-	buf.WriteString(" CONSTRAINT ").
-		WriteString(pkName).
-		WriteString(" PRIMARY KEY(")
+	buf.WriteStrings(" CONSTRAINT ", pkName, " PRIMARY KEY(")
 
 	for _, col := range cols {
-		buf.WriteByte('{').WriteString(col.Name).WriteByte('}')
+		buf.Quote(col.Name)
 		buf.WriteByte(',')
 	}
 	buf.TruncateLast(1) // 去掉最后一个逗号
@@ -74,11 +305,9 @@ func createPKSQL(buf *sqlbuilder.SQLBuilder, cols []*model.Column, pkName string
 // create table 语句中的 unique 约束部分的语句。
 func createUniqueSQL(buf *sqlbuilder.SQLBuilder, cols []*model.Column, indexName string) {
 	// CONSTRAINT unique_name UNIQUE (id,lastName)
-	buf.WriteString(" CONSTRAINT ").
-		WriteString(indexName).
-		WriteString(" UNIQUE(")
+	buf.WriteStrings(" CONSTRAINT ", indexName, " UNIQUE(")
 	for _, col := range cols {
-		buf.WriteByte('{').WriteString(col.Name).WriteByte('}')
+		buf.Quote(col.Name)
 		buf.WriteByte(',')
 	}
 	buf.TruncateLast(1) // 去掉最后一个逗号
@@ -88,16 +317,20 @@ func createUniqueSQL(buf *sqlbuilder.SQLBuilder, cols []*model.Column, indexName
 
 // create table 语句中 fk 的约束部分的语句
 func createFKSQL(buf *sqlbuilder.SQLBuilder, fk *model.ForeignKey, fkName string) {
-	// CONSTRAINT fk_name FOREIGN KEY (id) REFERENCES user(id)
+	// CONSTRAINT fk_name FOREIGN KEY (id,name) REFERENCES user(id,name)
 	buf.WriteString(" CONSTRAINT ").WriteString(fkName)
 
 	buf.WriteString(" FOREIGN KEY(")
-	buf.WriteByte('{').WriteString(fk.Col.Name).WriteByte('}')
-
-	buf.WriteString(") REFERENCES ").WriteString(fk.RefTableName)
+	for _, col := range fk.Cols {
+		buf.Quote(col.Name).WriteByte(',')
+	}
+	buf.TruncateLast(1)
 
-	buf.WriteByte('(')
-	buf.WriteByte('{').WriteString(fk.RefColName).WriteByte('}')
+	buf.WriteString(") REFERENCES ").Quote(fk.RefTableName).WriteByte('(')
+	for _, col := range fk.RefColNames {
+		buf.Quote(col).WriteByte(',')
+	}
+	buf.TruncateLast(1)
 	buf.WriteByte(')')
 
 	if len(fk.UpdateRule) > 0 {
@@ -112,17 +345,16 @@ func createFKSQL(buf *sqlbuilder.SQLBuilder, fk *model.ForeignKey, fkName string
 // create table 语句中 check 约束部分的语句
 func createCheckSQL(buf *sqlbuilder.SQLBuilder, expr, chkName string) {
 	// CONSTRAINT chk_name CHECK (id>0 AND username='admin')
-	buf.WriteString(" CONSTRAINT ").
-		WriteString(chkName).
-		WriteString(" CHECK(").
-		WriteString(expr).
-		WriteByte(')')
+	buf.WriteStrings(" CONSTRAINT ", chkName, " CHECK(", expr, ")")
 }
 
 // 创建标准的几种约束(除 PK 约束，该约束有专门的函数 createPKSQL() 产生)：unique, foreign key, check
 func createConstraints(buf *sqlbuilder.SQLBuilder, model *model.Model) {
-	// Unique Index
+	// Unique Index，带条件的由 createIndexSQL 以 CREATE UNIQUE INDEX 的形式创建
 	for name, index := range model.UniqueIndexes {
+		if _, found := model.UniqueConds[name]; found {
+			continue
+		}
 		createUniqueSQL(buf, index, name)
 		buf.WriteByte(',')
 	}
@@ -133,85 +365,596 @@ func createConstraints(buf *sqlbuilder.SQLBuilder, model *model.Model) {
 		buf.WriteByte(',')
 	}
 
-	// Check
-	for name, chk := range model.Check {
-		createCheckSQL(buf, chk, name)
+	// Check，按名称排序，保证生成的语句顺序固定。
+	names := make([]string, 0, len(model.Check))
+	for name := range model.Check {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		createCheckSQL(buf, model.Check[name], name)
 		buf.WriteByte(',')
 	}
 }
 
-func createIndexSQL(model *model.Model) ([]string, error) {
-	if len(model.KeyIndexes) == 0 {
+// 生成标准的创建视图的语句
+//  CREATE OR REPLACE VIEW {#name} AS SELECT ...
+func standardCreateViewSQL(name, selectSQL string, orReplace bool) string {
+	buf := sqlbuilder.New("CREATE ")
+	if orReplace {
+		buf.WriteString("OR REPLACE ")
+	}
+
+	return buf.WriteString("VIEW ").
+		Quote("#" + name).
+		WriteString(" AS ").
+		WriteString(selectSQL).
+		String()
+}
+
+// 生成标准的添加约束的语句
+//  ALTER TABLE {#table} ADD CONSTRAINT chk_name CHECK(id>0)
+func standardAddConstraintSQL(table, name string, con *model.Constraint) (string, error) {
+	if con == nil {
+		return "", errors.New("AddConstraintSQL: con 不能为空")
+	}
+
+	buf := sqlbuilder.New("ALTER TABLE ").Quote(table).WriteString(" ADD")
+	switch con.Type {
+	case model.ConstraintPK:
+		if len(con.Cols) == 0 {
+			return "", errors.New("AddConstraintSQL: 未指定主键列")
+		}
+		createPKSQL(buf, con.Cols, name)
+	case model.ConstraintUnique:
+		if len(con.Cols) == 0 {
+			return "", errors.New("AddConstraintSQL: 未指定唯一约束的列")
+		}
+		createUniqueSQL(buf, con.Cols, name)
+	case model.ConstraintFK:
+		if con.FK == nil || len(con.FK.Cols) == 0 {
+			return "", errors.New("AddConstraintSQL: 未指定外键")
+		}
+		createFKSQL(buf, con.FK, name)
+	case model.ConstraintCheck:
+		if con.Check == "" {
+			return "", errors.New("AddConstraintSQL: 未指定 check 表达式")
+		}
+		createCheckSQL(buf, con.Check, name)
+	default:
+		return "", fmt.Errorf("AddConstraintSQL: 不支持的约束类型 %s", con.Type)
+	}
+
+	return buf.String(), nil
+}
+
+// 生成标准的保存点语句，mysql、postgres 和 sqlite3 的语法都是相同的：
+//  SAVEPOINT name
+//  RELEASE SAVEPOINT name
+//  ROLLBACK TO SAVEPOINT name
+func standardSavepointSQL(name string) string {
+	return "SAVEPOINT " + name
+}
+
+func standardReleaseSavepointSQL(name string) string {
+	return "RELEASE SAVEPOINT " + name
+}
+
+func standardRollbackToSavepointSQL(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + name
+}
+
+// 生成标准的 CREATE INDEX 语句
+//  CREATE UNIQUE INDEX index_name ON table(id,lastName)
+func standardCreateIndexSQL(tableName, indexName string, cols []*model.Column, unique bool) string {
+	return collateCreateIndexSQL(tableName, indexName, cols, unique, nil)
+}
+
+// 与 standardCreateIndexSQL 相同，但是可以通过 collates 为列单独指定排序规则：
+//  CREATE INDEX index_name ON {#tbl}({name} COLLATE {utf8mb4_general_ci})
+//
+// collates 的键名为索引中的列。
+func collateCreateIndexSQL(tableName, indexName string, cols []*model.Column, unique bool, collates map[*model.Column]string) string {
+	buf := sqlbuilder.New("CREATE ")
+	if unique {
+		buf.WriteString("UNIQUE ")
+	}
+	buf.WriteStrings("INDEX ", indexName, " ON ", tableName, "(")
+	for _, col := range cols {
+		writeIndexColumn(buf, col, collates)
+		buf.WriteByte(',')
+	}
+	buf.TruncateLast(1) // 去掉最后一个逗号
+	buf.WriteByte(')')
+
+	return buf.String()
+}
+
+// 写入索引中的列，若在 collates 中指定了排序规则，则一并写入。
+// 排序规则与标识符的引用方式相同，以占位符的形式写入。
+func writeIndexColumn(buf *sqlbuilder.SQLBuilder, col *model.Column, collates map[*model.Column]string) {
+	buf.Quote(col.Name)
+	if collate, found := collates[col]; found {
+		buf.WriteString(" COLLATE ").Quote(collate)
+	}
+}
+
+// 生成 CREATE TABLE 语句的开始部分，包括表名和左括号。
+func createTableSQL(m *model.Model, ifNotExists bool) *sqlbuilder.SQLBuilder {
+	w := sqlbuilder.New("CREATE TABLE ")
+	if ifNotExists {
+		w.WriteString("IF NOT EXISTS ")
+	}
+	return w.WriteString(m.FullName()).WriteByte('(')
+}
+
+// 将 schema.#table 形式的表名拆分成 schema 和表名两部分，未指定 schema 时，schema 为空。
+func splitTableName(table string) (schema, name string) {
+	if i := strings.IndexByte(table, '.'); i > 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// 将未经引号包含的表名 table 以 {schema}.{#table} 的形式写入 buf
+func quoteTableName(buf *sqlbuilder.SQLBuilder, table string) *sqlbuilder.SQLBuilder {
+	schema, name := splitTableName(table)
+	if schema != "" {
+		buf.Quote(schema).WriteByte('.')
+	}
+	return buf.Quote(name)
+}
+
+// 从 {schema}.{#table} 形式的表名中分离出 schema 部分，未指定 schema 时返回空值。
+//
+// 用于索引名只在 schema 内唯一的数据库，删除或创建索引时需要以 {schema}.index 的形式指定索引。
+func indexSchema(tableName string) string {
+	if i := strings.LastIndexByte(tableName, '.'); i > 0 {
+		return tableName[:i]
+	}
+	return ""
+}
+
+// cols 中是否包含 col
+func containsColumn(cols []*model.Column, col *model.Column) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// 生成标准的 INSERT 语句
+//  INSERT INTO {#table}({id},{name}) VALUES(?,?)
+//
+// 没有可插入的列时，生成 INSERT INTO {#table} DEFAULT VALUES。
+func standardInsertSQL(m *model.Model, includeAI bool) (string, []string) {
+	query, names := insertSQL(m, 1, includeAI)
+	if len(names) == 0 {
+		return "INSERT INTO " + m.FullName() + " DEFAULT VALUES", names
+	}
+	return query, names
+}
+
+// 生成标准的插入多行数据的 INSERT 语句
+//  INSERT INTO {#table}({id},{name}) VALUES(?,?),(?,?)
+//
+// DEFAULT VALUES 只能插入一行数据，所以没有可插入的列时，rowCount 只能为 1。
+func standardMultiInsertSQL(m *model.Model, rowCount int, includeAI bool) (string, []string, error) {
+	if rowCount <= 0 {
+		return "", nil, errors.New("MultiInsertSQL: rowCount 必须大于 0")
+	}
+
+	if rowCount == 1 {
+		query, names := standardInsertSQL(m, includeAI)
+		return query, names, nil
+	}
+
+	query, names := insertSQL(m, rowCount, includeAI)
+	if len(names) == 0 {
+		return "", nil, errors.New("MultiInsertSQL: 没有可插入的列时只能插入一行数据")
+	}
+	return query, names, nil
+}
+
+// 生成插入 rowCount 行数据的语句，没有可插入的列时，
+// 生成 INSERT INTO {#table}() VALUES(),() 形式的语句，仅 mysql 支持该语法。
+func insertSQL(m *model.Model, rowCount int, includeAI bool) (string, []string) {
+	names := make([]string, 0, len(m.Cols))
+	buf := sqlbuilder.New("INSERT INTO ").
+		WriteString(m.FullName()).
+		WriteByte('(')
+	for _, col := range m.Columns() {
+		if (!includeAI && col.IsAI()) || col.Readonly {
+			continue
+		}
+
+		buf.Quote(col.Name).WriteByte(',')
+		names = append(names, col.GoName)
+	}
+	if len(names) > 0 {
+		buf.TruncateLast(1)
+	}
+
+	buf.WriteString(") VALUES")
+	for i := 0; i < rowCount; i++ {
+		buf.WriteByte('(')
+		for range names {
+			buf.WriteString("?,")
+		}
+		if len(names) > 0 {
+			buf.TruncateLast(1)
+		}
+		buf.WriteString("),")
+	}
+	buf.TruncateLast(1)
+
+	return buf.String(), names
+}
+
+// 生成 INSERT ... ON CONFLICT 形式的语句
+//  INSERT INTO {#table}({id},{name}) VALUES(?,?) ON CONFLICT({id}) DO UPDATE SET {name}=EXCLUDED.{name}
+func standardUpsertSQL(m *model.Model, conflictCols, updateCols []string) (string, []string, error) {
+	conflict, update, err := upsertColumns(m, conflictCols, updateCols)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, names := insertSQL(m, 1, hasAI(conflict))
+	buf := sqlbuilder.New(query).WriteString(" ON CONFLICT(")
+	for _, col := range conflict {
+		buf.Quote(col.Name).WriteByte(',')
+	}
+	buf.TruncateLast(1).WriteString(") DO UPDATE SET ")
+
+	for _, col := range update {
+		buf.Quote(col.Name).WriteString("=EXCLUDED.").Quote(col.Name).WriteByte(',')
+	}
+	if occ, found := m.OCCColumn(); found {
+		buf.Quote(occ.Name).WriteByte('=').Quote("#" + m.Name).WriteByte('.').Quote(occ.Name).WriteString("+1,")
+	}
+	buf.TruncateLast(1)
+
+	return buf.String(), names, nil
+}
+
+// 获取 UpsertSQL 中冲突列和需要更新的列
+func upsertColumns(m *model.Model, conflictCols, updateCols []string) (conflict, update []*model.Column, err error) {
+	if len(conflictCols) == 0 {
+		if len(m.PK) == 0 {
+			return nil, nil, fmt.Errorf("UpsertSQL: %s 不存在主键", m.Name)
+		}
+		conflict = m.PK
+	} else {
+		conflict = make([]*model.Column, 0, len(conflictCols))
+		for _, name := range conflictCols {
+			col, found := m.Cols[name]
+			if !found {
+				return nil, nil, fmt.Errorf("UpsertSQL: %s 中不存在列 %s", m.Name, name)
+			}
+			conflict = append(conflict, col)
+		}
+	}
+
+	isConflict := func(col *model.Column) bool {
+		for _, c := range conflict {
+			if c == col {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(updateCols) == 0 {
+		for _, col := range m.Columns() {
+			if !isConflict(col) && !col.IsAI() && col != m.OCC && !col.Readonly {
+				update = append(update, col)
+			}
+		}
+	} else {
+		update = make([]*model.Column, 0, len(updateCols))
+		for _, name := range updateCols {
+			col, found := m.Cols[name]
+			if !found {
+				return nil, nil, fmt.Errorf("UpsertSQL: %s 中不存在列 %s", m.Name, name)
+			}
+			if isConflict(col) || col.IsAI() || col == m.OCC || col.Readonly {
+				return nil, nil, fmt.Errorf("UpsertSQL: 不能更新冲突列、自增列、乐观锁列或只读列 %s", name)
+			}
+			update = append(update, col)
+		}
+	}
+
+	if len(update) == 0 && !m.HasOCC() {
+		return nil, nil, sqlbuilder.ErrValueIsEmpty
+	}
+
+	return conflict, update, nil
+}
+
+// cols 中是否包含自增列
+func hasAI(cols []*model.Column) bool {
+	for _, col := range cols {
+		if col.IsAI() {
+			return true
+		}
+	}
+	return false
+}
+
+// 生成标准的 UPDATE 语句
+//  UPDATE {#table} SET {name}=?,{occ}={occ}+1 WHERE {id}=? AND {occ}=?
+func standardUpdateSQL(m *model.Model, cols []string) (string, []string, error) {
+	if len(m.PK) == 0 {
+		return "", nil, fmt.Errorf("UpdateSQL: %s 不存在主键", m.Name)
+	}
+
+	isPK := func(col *model.Column) bool {
+		for _, pk := range m.PK {
+			if pk == col {
+				return true
+			}
+		}
+		return false
+	}
+
+	var updateCols []*model.Column
+	if len(cols) == 0 {
+		for _, col := range m.Columns() {
+			if !isPK(col) && col != m.OCC && !col.Readonly {
+				updateCols = append(updateCols, col)
+			}
+		}
+	} else {
+		updateCols = make([]*model.Column, 0, len(cols))
+		for _, name := range cols {
+			col, found := m.Cols[name]
+			if !found {
+				return "", nil, fmt.Errorf("UpdateSQL: %s 中不存在列 %s", m.Name, name)
+			}
+			if isPK(col) || col == m.OCC {
+				return "", nil, fmt.Errorf("UpdateSQL: 不能更新主键或乐观锁列 %s", name)
+			}
+			if col.Readonly {
+				return "", nil, fmt.Errorf("UpdateSQL: 不能更新只读列 %s", name)
+			}
+			updateCols = append(updateCols, col)
+		}
+	}
+
+	if len(updateCols) == 0 && !m.HasOCC() {
+		return "", nil, sqlbuilder.ErrValueIsEmpty
+	}
+
+	names := make([]string, 0, len(updateCols)+len(m.PK)+1)
+	buf := sqlbuilder.New("UPDATE ").
+		WriteString(m.FullName()).
+		WriteString(" SET ")
+	for _, col := range updateCols {
+		buf.Quote(col.Name).WriteString("=?,")
+		names = append(names, col.GoName)
+	}
+	occ, hasOCC := m.OCCColumn()
+	if hasOCC {
+		buf.Quote(occ.Name).WriteByte('=').Quote(occ.Name).WriteString("+1,")
+	}
+	buf.TruncateLast(1)
+
+	buf.WriteString(" WHERE ")
+	for _, col := range m.PK {
+		buf.Quote(col.Name).WriteString("=? AND ")
+		names = append(names, col.GoName)
+	}
+	if hasOCC {
+		buf.Quote(occ.Name).WriteString("=? AND ")
+		names = append(names, occ.GoName)
+	}
+	buf.TruncateLast(5) // 去掉最后的 " AND "
+
+	return buf.String(), names, nil
+}
+
+// 生成标准的 DELETE 语句
+//  DELETE FROM {#table} WHERE {id}=?
+//
+// 软删除时：
+//  UPDATE {#table} SET {deleted_at}=? WHERE {id}=?
+func standardDeleteSQL(m *model.Model) (string, []string, error) {
+	if len(m.PK) == 0 {
+		return "", nil, fmt.Errorf("DeleteSQL: %s 不存在主键", m.Name)
+	}
+
+	names := make([]string, 0, len(m.PK)+1)
+	buf := sqlbuilder.New("")
+	if m.IsSoftDelete() {
+		buf.WriteString("UPDATE ").
+			WriteString(m.FullName()).
+			WriteString(" SET ").
+			Quote(m.SoftDelete.Name).
+			WriteString("=?")
+		names = append(names, m.SoftDelete.GoName)
+	} else {
+		buf.WriteString("DELETE FROM ").WriteString(m.FullName())
+	}
+
+	buf.WriteString(" WHERE ")
+	for _, col := range m.PK {
+		buf.Quote(col.Name).WriteString("=? AND ")
+		names = append(names, col.GoName)
+	}
+	buf.TruncateLast(5) // 去掉最后的 " AND "
+
+	return buf.String(), names, nil
+}
+
+func createIndexSQL(b base, model *model.Model) ([]string, error) {
+	if len(model.KeyIndexes) == 0 && len(model.UniqueConds) == 0 {
 		return nil, nil
 	}
 
-	sqls := make([]string, 0, len(model.KeyIndexes))
-	buf := sqlbuilder.CreateIndex(nil)
+	sqls := make([]string, 0, len(model.KeyIndexes)+len(model.UniqueConds))
 	for name, cols := range model.KeyIndexes {
-		buf.Reset()
-		buf.Table("{#" + model.Name + "}").Name(name)
-		for _, col := range cols {
-			buf.Columns("{" + col.Name + "}")
+		if len(cols) == 0 {
+			return nil, sqlbuilder.ErrColumnsIsEmpty
 		}
 
-		sql, _, err := buf.SQL()
-		if err != nil {
-			return nil, err
+		if collates := model.IndexCollates[name]; len(collates) > 0 {
+			if vd, ok := b.(orm.VersionedDialect); ok && !vd.SupportsFeature(orm.FeatureIndexCollate, vd.Version()) {
+				return nil, fmt.Errorf("CreateTableSQL: %s 不支持为索引 %s 指定排序规则", b.Name(), name)
+			}
+			sqls = append(sqls, collateCreateIndexSQL(model.FullName(), name, cols, false, collates))
+			continue
+		}
+
+		sqls = append(sqls, b.CreateIndexSQL(model.FullName(), name, cols, false))
+	}
+
+	// 带条件的唯一索引
+	for name, cond := range model.UniqueConds {
+		cols := model.UniqueIndexes[name]
+		if len(cols) == 0 {
+			return nil, sqlbuilder.ErrColumnsIsEmpty
 		}
-		sqls = append(sqls, sql)
+
+		query := b.CreateIndexSQL(model.FullName(), name, cols, true)
+		sqls = append(sqls, query+" WHERE "+cond)
 	}
 
 	return sqls, nil
 }
 
+// 将 s 转换成以单引号包含的字符串字面量，其中的单引号会被转义成两个单引号。
+// backslash 表示是否需要将反斜杠也作为转义字符处理。
+func quoteString(s string, backslash bool) string {
+	if backslash {
+		s = strings.Replace(s, "\\", "\\\\", -1)
+	}
+
+	return sqlbuilder.New("").WriteQuotedString(s).String()
+}
+
+// 时间字面量的格式，没有小数部分时会省略小数点。
+const datetimeLayout = "2006-01-02 15:04:05.999999"
+
+// 将 v 转换成 SQL 字面量。
+//
+// backslash 的作用与 quoteString 中的相同；t 和 f 分别为布尔值 true 和 false 的字面量。
+// 时间字面量不包含时区信息，所以统一转换成 UTC 时间之后再输出。
+func formatValue(v interface{}, backslash bool, t, f string) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		v = val
+	}
+
+	switch val := v.(type) {
+	case nil:
+		return "NULL", nil
+	case time.Time:
+		return "'" + val.UTC().Format(datetimeLayout) + "'", nil
+	}
+
+	rval := reflect.ValueOf(v)
+	switch rval.Kind() {
+	case reflect.String:
+		return quoteString(rval.String(), backslash), nil
+	case reflect.Bool:
+		if rval.Bool() {
+			return t, nil
+		}
+		return f, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rval.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rval.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		fv := rval.Float()
+		if math.IsNaN(fv) || math.IsInf(fv, 0) {
+			return "", fmt.Errorf("FormatValue: 无法表示的浮点数 %v", fv)
+		}
+		return strconv.FormatFloat(fv, 'g', -1, rval.Type().Bits()), nil
+	}
+
+	return "", fmt.Errorf("FormatValue: 不支持的类型 %T", v)
+}
+
+// 以 l 和 r 包含标识符 name，name 中的点号表示 schema.table 等层级，
+// 每一段都会被单独包含，其中的 r 字符会被转义成两个 r。
+func quoteIdentifier(name string, l, r byte) string {
+	buf := sqlbuilder.New("")
+	buf.WriteByte(l)
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; c {
+		case '.':
+			buf.WriteByte(r).WriteByte('.').WriteByte(l)
+		case r:
+			buf.WriteByte(r).WriteByte(r)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(r)
+
+	return buf.String()
+}
+
+// 分页语句中 limit 或 offset 对应的占位符
+func limitPlaceholder(v interface{}) string {
+	if named, ok := v.(sql.NamedArg); ok && named.Name != "" {
+		return "@" + named.Name
+	}
+	return "?"
+}
+
 // mysql 系列数据库分页语法的实现。支持以下数据库：
 // MySQL, H2, HSQLDB, Postgres, SQLite3
-func mysqlLimitSQL(limit interface{}, offset ...interface{}) (string, []interface{}) {
-	query := " LIMIT "
+//
+// limit 为 nil 表示仅指定了 offset，此时以 all 作为 LIMIT 的值；
+// all 为空则表示数据库支持单独的 OFFSET 语句。
+// limit 和 offset 都未指定时，返回空值。
+func mysqlLimitSQL(all string, limit interface{}, offset ...interface{}) (string, []interface{}) {
+	if limit == nil {
+		if len(offset) == 0 {
+			return "", nil
+		}
 
-	if named, ok := limit.(sql.NamedArg); ok && named.Name != "" {
-		query += "@" + named.Name
-	} else {
-		query += "?"
+		query := " OFFSET " + limitPlaceholder(offset[0]) + " "
+		if all != "" {
+			query = " LIMIT " + all + query
+		}
+		return query, []interface{}{offset[0]}
 	}
 
+	query := " LIMIT " + limitPlaceholder(limit)
+
 	if len(offset) == 0 {
 		return query + " ", []interface{}{limit}
 	}
 
-	query += " OFFSET "
-	o := offset[0]
-	if named, ok := o.(sql.NamedArg); ok && named.Name != "" {
-		query += "@" + named.Name
-	} else {
-		query += "?"
-	}
-
+	query += " OFFSET " + limitPlaceholder(offset[0])
 	return query + " ", []interface{}{limit, offset[0]}
 }
 
 // oracle系列数据库分页语法的实现。支持以下数据库：
 // Derby, SQL Server 2012, Oracle 12c, the SQL 2008 standard
+//
+// limit 为 nil 表示仅指定了 offset，会省略 FETCH NEXT 部分。
 func oracleLimitSQL(limit interface{}, offset ...interface{}) (string, []interface{}) {
-	query := "FETCH NEXT "
-
-	if named, ok := limit.(sql.NamedArg); ok && named.Name != "" {
-		query += "@" + named.Name
-	} else {
-		query += "?"
-	}
-	query += " ROWS ONLY "
+	var query string
+	var args []interface{}
 
-	if len(offset) == 0 {
-		return query, []interface{}{limit}
+	if len(offset) > 0 {
+		query = "OFFSET " + limitPlaceholder(offset[0]) + " ROWS "
+		args = append(args, offset[0])
 	}
 
-	o := offset[0]
-	if named, ok := o.(sql.NamedArg); ok && named.Name != "" {
-		query = "OFFSET @" + named.Name + " ROWS " + query
-	} else {
-		query = "OFFSET ? ROWS " + query
+	if limit != nil {
+		query += "FETCH NEXT " + limitPlaceholder(limit) + " ROWS ONLY "
+		args = append(args, limit)
 	}
 
-	return query, []interface{}{offset[0], limit}
+	return query, args
 }
@@ -6,10 +6,16 @@ package dialect
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
+	"github.com/issue9/orm"
 	"github.com/issue9/orm/internal/sqltest"
 	"github.com/issue9/orm/model"
 	"github.com/issue9/orm/sqlbuilder"
@@ -41,10 +47,202 @@ func TestCreatColSQL(t *testing.T) {
 	col.HasDefault = false
 	col.Nullable = true
 	createColSQL(dialect, buf, col)
-	wont = "{id} SMALLINT"
+	wont = "{id} SMALLINT NULL"
 	sqltest.Equal(a, buf.String(), wont)
 }
 
+// nullable 和 default 的四种组合
+func TestCreatColSQL_nullableDefault(t *testing.T) {
+	a := assert.New(t)
+
+	data := []*struct {
+		nullable, hasDefault bool
+		wont                 string
+	}{
+		{nullable: false, hasDefault: false, wont: "{name} %s NOT NULL"},
+		{nullable: false, hasDefault: true, wont: "{name} %s NOT NULL DEFAULT 'abc'"},
+		{nullable: true, hasDefault: false, wont: "{name} %s NULL"},
+		{nullable: true, hasDefault: true, wont: "{name} %s NULL DEFAULT 'abc'"},
+	}
+
+	dialects := map[string]base{
+		"VARCHAR(20)": &mysql{},
+		"TEXT":        &sqlite3{},
+	}
+	for typ, d := range dialects {
+		for _, item := range data {
+			buf := sqlbuilder.New("")
+			col := &model.Column{
+				Name:       "name",
+				GoType:     reflect.TypeOf(""),
+				Len1:       20,
+				Nullable:   item.nullable,
+				HasDefault: item.hasDefault,
+				Default:    "abc",
+			}
+
+			a.NotError(createColSQL(d, buf, col))
+			sqltest.Equal(a, buf.String(), fmt.Sprintf(item.wont, typ))
+		}
+	}
+
+	// postgres 的 unsigned 以 CHECK 代替，依然在 NULL 和 DEFAULT 之前
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		Name:       "age",
+		GoType:     reflect.TypeOf(int64(1)),
+		Unsigned:   true,
+		Nullable:   true,
+		HasDefault: true,
+		Default:    "1",
+	}
+	a.NotError(createColSQL(&postgres{}, buf, col))
+	sqltest.Equal(a, buf.String(), "{age} BIGINT CHECK({age}>=0) NULL DEFAULT '1'")
+}
+
+// 时间类型的默认值，各数据库可用的关键字原样输出，其它数据库的关键字返回错误
+func TestCreatColSQL_timeDefault(t *testing.T) {
+	a := assert.New(t)
+
+	newCol := func(typ reflect.Type, def string) *model.Column {
+		return &model.Column{
+			Name:       "created",
+			GoType:     typ,
+			HasDefault: true,
+			Default:    def,
+		}
+	}
+	timeType := reflect.TypeOf(time.Time{})
+
+	data := []*struct {
+		d    base
+		typ  string
+		kws  []string // 可以使用的关键字
+		errs []string // 其它数据库的关键字，会返回错误
+	}{
+		{
+			d:    &mysql{},
+			typ:  "DATETIME",
+			kws:  []string{"CURRENT_TIMESTAMP", "now()"},
+			errs: []string{"CURRENT_DATE", "LOCALTIMESTAMP"},
+		},
+		{
+			d:    &postgres{},
+			typ:  "TIMESTAMP",
+			kws:  []string{"current_timestamp", "NOW()", "CURRENT_DATE", "LOCALTIMESTAMP"},
+			errs: []string{"CURRENT_TIME"},
+		},
+		{
+			d:    &sqlite3{},
+			typ:  "DATETIME",
+			kws:  []string{"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME"},
+			errs: []string{"NOW()"},
+		},
+	}
+
+	for _, item := range data {
+		for _, kw := range item.kws {
+			buf := sqlbuilder.New("")
+			a.NotError(createColSQL(item.d, buf, newCol(timeType, kw)))
+			a.Equal(buf.String(), "{created} "+item.typ+" NOT NULL DEFAULT "+strings.ToUpper(kw))
+		}
+
+		for _, kw := range item.errs {
+			buf := sqlbuilder.New("")
+			a.Error(createColSQL(item.d, buf, newCol(timeType, kw)))
+		}
+
+		// 非时间类型的列依然当作字符串处理
+		buf := sqlbuilder.New("")
+		a.NotError(createColSQL(item.d, buf, newCol(reflect.TypeOf(""), "CURRENT_TIMESTAMP")))
+		a.True(strings.HasSuffix(buf.String(), " DEFAULT 'CURRENT_TIMESTAMP'"), buf.String())
+
+		// 普通的时间值
+		buf = sqlbuilder.New("")
+		a.NotError(createColSQL(item.d, buf, newCol(timeType, "2018-01-02 03:04:05")))
+		a.True(strings.HasSuffix(buf.String(), " DEFAULT '2018-01-02 03:04:05'"), buf.String())
+	}
+
+	// 通过 struct tag 指定
+	type timeDefault struct {
+		Created time.Time `orm:"name(created);default(CURRENT_TIMESTAMP)"`
+	}
+	mod, err := model.New(&timeDefault{})
+	a.NotError(err).NotNil(mod)
+	sqls, err := (&sqlite3{}).CreateTableSQL(mod, true)
+	a.NotError(err)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#timeDefault}({created} DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)")
+}
+
+func TestCreatColSQL_default(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		Name:       "name",
+		GoType:     reflect.TypeOf(""),
+		Len1:       -1,
+		HasDefault: true,
+		Default:    `O'Brien\dir`,
+	}
+
+	a.NotError(createColSQL(Mysql().(base), buf, col))
+	sqltest.Equal(a, buf.String(), `{name} LONGTEXT NOT NULL DEFAULT 'O''Brien\\dir'`)
+
+	buf.Reset()
+	a.NotError(createColSQL(Postgres().(base), buf, col))
+	sqltest.Equal(a, buf.String(), `{name} TEXT NOT NULL DEFAULT 'O''Brien\dir'`)
+
+	buf.Reset()
+	a.NotError(createColSQL(Sqlite3().(base), buf, col))
+	sqltest.Equal(a, buf.String(), `{name} TEXT NOT NULL DEFAULT 'O''Brien\dir'`)
+}
+
+func TestCreatColSQL_generated(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		Name:            "total",
+		GoType:          reflect.TypeOf(int64(1)),
+		Generated:       "{price}*2",
+		GeneratedStored: true,
+	}
+
+	a.NotError(createColSQL(Mysql().(base), buf, col))
+	sqltest.Equal(a, buf.String(), "{total} BIGINT GENERATED ALWAYS AS ({price}*2) STORED NOT NULL")
+
+	buf.Reset()
+	a.NotError(createColSQL(Postgres().(base), buf, col))
+	sqltest.Equal(a, buf.String(), "{total} BIGINT GENERATED ALWAYS AS ({price}*2) STORED NOT NULL")
+
+	col.GeneratedStored = false
+	buf.Reset()
+	a.NotError(createColSQL(Mysql().(base), buf, col))
+	sqltest.Equal(a, buf.String(), "{total} BIGINT GENERATED ALWAYS AS ({price}*2) VIRTUAL NOT NULL")
+
+	// postgres 不支持 virtual
+	buf.Reset()
+	a.Error(createColSQL(Postgres().(base), buf, col))
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(m.QuoteIdentifier("user"), "`user`")
+	a.Equal(m.QuoteIdentifier("db.user"), "`db`.`user`")
+	a.Equal(m.QuoteIdentifier("us`er"), "`us``er`")
+
+	p := &postgres{}
+	a.Equal(p.QuoteIdentifier("user"), `"user"`)
+	a.Equal(p.QuoteIdentifier("public.user"), `"public"."user"`)
+	a.Equal(p.QuoteIdentifier(`us"er`), `"us""er"`)
+
+	s := &sqlite3{}
+	a.Equal(s.QuoteIdentifier("main.user"), "`main`.`user`")
+
+	// 左右引号不同时，只转义右引号
+	a.Equal(quoteIdentifier("a[b]c", '[', ']'), "[a[b]]c]")
+}
+
 func TestCreatePKSQL(t *testing.T) {
 	a := assert.New(t)
 	buf := sqlbuilder.New("")
@@ -83,14 +281,25 @@ func TestCreateFKSQL(t *testing.T) {
 	a := assert.New(t)
 	buf := sqlbuilder.New("")
 	fk := &model.ForeignKey{
-		Col:          &model.Column{Name: "id"},
-		RefTableName: "refTable",
-		RefColName:   "refCol",
+		Cols:         []*model.Column{{Name: "id"}},
+		RefTableName: "#refTable",
+		RefColNames:  []string{"refCol"},
 		UpdateRule:   "NO ACTION",
 	}
 
 	createFKSQL(buf, fk, "fkname")
-	wont := "CONSTRAINT fkname FOREIGN KEY({id}) REFERENCES refTable({refCol}) ON UPDATE NO ACTION"
+	wont := "CONSTRAINT fkname FOREIGN KEY({id}) REFERENCES {#refTable}({refCol}) ON UPDATE NO ACTION"
+	sqltest.Equal(a, buf.String(), wont)
+
+	// 复合外键
+	buf.Reset()
+	fk = &model.ForeignKey{
+		Cols:         []*model.Column{{Name: "first"}, {Name: "last"}},
+		RefTableName: "refTable",
+		RefColNames:  []string{"firstName", "lastName"},
+	}
+	createFKSQL(buf, fk, "fkname")
+	wont = "CONSTRAINT fkname FOREIGN KEY({first},{last}) REFERENCES {refTable}({firstName},{lastName})"
 	sqltest.Equal(a, buf.String(), wont)
 }
 
@@ -103,21 +312,149 @@ func TestCreateCheckSQL(t *testing.T) {
 	sqltest.Equal(a, buf.String(), wont)
 }
 
+func TestFormatValue(t *testing.T) {
+	a := assert.New(t)
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// 各数据库相同的部分
+	for _, d := range []base{&mysql{}, &postgres{}, &sqlite3{}} {
+		data := map[interface{}]string{
+			nil:                                     "NULL",
+			5:                                       "5",
+			int8(-5):                                "-5",
+			uint64(18):                              "18",
+			1.5:                                     "1.5",
+			"abc":                                   "'abc'",
+			"O'Brien":                               "'O''Brien'",
+			now:                                     "'2018-01-02 03:04:05'",
+			now.Add(time.Millisecond):               "'2018-01-02 03:04:05.001'",
+			now.In(time.FixedZone("UTC+8", 8*3600)): "'2018-01-02 03:04:05'",
+			sql.NullInt64{Int64: 5, Valid: true}:    "5",
+			sql.NullString{}:                        "NULL",
+		}
+		for v, wont := range data {
+			val, err := d.FormatValue(v)
+			a.NotError(err).Equal(val, wont)
+		}
+
+		// 不支持的类型
+		val, err := d.FormatValue([]int{1})
+		a.Error(err).Empty(val)
+		val, err = d.FormatValue(struct{}{})
+		a.Error(err).Empty(val)
+		val, err = d.FormatValue(math.NaN())
+		a.Error(err).Empty(val)
+	}
+
+	// 布尔值
+	val, err := (&mysql{}).FormatValue(true)
+	a.NotError(err).Equal(val, "1")
+	val, err = (&sqlite3{}).FormatValue(false)
+	a.NotError(err).Equal(val, "0")
+	val, err = (&postgres{}).FormatValue(true)
+	a.NotError(err).Equal(val, "TRUE")
+	val, err = (&postgres{}).FormatValue(false)
+	a.NotError(err).Equal(val, "FALSE")
+
+	// 反斜杠
+	val, err = (&mysql{}).FormatValue(`c:\dir`)
+	a.NotError(err).Equal(val, `'c:\\dir'`)
+	val, err = (&postgres{}).FormatValue(`c:\dir`)
+	a.NotError(err).Equal(val, `'c:\dir'`)
+}
+
 func TestMysqlLimitSQL(t *testing.T) {
 	a := assert.New(t)
 
-	query, ret := mysqlLimitSQL(5, 0)
+	query, ret := mysqlLimitSQL("", 5, 0)
 	a.Equal(ret, []int{5, 0})
 	sqltest.Equal(a, query, " LIMIT ? OFFSET ? ")
 
-	query, ret = mysqlLimitSQL(5)
+	query, ret = mysqlLimitSQL("", 5)
 	a.Equal(ret, []int{5})
 	sqltest.Equal(a, query, "LIMIT ?")
 
 	// 带 sql.namedArg
-	query, ret = mysqlLimitSQL(sql.Named("limit", 1), 2)
+	query, ret = mysqlLimitSQL("", sql.Named("limit", 1), 2)
 	a.Equal(ret, []interface{}{sql.Named("limit", 1), 2})
 	sqltest.Equal(a, query, "LIMIT @limit offset ?")
+
+	// 仅 offset
+	query, ret = mysqlLimitSQL("", nil, 2)
+	a.Equal(ret, []interface{}{2})
+	sqltest.Equal(a, query, "OFFSET ?")
+
+	query, ret = mysqlLimitSQL("-1", nil, sql.Named("offset", 2))
+	a.Equal(ret, []interface{}{sql.Named("offset", 2)})
+	sqltest.Equal(a, query, "LIMIT -1 OFFSET @offset")
+
+	// 都未指定
+	query, ret = mysqlLimitSQL("-1", nil)
+	a.Empty(ret).Empty(query)
+}
+
+func TestLimitSQL(t *testing.T) {
+	a := assert.New(t)
+
+	data := []*struct {
+		d          base
+		limitOnly  string
+		both       string
+		offsetOnly string
+	}{
+		{
+			d:          &mysql{},
+			limitOnly:  "LIMIT ?",
+			both:       "LIMIT ? OFFSET ?",
+			offsetOnly: "LIMIT 18446744073709551615 OFFSET ?",
+		},
+		{
+			d:          &postgres{},
+			limitOnly:  "LIMIT ?",
+			both:       "LIMIT ? OFFSET ?",
+			offsetOnly: "OFFSET ?",
+		},
+		{
+			d:          &sqlite3{},
+			limitOnly:  "LIMIT ?",
+			both:       "LIMIT ? OFFSET ?",
+			offsetOnly: "LIMIT -1 OFFSET ?",
+		},
+	}
+
+	for _, item := range data {
+		query, args := item.d.LimitSQL(5)
+		a.Equal(args, []interface{}{5})
+		sqltest.Equal(a, query, item.limitOnly)
+
+		query, args = item.d.LimitSQL(5, 10)
+		a.Equal(args, []interface{}{5, 10})
+		sqltest.Equal(a, query, item.both)
+
+		query, args = item.d.LimitSQL(nil, 10)
+		a.Equal(args, []interface{}{10})
+		sqltest.Equal(a, query, item.offsetOnly)
+	}
+}
+
+// limit 和 offset 的值只能出现在参数中，不能直接写入语句。
+func TestLimitSQL_placeholder(t *testing.T) {
+	a := assert.New(t)
+
+	for _, d := range []base{&mysql{}, &postgres{}, &sqlite3{}} {
+		query, args := d.LimitSQL(123, 456)
+		a.Equal(args, []interface{}{123, 456})
+		a.False(strings.Contains(query, "123"), query).
+			False(strings.Contains(query, "456"), query)
+
+		query, args = d.LimitSQL(nil, 456)
+		a.Equal(args, []interface{}{456})
+		a.False(strings.Contains(query, "456"), query)
+	}
+
+	query, args := oracleLimitSQL(123, 456)
+	a.Equal(args, []interface{}{456, 123})
+	a.False(strings.ContainsAny(query, "123456"), query)
 }
 
 func TestOracleLimitSQL(t *testing.T) {
@@ -135,4 +472,1270 @@ func TestOracleLimitSQL(t *testing.T) {
 	query, ret = oracleLimitSQL(sql.Named("limit", 1), 2)
 	a.Equal(ret, []interface{}{2, sql.Named("limit", 1)})
 	sqltest.Equal(a, query, "offset ? rows fetch next @limit rows only")
+
+	// 仅 offset
+	query, ret = oracleLimitSQL(nil, 2)
+	a.Equal(ret, []interface{}{2})
+	sqltest.Equal(a, query, "OFFSET ? ROWS")
+}
+
+func TestStandardCreateIndexSQL(t *testing.T) {
+	a := assert.New(t)
+	col1 := &model.Column{Name: "id"}
+	col2 := &model.Column{Name: "username"}
+	cols := []*model.Column{col1, col2}
+
+	query := standardCreateIndexSQL("{#tbl}", "index_name", cols, false)
+	sqltest.Equal(a, query, "CREATE INDEX index_name ON {#tbl}({id},{username})")
+
+	query = standardCreateIndexSQL("{#tbl}", "unique_name", cols[:1], true)
+	sqltest.Equal(a, query, "CREATE UNIQUE INDEX unique_name ON {#tbl}({id})")
+
+	query = Mysql().CreateIndexSQL("tbl", "index_name", cols, true)
+	sqltest.Equal(a, query, "CREATE UNIQUE INDEX index_name ON tbl({id},{username})")
+
+	// 带 schema 的表名
+	query = Postgres().CreateIndexSQL("{s}.{#tbl}", "index_name", cols, false)
+	sqltest.Equal(a, query, "CREATE INDEX index_name ON {s}.{#tbl}({id},{username})")
+	query = Sqlite3().CreateIndexSQL("{s}.{#tbl}", "index_name", cols, false)
+	sqltest.Equal(a, query, "CREATE INDEX {s}.index_name ON {#tbl}({id},{username})")
+}
+
+func TestDropIndexSQL(t *testing.T) {
+	a := assert.New(t)
+
+	sqltest.Equal(a, Mysql().DropIndexSQL("{#tbl}", "index_name"), "DROP INDEX index_name ON {#tbl}")
+	sqltest.Equal(a, Postgres().DropIndexSQL("{#tbl}", "index_name"), "DROP INDEX index_name")
+	sqltest.Equal(a, Sqlite3().DropIndexSQL("{#tbl}", "index_name"), "DROP INDEX IF EXISTS index_name")
+
+	// 带 schema 的表名
+	sqltest.Equal(a, Mysql().DropIndexSQL("{s}.{#tbl}", "index_name"), "DROP INDEX index_name ON {s}.{#tbl}")
+	sqltest.Equal(a, Postgres().DropIndexSQL("{s}.{#tbl}", "index_name"), "DROP INDEX {s}.index_name")
+	sqltest.Equal(a, Sqlite3().DropIndexSQL("{s}.{#tbl}", "index_name"), "DROP INDEX IF EXISTS {s}.index_name")
+}
+
+func TestTypeForColumn(t *testing.T) {
+	a := assert.New(t)
+
+	col := &model.Column{Name: "name", GoType: reflect.TypeOf(""), Len1: 20, Nullable: true, HasDefault: true, Default: "abc"}
+	typ, err := Mysql().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "VARCHAR(20)")
+	typ, err = Postgres().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "VARCHAR(20)")
+	typ, err = Sqlite3().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "TEXT")
+
+	col = &model.Column{Name: "id", GoType: reflect.TypeOf(int64(1))}
+	typ, err = Mysql().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "BIGINT")
+	typ, err = Postgres().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "BIGINT")
+	typ, err = Sqlite3().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "INTEGER")
+
+	// 无符号整数，postgres 的 CHECK 约束不属于类型
+	col = &model.Column{Name: "age", GoType: reflect.TypeOf(int64(1)), Unsigned: true}
+	typ, err = Mysql().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "BIGINT UNSIGNED")
+	typ, err = Postgres().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "BIGINT")
+	a.True(col.Unsigned)
+
+	// 字符集和排序规则不属于类型
+	col = &model.Column{Name: "name", GoType: reflect.TypeOf(""), Len1: 20, Charset: "utf8mb4", Collate: "utf8mb4_bin"}
+	typ, err = Mysql().TypeForColumn(col)
+	a.NotError(err).Equal(typ, "VARCHAR(20)")
+	a.Equal(col.Charset, "utf8mb4")
+
+	// 不支持的类型
+	col = &model.Column{Name: "obj", GoType: reflect.TypeOf(struct{}{})}
+	typ, err = Mysql().TypeForColumn(col)
+	a.Error(err).Empty(typ)
+
+	typ, err = Mysql().TypeForColumn(nil)
+	a.Error(err).Empty(typ)
+}
+
+func TestPlaceholder(t *testing.T) {
+	a := assert.New(t)
+
+	// 由常量和参数混合组成的 WHERE 语句
+	where := func(d orm.Dialect) string {
+		age, err := d.FormatValue(18)
+		a.NotError(err)
+		deleted, err := d.FormatValue(false)
+		a.NotError(err)
+
+		return sqlbuilder.New("SELECT * FROM {#user} WHERE {age}>").
+			WriteString(age).
+			WriteString(" AND {name}=").WriteString(d.Placeholder(1)).
+			WriteString(" AND {deleted}=").WriteString(deleted).
+			WriteString(" AND {group}=").WriteString(d.Placeholder(2)).
+			String()
+	}
+
+	for _, d := range []orm.Dialect{Mysql(), Sqlite3()} {
+		a.Equal(d.Placeholder(1), "?").Equal(d.Placeholder(5), "?")
+
+		query, err := d.SQL(where(d))
+		a.NotError(err)
+		sqltest.Equal(a, query, "SELECT * FROM {#user} WHERE {age}>18 AND {name}=? AND {deleted}=0 AND {group}=?")
+	}
+
+	p := Postgres()
+	a.Equal(p.Placeholder(1), "$1").Equal(p.Placeholder(12), "$12")
+
+	// 已经是 $N 形式的占位符，不会再被转换
+	query, err := p.SQL(where(p))
+	a.NotError(err)
+	sqltest.Equal(a, query, "SELECT * FROM {#user} WHERE {age}>18 AND {name}=$1 AND {deleted}=FALSE AND {group}=$2")
+
+	// 不能同时出现两种形式的占位符
+	query, err = p.SQL("SELECT * FROM {#user} WHERE {name}=" + p.Placeholder(1) + " AND {group}=?")
+	a.Error(err).Empty(query)
+}
+
+func TestSavepointSQL(t *testing.T) {
+	a := assert.New(t)
+
+	for _, d := range []orm.Dialect{Mysql(), Postgres(), Sqlite3()} {
+		a.Equal(d.SavepointSQL("sp1"), "SAVEPOINT sp1")
+		a.Equal(d.ReleaseSavepointSQL("sp1"), "RELEASE SAVEPOINT sp1")
+		a.Equal(d.RollbackToSavepointSQL("sp1"), "ROLLBACK TO SAVEPOINT sp1")
+	}
+}
+
+type invalidFloat struct {
+	ID    int64   `orm:"name(id);ai"`
+	Price float64 `orm:"name(price)"`
+}
+
+func (o *invalidFloat) Meta() string {
+	return "name(orders)"
+}
+
+func TestCreateTableSQL_columnError(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&invalidFloat{})
+	a.NotError(err).NotNil(mod)
+
+	for _, d := range []base{&mysql{}, &postgres{}} {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.Error(err).Nil(sqls)
+		a.Equal(err.Error(), "CreateTableSQL: table=orders col=price: 请指定长度")
+	}
+}
+
+type typeArticle struct {
+	ID      int64  `orm:"name(id);ai"`
+	Content string `orm:"name(content);type(mediumtext)"`
+}
+
+func (o *typeArticle) Meta() string {
+	return "name(articles)"
+}
+
+func TestCreateTableSQL_type(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&typeArticle{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := (&mysql{}).CreateTableSQL(mod, true)
+	a.NotError(err).NotNil(sqls)
+
+	for _, d := range []base{&postgres{}, &sqlite3{}} {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.Error(err).Nil(sqls)
+		a.Equal(err.Error(), "CreateTableSQL: table=articles col=content: sqlType:不支持的类型:[mediumtext]")
+	}
+}
+
+func TestCreateTableSQL_colsOrder(t *testing.T) {
+	a := assert.New(t)
+
+	type order struct {
+		Zebra string `orm:"name(zebra);len(20)"`
+		Apple int64  `orm:"name(apple)"`
+		Mango int64  `orm:"name(mango)"`
+	}
+	mod, err := model.New(&order{})
+	a.NotError(err).NotNil(mod)
+
+	for i := 0; i < 10; i++ {
+		sqls, err := m.CreateTableSQL(mod, true)
+		a.NotError(err).Equal(len(sqls), 1)
+		sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#order}({zebra} VARCHAR(20) NOT NULL,{apple} BIGINT NOT NULL,{mango} BIGINT NOT NULL)")
+	}
+}
+
+func TestCreateTableSQL_autoRandom(t *testing.T) {
+	a := assert.New(t)
+
+	type autoRandom struct {
+		ID   int64  `orm:"name(id);autorandom"`
+		Name string `orm:"name(name);len(20)"`
+	}
+	mod, err := model.New(&autoRandom{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := m.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#autoRandom}({id} BIGINT NOT NULL PRIMARY KEY AUTO_RANDOM,{name} VARCHAR(20) NOT NULL)")
+
+	for _, d := range []base{&postgres{}, &sqlite3{}} {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.Error(err).Nil(sqls)
+	}
+}
+
+func TestCreateTableSQL_aiOptions(t *testing.T) {
+	a := assert.New(t)
+
+	type aiOptions struct {
+		ID   int64  `orm:"name(id);ai(100)"`
+		Name string `orm:"name(name);len(20)"`
+	}
+	mod, err := model.New(&aiOptions{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := m.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#aiOptions}({id} BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT,{name} VARCHAR(20) NOT NULL) AUTO_INCREMENT=100")
+
+	sqls, err = (&postgres{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#aiOptions}({id} BIGINT GENERATED BY DEFAULT AS IDENTITY (START WITH 100 INCREMENT BY 1) NOT NULL,{name} VARCHAR(20) NOT NULL,CONSTRAINT aiOptionspk PRIMARY KEY({id}))")
+
+	sqls, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 2)
+	sqltest.Equal(a, sqls[1], "INSERT INTO SQLITE_SEQUENCE(name,seq) SELECT '#aiOptions',99 WHERE NOT EXISTS(SELECT 1 FROM SQLITE_SEQUENCE WHERE name='#aiOptions')")
+
+	// mysql 和 sqlite3 不支持步长
+	type aiStep struct {
+		ID int64 `orm:"name(id);ai(100,2)"`
+	}
+	mod, err = model.New(&aiStep{})
+	a.NotError(err).NotNil(mod)
+	sqls, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+	sqls, err = m.CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+}
+
+type schemaObj struct {
+	ID   int64  `orm:"name(id);ai(5)"`
+	Name string `orm:"name(name);len(20);index(index_name)"`
+}
+
+func (s *schemaObj) Meta() string {
+	return "name(events);schema(analytics)"
+}
+
+func TestCreateTableSQL_schema(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&schemaObj{})
+	a.NotError(err).NotNil(mod)
+
+	data := map[base]string{
+		&mysql{}:    "CREATE TABLE IF NOT EXISTS `analytics`.`p_events`(",
+		&postgres{}: `CREATE TABLE IF NOT EXISTS "analytics"."p_events"(`,
+		&sqlite3{}:  "CREATE TABLE IF NOT EXISTS `analytics`.`p_events`(",
+	}
+	for d, wont := range data {
+		l, r := d.QuoteTuple()
+
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.NotError(err).NotEmpty(sqls)
+		query := sqlbuilder.ReplaceQuotes(sqls[0], l, r, "p_")
+		a.True(strings.HasPrefix(query, wont), query)
+
+		// 索引，mysql 的索引在 CREATE TABLE 中指定；
+		// sqlite3 的 schema 需要指定在索引名上。
+		if len(sqls) > 1 {
+			query = sqlbuilder.ReplaceQuotes(sqls[len(sqls)-1], l, r, "p_")
+			if _, ok := d.(*sqlite3); ok {
+				a.True(strings.Contains(query, string(l)+"analytics"+string(r)+".index_name ON "+string(l)+"p_events"+string(r)), query)
+			} else {
+				a.True(strings.Contains(query, " ON "+string(l)+"analytics"+string(r)+"."+string(l)+"p_events"+string(r)), query)
+			}
+		}
+
+		// insert
+		query, _ = d.InsertSQL(mod, false)
+		query = sqlbuilder.ReplaceQuotes(query, l, r, "p_")
+		a.True(strings.HasPrefix(query, "INSERT INTO "+string(l)+"analytics"+string(r)+"."+string(l)+"p_events"+string(r)), query)
+	}
+
+	// sqlite3 的 SQLITE_SEQUENCE 位于相同的数据库中
+	sqls, err := (&sqlite3{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 3)
+	sqltest.Equal(a, sqls[1], "INSERT INTO {analytics}.SQLITE_SEQUENCE(name,seq) SELECT '#events',4 WHERE NOT EXISTS(SELECT 1 FROM {analytics}.SQLITE_SEQUENCE WHERE name='#events')")
+}
+
+func TestCreateTableSQL_ifNotExists(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&checkObj{})
+	a.NotError(err).NotNil(mod)
+
+	for _, d := range []base{&mysql{version: "8.0.16"}, &postgres{}, &sqlite3{}} {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.NotError(err).NotEmpty(sqls)
+		a.True(strings.HasPrefix(sqls[0], "CREATE TABLE IF NOT EXISTS {#checks}("), sqls[0])
+
+		sqls, err = d.CreateTableSQL(mod, false)
+		a.NotError(err).NotEmpty(sqls)
+		a.True(strings.HasPrefix(sqls[0], "CREATE TABLE {#checks}("), sqls[0])
+	}
+}
+
+type indexCollate struct {
+	ID   int64  `orm:"name(id);ai"`
+	Name string `orm:"name(name);len(20);index(index_name,collate:utf8mb4_general_ci)"`
+	Age  int    `orm:"name(age);index(index_name)"`
+}
+
+func TestCreateTableSQL_indexCollate(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&indexCollate{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := (&mysql{version: "8.0.13"}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	a.True(strings.Contains(sqls[0], "INDEX index_name(({name} COLLATE {utf8mb4_general_ci}),{age})"), sqls[0])
+
+	// 低版本的 mysql 不支持
+	sqls, err = (&mysql{version: "8.0.12"}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+
+	for _, d := range []base{&postgres{}, &sqlite3{}} {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.NotError(err).Equal(len(sqls), 2)
+		sqltest.Equal(a, sqls[1], "CREATE INDEX index_name ON {#indexCollate}({name} COLLATE {utf8mb4_general_ci},{age})")
+	}
+
+	// 替换之后的语句
+	sqls, err = (&postgres{}).CreateTableSQL(mod, true)
+	a.NotError(err)
+	query := sqlbuilder.ReplaceQuotes(sqls[1], '"', '"', "")
+	a.Equal(query, `CREATE INDEX index_name ON "indexCollate"("name" COLLATE "utf8mb4_general_ci","age")`)
+}
+
+type partitionObj struct {
+	ID      int64     `orm:"name(id);pk"`
+	Created time.Time `orm:"name(created);pk"`
+	Name    string    `orm:"name(name);len(20)"`
+}
+
+func (p *partitionObj) Meta() string {
+	return "name(events);partition(range,created);partition_values(p2017:2018-01-01,pmax:MAXVALUE)"
+}
+
+type partitionPK struct {
+	ID      int64     `orm:"name(id);ai"`
+	Created time.Time `orm:"name(created)"`
+}
+
+func (p *partitionPK) Meta() string {
+	return "partition(range,created);partition_values(p2017:2018-01-01)"
+}
+
+func TestCreateTableSQL_partition(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&partitionObj{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := m.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#events}({id} BIGINT NOT NULL,{created} DATETIME NOT NULL,{name} VARCHAR(20) NOT NULL,CONSTRAINT pk PRIMARY KEY({id},{created})) PARTITION BY RANGE COLUMNS({created})(PARTITION p2017 VALUES LESS THAN('2018-01-01'),PARTITION pmax VALUES LESS THAN(MAXVALUE))")
+
+	// 其它数据库不支持
+	sqls, err = (&postgres{}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+	sqls, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+
+	// 分区列不在主键中
+	mod, err = model.New(&partitionPK{})
+	a.NotError(err).NotNil(mod)
+	sqls, err = m.CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+}
+
+type dialectTypes struct {
+	ID      int64  `orm:"name(id);ai"`
+	Options string `orm:"name(options);len(-1);type(mysql:JSON,sqlite3:TEXT)"`
+}
+
+func TestCreateTableSQL_dialectTypes(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&dialectTypes{})
+	a.NotError(err).NotNil(mod)
+	a.True(strings.Contains(mod.String(), "type(mysql:JSON,sqlite3:TEXT)"))
+
+	buf := sqlbuilder.New("")
+	a.NotError(m.sqlType(buf, mod.Cols["options"]))
+	a.Equal(buf.String(), "JSON")
+
+	buf.Reset()
+	a.NotError((&sqlite3{}).sqlType(buf, mod.Cols["options"]))
+	a.Equal(buf.String(), "TEXT")
+
+	// 未指定的数据库采用默认的类型
+	buf.Reset()
+	a.NotError((&postgres{}).sqlType(buf, mod.Cols["options"]))
+	a.Equal(buf.String(), "TEXT")
+}
+
+type checkObj struct {
+	ID  int64 `orm:"name(id);ai"`
+	Age int   `orm:"name(age);check(chk_age,{age}>0 AND {age}<200)"`
+}
+
+func (c *checkObj) Meta() string {
+	return "name(checks);check(chk_id,{id}>0)"
+}
+
+func TestCreateTableSQL_check(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&checkObj{})
+	a.NotError(err).NotNil(mod)
+
+	data := map[base]string{
+		&mysql{version: "8.0.16"}: "CONSTRAINT chk_age CHECK(`age`>0 AND `age`<200), CONSTRAINT chk_id CHECK(`id`>0)",
+		&postgres{}:               `CONSTRAINT chk_age CHECK("age">0 AND "age"<200), CONSTRAINT chk_id CHECK("id">0)`,
+		&sqlite3{}:                "CONSTRAINT chk_age CHECK(`age`>0 AND `age`<200), CONSTRAINT chk_id CHECK(`id`>0)",
+	}
+	for d, wont := range data {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.NotError(err).NotEmpty(sqls)
+
+		l, r := d.QuoteTuple()
+		query := sqlbuilder.ReplaceQuotes(sqls[0], l, r, "p_")
+		a.True(strings.Contains(query, wont), query)
+	}
+
+	// 低版本的 mysql 不支持 check
+	sqls, err := (&mysql{version: "5.7"}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+}
+
+// 表名和列名都为 SQL 关键字
+type keyword struct {
+	Select int64  `orm:"name(select);ai"`
+	From   string `orm:"name(from);len(20);index(index_from)"`
+	Group  int64  `orm:"name(group);unique(unique_group)"`
+}
+
+func (k *keyword) Meta() string {
+	return "name(order)"
+}
+
+var keywordExpr = regexp.MustCompile(`\b(p_order|select|from|group)\b`)
+
+func TestKeywords(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	for _, d := range []base{&mysql{}, &postgres{}, &sqlite3{}} {
+		l, r := d.QuoteTuple()
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.NotError(err).NotEmpty(sqls)
+		sqls = append(sqls, d.TruncateTableSQL("#order", "select"))
+
+		for _, query := range sqls {
+			query = sqlbuilder.ReplaceQuotes(query, l, r, "p_")
+			a.False(strings.ContainsAny(query, "{}"), query)
+
+			// 去掉所有被引号包含的标识符之后，不应该再出现这些名称。
+			// 生成的关键字都是大写，所以此处区分大小写。
+			for _, name := range []string{"p_order", "select", "from", "group"} {
+				query = strings.Replace(query, string(l)+name+string(r), "", -1)
+			}
+			query = strings.Replace(query, "'p_order'", "", -1) // sqlite3 truncate 中的字符串
+			a.False(keywordExpr.MatchString(query), query)
+		}
+	}
+}
+
+func TestExistsTableSQL(t *testing.T) {
+	a := assert.New(t)
+
+	query, args := (&mysql{}).ExistsTableSQL("", "#users")
+	a.Empty(args)
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=DATABASE() AND table_name='#users'")
+
+	query, args = (&mysql{}).ExistsTableSQL("s", "#users")
+	a.Empty(args)
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema='s' AND table_name='#users'")
+
+	query, args = (&postgres{}).ExistsTableSQL("", "#users")
+	a.Empty(args)
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM pg_tables WHERE schemaname=CURRENT_SCHEMA() AND tablename='#users'")
+
+	query, args = (&postgres{}).ExistsTableSQL("s", "#users")
+	a.Empty(args)
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM pg_tables WHERE schemaname='s' AND tablename='#users'")
+
+	query, args = (&sqlite3{}).ExistsTableSQL("s", "#users")
+	a.Empty(args)
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM {s}.sqlite_master WHERE type='table' AND name='#users'")
+
+	query, args = (&sqlite3{}).ExistsTableSQL("", "#users")
+	a.Empty(args)
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='#users'")
+
+	// 表名前缀依然会被替换
+	query = sqlbuilder.ReplaceQuotes(query, '"', '"', "p_")
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='p_users'")
+}
+
+func TestNextAIValueSQL(t *testing.T) {
+	a := assert.New(t)
+
+	query, args, err := (&mysql{}).NextAIValueSQL("", "#users")
+	a.NotError(err).Empty(args)
+	sqltest.Equal(a, query, "SELECT AUTO_INCREMENT FROM information_schema.tables WHERE table_schema=DATABASE() AND table_name='#users'")
+
+	query, args, err = (&mysql{}).NextAIValueSQL("s", "#users")
+	a.NotError(err).Empty(args)
+	sqltest.Equal(a, query, "SELECT AUTO_INCREMENT FROM information_schema.tables WHERE table_schema='s' AND table_name='#users'")
+
+	query, args, err = (&postgres{}).NextAIValueSQL("", "#users")
+	a.Equal(err, sqlbuilder.ErrNotSupported).Empty(query).Nil(args)
+
+	query, args, err = (&sqlite3{}).NextAIValueSQL("", "#users")
+	a.Equal(err, sqlbuilder.ErrNotSupported).Empty(query).Nil(args)
+}
+
+func TestStandardInsertSQL(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	query, names := standardInsertSQL(mod, false)
+	a.Equal(names, []string{"From", "Group"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group}) VALUES(?,?)")
+
+	query, names = standardInsertSQL(mod, true)
+	a.Equal(names, []string{"From", "Group", "Select"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group},{select}) VALUES(?,?,?)")
+
+	// 只读列
+	mod, err = model.New(&readonlyObj{})
+	a.NotError(err).NotNil(mod)
+	query, names = standardInsertSQL(mod, false)
+	a.Equal(names, []string{"Name"})
+	sqltest.Equal(a, query, "INSERT INTO {#readonlyObj}({name}) VALUES(?)")
+
+	// 没有可插入的列
+	mod, err = model.New(&aiOnlyObj{})
+	a.NotError(err).NotNil(mod)
+	query, names = standardInsertSQL(mod, false)
+	a.Empty(names)
+	sqltest.Equal(a, query, "INSERT INTO {#aiOnlyObj} DEFAULT VALUES")
+	query, names = (&postgres{}).InsertSQL(mod, false)
+	a.Empty(names)
+	sqltest.Equal(a, query, "INSERT INTO {#aiOnlyObj} DEFAULT VALUES RETURNING {id}")
+	query, names = (&mysql{}).InsertSQL(mod, false)
+	a.Empty(names)
+	sqltest.Equal(a, query, "INSERT INTO {#aiOnlyObj}() VALUES()")
+
+	query, names = standardInsertSQL(mod, true)
+	a.Equal(names, []string{"ID"})
+	sqltest.Equal(a, query, "INSERT INTO {#aiOnlyObj}({id}) VALUES(?)")
+}
+
+type aiOnlyObj struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+type readonlyObj struct {
+	ID      int64  `orm:"name(id);ai"`
+	Name    string `orm:"name(name);len(20)"`
+	Created int64  `orm:"name(created);readonly"`
+}
+
+func TestInsertSQL_returning(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	p := &postgres{}
+	a.False(p.LastInsertIDSupported())
+	query, names := p.InsertSQL(mod, false)
+	a.Equal(names, []string{"From", "Group"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group}) VALUES(?,?) RETURNING {select}")
+
+	// 包含自增列
+	query, _ = p.InsertSQL(mod, true)
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group},{select}) VALUES(?,?,?)")
+
+	// 没有自增列
+	mod, err = model.New(&noPKObj{})
+	a.NotError(err).NotNil(mod)
+	query, _ = p.InsertSQL(mod, false)
+	sqltest.Equal(a, query, "INSERT INTO {#noPKObj}({name}) VALUES(?)")
+
+	a.True(m.LastInsertIDSupported())
+	a.True((&sqlite3{}).LastInsertIDSupported())
+}
+
+func TestStandardMultiInsertSQL(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	query, names, err := standardMultiInsertSQL(mod, 3, false)
+	a.NotError(err)
+	a.Equal(names, []string{"From", "Group"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group}) VALUES(?,?),(?,?),(?,?)")
+
+	query, names, err = standardMultiInsertSQL(mod, 1, true)
+	a.NotError(err)
+	a.Equal(names, []string{"From", "Group", "Select"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group},{select}) VALUES(?,?,?)")
+
+	query, names, err = standardMultiInsertSQL(mod, 0, false)
+	a.Error(err).Empty(query).Nil(names)
+
+	// 没有可插入的列
+	mod, err = model.New(&aiOnlyObj{})
+	a.NotError(err).NotNil(mod)
+	query, names, err = standardMultiInsertSQL(mod, 1, false)
+	a.NotError(err).Empty(names)
+	sqltest.Equal(a, query, "INSERT INTO {#aiOnlyObj} DEFAULT VALUES")
+	query, names, err = standardMultiInsertSQL(mod, 2, false)
+	a.Error(err).Empty(query).Nil(names)
+	query, names, err = (&mysql{}).MultiInsertSQL(mod, 2, false)
+	a.NotError(err).Empty(names)
+	sqltest.Equal(a, query, "INSERT INTO {#aiOnlyObj}() VALUES(),()")
+}
+
+type occObj struct {
+	ID      int64  `orm:"name(id);ai"`
+	Name    string `orm:"name(name);len(20)"`
+	Version int64  `orm:"name(version);occ"`
+}
+
+type noPKObj struct {
+	Name string `orm:"name(name);len(20)"`
+}
+
+func TestStandardUpsertSQL(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	// 默认以主键判断冲突，主键为自增列，所以需要包含自增列
+	query, names, err := standardUpsertSQL(mod, nil, nil)
+	a.NotError(err)
+	a.Equal(names, []string{"From", "Group", "Select"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group},{select}) VALUES(?,?,?) ON CONFLICT({select}) DO UPDATE SET {from}=EXCLUDED.{from},{group}=EXCLUDED.{group}")
+
+	query, names, err = standardUpsertSQL(mod, []string{"group"}, []string{"from"})
+	a.NotError(err)
+	a.Equal(names, []string{"From", "Group"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group}) VALUES(?,?) ON CONFLICT({group}) DO UPDATE SET {from}=EXCLUDED.{from}")
+
+	// 不存在的列
+	_, _, err = standardUpsertSQL(mod, []string{"not-exists"}, nil)
+	a.Error(err)
+	_, _, err = standardUpsertSQL(mod, nil, []string{"not-exists"})
+	a.Error(err)
+
+	// 更新冲突列
+	_, _, err = standardUpsertSQL(mod, []string{"group"}, []string{"group"})
+	a.Error(err)
+
+	// 乐观锁
+	mod, err = model.New(&occObj{})
+	a.NotError(err).NotNil(mod)
+	query, names, err = standardUpsertSQL(mod, nil, nil)
+	a.NotError(err)
+	a.Equal(names, []string{"ID", "Name", "Version"})
+	sqltest.Equal(a, query, "INSERT INTO {#occObj}({id},{name},{version}) VALUES(?,?,?) ON CONFLICT({id}) DO UPDATE SET {name}=EXCLUDED.{name},{version}={#occObj}.{version}+1")
+
+	// 没有主键
+	mod, err = model.New(&noPKObj{})
+	a.NotError(err).NotNil(mod)
+	_, _, err = standardUpsertSQL(mod, nil, nil)
+	a.Error(err)
+}
+
+func TestUpsertSQL(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	query, names, err := m.UpsertSQL(mod, nil, nil)
+	a.NotError(err)
+	a.Equal(names, []string{"From", "Group", "Select"})
+	sqltest.Equal(a, query, "INSERT INTO {#order}({from},{group},{select}) VALUES(?,?,?) ON DUPLICATE KEY UPDATE {from}=VALUES({from}),{group}=VALUES({group})")
+
+	mod, err = model.New(&occObj{})
+	a.NotError(err).NotNil(mod)
+	query, _, err = m.UpsertSQL(mod, nil, []string{"name"})
+	a.NotError(err)
+	sqltest.Equal(a, query, "INSERT INTO {#occObj}({id},{name},{version}) VALUES(?,?,?) ON DUPLICATE KEY UPDATE {name}=VALUES({name}),{version}={version}+1")
+
+	// 版本不支持
+	_, _, err = Sqlite3Version("3.23.0").UpsertSQL(mod, nil, nil)
+	a.Equal(err, sqlbuilder.ErrNotSupported)
+	_, _, err = PostgresVersion("9.4").UpsertSQL(mod, nil, nil)
+	a.Equal(err, sqlbuilder.ErrNotSupported)
+
+	_, _, err = Sqlite3().UpsertSQL(mod, nil, nil)
+	a.NotError(err)
+}
+
+func TestStandardUpdateSQL(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+
+	query, names, err := standardUpdateSQL(mod, nil)
+	a.NotError(err)
+	a.Equal(names, []string{"From", "Group", "Select"})
+	sqltest.Equal(a, query, "UPDATE {#order} SET {from}=?,{group}=? WHERE {select}=?")
+
+	query, names, err = standardUpdateSQL(mod, []string{"group"})
+	a.NotError(err)
+	a.Equal(names, []string{"Group", "Select"})
+	sqltest.Equal(a, query, "UPDATE {#order} SET {group}=? WHERE {select}=?")
+
+	// 不存在的列
+	_, _, err = standardUpdateSQL(mod, []string{"not-exists"})
+	a.Error(err)
+
+	// 主键
+	_, _, err = standardUpdateSQL(mod, []string{"select"})
+	a.Error(err)
+
+	// 乐观锁
+	mod, err = model.New(&occObj{})
+	a.NotError(err).NotNil(mod)
+	query, names, err = standardUpdateSQL(mod, nil)
+	a.NotError(err)
+	a.Equal(names, []string{"Name", "ID", "Version"})
+	sqltest.Equal(a, query, "UPDATE {#occObj} SET {name}=?,{version}={version}+1 WHERE {id}=? AND {version}=?")
+
+	_, _, err = standardUpdateSQL(mod, []string{"version"})
+	a.Error(err)
+
+	// 只读列
+	mod, err = model.New(&readonlyObj{})
+	a.NotError(err).NotNil(mod)
+	query, names, err = standardUpdateSQL(mod, nil)
+	a.NotError(err)
+	a.Equal(names, []string{"Name", "ID"})
+	sqltest.Equal(a, query, "UPDATE {#readonlyObj} SET {name}=? WHERE {id}=?")
+
+	_, _, err = standardUpdateSQL(mod, []string{"created"})
+	a.Error(err)
+
+	// 没有主键
+	mod, err = model.New(&noPKObj{})
+	a.NotError(err).NotNil(mod)
+	_, _, err = standardUpdateSQL(mod, nil)
+	a.Error(err)
+}
+
+type softDeleteObj struct {
+	ID      int64      `orm:"name(id);ai"`
+	Deleted *time.Time `orm:"name(deleted_at);softdelete"`
+}
+
+func TestStandardDeleteSQL(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&keyword{})
+	a.NotError(err).NotNil(mod)
+	query, names, err := standardDeleteSQL(mod)
+	a.NotError(err)
+	a.Equal(names, []string{"Select"})
+	sqltest.Equal(a, query, "DELETE FROM {#order} WHERE {select}=?")
+
+	// 软删除
+	mod, err = model.New(&softDeleteObj{})
+	a.NotError(err).NotNil(mod)
+	query, names, err = standardDeleteSQL(mod)
+	a.NotError(err)
+	a.Equal(names, []string{"Deleted", "ID"})
+	sqltest.Equal(a, query, "UPDATE {#softDeleteObj} SET {deleted_at}=? WHERE {id}=?")
+
+	// 没有主键
+	mod, err = model.New(&noPKObj{})
+	a.NotError(err).NotNil(mod)
+	_, _, err = standardDeleteSQL(mod)
+	a.Error(err)
+}
+
+func TestDialect_Name(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(Mysql().Name(), "mysql")
+	a.Equal(Postgres().Name(), "postgres")
+	a.Equal(Sqlite3().Name(), "sqlite3")
+}
+
+type (
+	regMoney int64
+	regUUID  [16]byte
+)
+
+func TestRegisterType(t *testing.T) {
+	a := assert.New(t)
+	defer func() {
+		typesMux.Lock()
+		for _, ts := range types {
+			delete(ts, reflect.TypeOf(regMoney(0)))
+			delete(ts, reflect.TypeOf(regUUID{}))
+		}
+		typesMux.Unlock()
+	}()
+
+	a.Error(RegisterType("", reflect.TypeOf(regUUID{}), "CHAR(36)"))
+	a.Error(RegisterType("mysql", nil, "CHAR(36)"))
+	a.Error(RegisterType("mysql", reflect.TypeOf(regUUID{}), ""))
+
+	a.NotError(RegisterType("mysql", reflect.TypeOf(regUUID{}), "CHAR(36)"))
+	a.NotError(RegisterType("postgres", reflect.TypeOf(regUUID{}), "UUID"))
+	a.NotError(RegisterType("mysql", reflect.TypeOf(regMoney(0)), "DECIMAL(19,4)"))
+
+	col := &model.Column{GoType: reflect.TypeOf(regUUID{})}
+	buf := sqlbuilder.New("")
+	a.NotError(Mysql().(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "CHAR(36)")
+
+	buf.Reset()
+	a.NotError(Postgres().(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "UUID")
+
+	// 未在 sqlite3 中注册，使用默认的类型
+	buf.Reset()
+	a.NotError(Sqlite3().(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT")
+
+	// 注册的类型忽略 len 等属性
+	col = &model.Column{GoType: reflect.TypeOf(regMoney(0)), Len1: 5}
+	buf.Reset()
+	a.NotError(Mysql().(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "DECIMAL(19,4)")
+
+	// 覆盖
+	a.NotError(RegisterType("mysql", reflect.TypeOf(regMoney(0)), "BIGINT"))
+	buf.Reset()
+	a.NotError(Mysql().(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BIGINT")
+
+	// 未注册的类型不受影响
+	col = &model.Column{GoType: reflect.TypeOf(int64(0))}
+	buf.Reset()
+	a.NotError(Mysql().(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BIGINT")
+}
+
+var (
+	_ orm.VersionedDialect = &mysql{}
+	_ orm.VersionedDialect = &postgres{}
+	_ orm.VersionedDialect = &sqlite3{}
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(versionAtLeast("", "8.0.16"))
+	a.True(versionAtLeast("8.0.16", "8.0.16"))
+	a.True(versionAtLeast("8.0.16-log", "8.0.16"))
+	a.True(versionAtLeast("8.1", "8.0.16"))
+	a.True(versionAtLeast("12", "9.4"))
+	a.True(versionAtLeast("5.7.8", "5.7"))
+	a.False(versionAtLeast("5.7", "5.7.8"))
+	a.False(versionAtLeast("5.6.40", "5.7.8"))
+	a.False(versionAtLeast("9.3", "9.4"))
+	a.False(versionAtLeast("abc", "1"))
+}
+
+func TestSupportsFeature(t *testing.T) {
+	a := assert.New(t)
+
+	my := MysqlVersion("5.6").(orm.VersionedDialect)
+	a.Equal(my.Version(), "5.6")
+	a.False(my.SupportsFeature(orm.FeatureCheck, my.Version()))
+	a.False(my.SupportsFeature(orm.FeatureJSON, my.Version()))
+	a.True(my.SupportsFeature(orm.FeatureCheck, "8.0.16"))
+	a.True(my.SupportsFeature(orm.FeatureJSON, ""))
+	a.False(my.SupportsFeature("unknown", ""))
+	a.Equal(MysqlVersion(""), Mysql())
+
+	pg := PostgresVersion("11.2").(orm.VersionedDialect)
+	a.True(pg.SupportsFeature(orm.FeatureCheck, pg.Version()))
+	a.False(pg.SupportsFeature(orm.FeatureGenerated, pg.Version()))
+	a.True(pg.SupportsFeature(orm.FeatureReturning, pg.Version()))
+	a.False(Mysql().(orm.VersionedDialect).SupportsFeature(orm.FeatureReturning, ""))
+	a.Equal(PostgresVersion(""), Postgres())
+
+	s := Sqlite3Version("3.30.1").(orm.VersionedDialect)
+	a.False(s.SupportsFeature(orm.FeatureGenerated, s.Version()))
+	a.True(s.SupportsFeature(orm.FeatureGenerated, "3.31.0"))
+	a.False(s.SupportsFeature(orm.FeatureReturning, "3.34.1"))
+	a.True(s.SupportsFeature(orm.FeatureReturning, "3.35.0"))
+	a.Equal(Sqlite3Version(""), Sqlite3())
+}
+
+type versionObj struct {
+	ID    int64             `orm:"name(id);ai"`
+	Attrs map[string]string `orm:"name(attrs);serialize(json)"`
+}
+
+func (v *versionObj) Meta() string {
+	return "check(chk_id,{id}>0);name(versions)"
+}
+
+func TestCreateTableSQL_version(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&versionObj{})
+	a.NotError(err).NotNil(mod)
+
+	// check 约束在 8.0.16 之前会被忽略
+	sqls, err := MysqlVersion("5.7.20").CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+	_, err = MysqlVersion("5.7.20").AddConstraintSQL("#versions", "chk_id", &model.Constraint{
+		Type:  model.ConstraintCheck,
+		Check: "{id}>0",
+	})
+	a.Error(err)
+
+	sqls, err = MysqlVersion("8.0.16").CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	a.True(strings.Contains(sqls[0], "{attrs} JSON NOT NULL"), sqls[0])
+
+	// JSON 类型
+	col := mod.Cols["attrs"]
+	buf := sqlbuilder.New("")
+	a.NotError(MysqlVersion("5.7.7").(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "LONGTEXT")
+
+	buf.Reset()
+	a.NotError(PostgresVersion("9.3").(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT")
+
+	buf.Reset()
+	a.NotError(PostgresVersion("9.4").(base).sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "JSONB")
+
+	// 生成列
+	col = &model.Column{
+		Name:            "total",
+		GoType:          reflect.TypeOf(int64(1)),
+		Generated:       "{id}*2",
+		GeneratedStored: true,
+	}
+	buf.Reset()
+	a.Error(MysqlVersion("5.7.5").(base).sqlType(buf, col))
+	buf.Reset()
+	a.NotError(MysqlVersion("5.7.6").(base).sqlType(buf, col))
+	buf.Reset()
+	a.Error(PostgresVersion("11").(base).sqlType(buf, col))
+	buf.Reset()
+	a.Error(Sqlite3Version("3.30").(base).sqlType(buf, col))
+	buf.Reset()
+	a.NotError(Sqlite3Version("3.31.1").(base).sqlType(buf, col))
+}
+
+func TestCreateViewSQL(t *testing.T) {
+	a := assert.New(t)
+	query := "SELECT {id},{name} FROM {#user}"
+
+	for _, d := range []base{&mysql{}, &postgres{}} {
+		sqltest.Equal(a, d.CreateViewSQL("user_view", query, false), "CREATE VIEW {#user_view} AS "+query)
+		sqltest.Equal(a, d.CreateViewSQL("user_view", query, true), "CREATE OR REPLACE VIEW {#user_view} AS "+query)
+	}
+
+	s := &sqlite3{}
+	sqltest.Equal(a, s.CreateViewSQL("user_view", query, false), "CREATE VIEW {#user_view} AS "+query)
+	sqltest.Equal(a, s.CreateViewSQL("user_view", query, true), "DROP VIEW IF EXISTS {#user_view};CREATE VIEW {#user_view} AS "+query)
+}
+
+func TestAddConstraintSQL(t *testing.T) {
+	a := assert.New(t)
+
+	id := &model.Column{Name: "id"}
+	name := &model.Column{Name: "name"}
+
+	query, err := m.AddConstraintSQL("#user", "pk_user", &model.Constraint{
+		Type: model.ConstraintPK,
+		Cols: []*model.Column{id},
+	})
+	a.NotError(err)
+	sqltest.Equal(a, query, "ALTER TABLE {#user} ADD CONSTRAINT pk_user PRIMARY KEY({id})")
+
+	query, err = Postgres().AddConstraintSQL("#user", "u_user", &model.Constraint{
+		Type: model.ConstraintUnique,
+		Cols: []*model.Column{id, name},
+	})
+	a.NotError(err)
+	sqltest.Equal(a, query, "ALTER TABLE {#user} ADD CONSTRAINT u_user UNIQUE({id},{name})")
+
+	query, err = m.AddConstraintSQL("#user", "fk_user", &model.Constraint{
+		Type: model.ConstraintFK,
+		FK: &model.ForeignKey{
+			Cols:         []*model.Column{id},
+			RefTableName: "#group",
+			RefColNames:  []string{"id"},
+			DeleteRule:   "CASCADE",
+		},
+	})
+	a.NotError(err)
+	sqltest.Equal(a, query, "ALTER TABLE {#user} ADD CONSTRAINT fk_user FOREIGN KEY({id}) REFERENCES {#group}({id}) ON DELETE CASCADE")
+
+	query, err = m.AddConstraintSQL("#user", "chk_user", &model.Constraint{
+		Type:  model.ConstraintCheck,
+		Check: "{id}>0",
+	})
+	a.NotError(err)
+	sqltest.Equal(a, query, "ALTER TABLE {#user} ADD CONSTRAINT chk_user CHECK({id}>0)")
+
+	// 无效的参数
+	_, err = m.AddConstraintSQL("#user", "chk_user", nil)
+	a.Error(err)
+	_, err = m.AddConstraintSQL("#user", "chk_user", &model.Constraint{Type: model.ConstraintCheck})
+	a.Error(err)
+	_, err = m.AddConstraintSQL("#user", "pk_user", &model.Constraint{Type: model.ConstraintPK})
+	a.Error(err)
+	_, err = m.AddConstraintSQL("#user", "fk_user", &model.Constraint{Type: model.ConstraintFK})
+	a.Error(err)
+	_, err = m.AddConstraintSQL("#user", "xx", &model.Constraint{Type: "index"})
+	a.Error(err)
+
+	_, err = Sqlite3().AddConstraintSQL("#user", "chk_user", &model.Constraint{
+		Type:  model.ConstraintCheck,
+		Check: "{id}>0",
+	})
+	a.Equal(err, sqlbuilder.ErrNotSupported)
+}
+
+func TestDropConstraintSQL(t *testing.T) {
+	a := assert.New(t)
+
+	data := []*struct {
+		typ            string
+		mysql, postgre string
+	}{
+		{
+			typ:     model.ConstraintPK,
+			mysql:   "ALTER TABLE {#user} DROP PRIMARY KEY",
+			postgre: "ALTER TABLE {#user} DROP CONSTRAINT con_name",
+		},
+		{
+			typ:     model.ConstraintUnique,
+			mysql:   "ALTER TABLE {#user} DROP INDEX con_name",
+			postgre: "ALTER TABLE {#user} DROP CONSTRAINT con_name",
+		},
+		{
+			typ:     model.ConstraintFK,
+			mysql:   "ALTER TABLE {#user} DROP FOREIGN KEY con_name",
+			postgre: "ALTER TABLE {#user} DROP CONSTRAINT con_name",
+		},
+		{
+			typ:     model.ConstraintCheck,
+			mysql:   "ALTER TABLE {#user} DROP CHECK con_name",
+			postgre: "ALTER TABLE {#user} DROP CONSTRAINT con_name",
+		},
+	}
+
+	for _, item := range data {
+		query, err := m.DropConstraintSQL("#user", "con_name", item.typ)
+		a.NotError(err)
+		sqltest.Equal(a, query, item.mysql)
+
+		query, err = Postgres().DropConstraintSQL("#user", "con_name", item.typ)
+		a.NotError(err)
+		sqltest.Equal(a, query, item.postgre)
+
+		_, err = Sqlite3().DropConstraintSQL("#user", "con_name", item.typ)
+		a.Equal(err, sqlbuilder.ErrNotSupported)
+	}
+
+	_, err := m.DropConstraintSQL("#user", "con_name", "index")
+	a.Error(err)
+	_, err = Postgres().DropConstraintSQL("#user", "con_name", "index")
+	a.Error(err)
+}
+
+func TestTruncateTableSQL(t *testing.T) {
+	a := assert.New(t)
+
+	m := &mysql{}
+	sqltest.Equal(a, m.TruncateTableSQL("#tbl", ""), "TRUNCATE TABLE {#tbl}")
+	sqltest.Equal(a, m.TruncateTableSQL("#tbl", "id"), "TRUNCATE TABLE {#tbl}")
+
+	p := &postgres{}
+	sqltest.Equal(a, p.TruncateTableSQL("#tbl", ""), "TRUNCATE TABLE {#tbl}")
+	sqltest.Equal(a, p.TruncateTableSQL("#tbl", "id"), "TRUNCATE TABLE {#tbl} RESTART IDENTITY")
+
+	s := &sqlite3{}
+	sqltest.Equal(a, s.TruncateTableSQL("#tbl", ""), "DELETE FROM {#tbl}")
+	sqltest.Equal(a, s.TruncateTableSQL("#tbl", "id"), "DELETE FROM {#tbl};DELETE FROM SQLITE_SEQUENCE WHERE name='#tbl';")
+
+	// 带 schema 的表名
+	sqltest.Equal(a, m.TruncateTableSQL("s.#tbl", "id"), "TRUNCATE TABLE {s}.{#tbl}")
+	sqltest.Equal(a, p.TruncateTableSQL("s.#tbl", "id"), "TRUNCATE TABLE {s}.{#tbl} RESTART IDENTITY")
+	sqltest.Equal(a, s.TruncateTableSQL("s.#tbl", "id"), "DELETE FROM {s}.{#tbl};DELETE FROM {s}.SQLITE_SEQUENCE WHERE name='#tbl';")
+}
+
+type uniqueCond struct {
+	ID      int64      `orm:"name(id);ai"`
+	UID     int64      `orm:"name(uid);unique(u_active,where:{deleted_at} IS NULL)"`
+	Deleted *time.Time `orm:"name(deleted_at)"`
+}
+
+func TestCreateTableSQL_uniqueCond(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&uniqueCond{})
+	a.NotError(err).NotNil(mod)
+
+	for _, d := range []base{&postgres{}, &sqlite3{}} {
+		sqls, err := d.CreateTableSQL(mod, true)
+		a.NotError(err).Equal(len(sqls), 2)
+		a.False(strings.Contains(sqls[0], "u_active"))
+		sqltest.Equal(a, sqls[1], "CREATE UNIQUE INDEX u_active ON {#uniqueCond}({uid}) WHERE {deleted_at} IS NULL")
+	}
+
+	sqls, err := (&mysql{}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+}
+
+type aiNotPK struct {
+	Tenant int64 `orm:"name(tenant);pk"`
+	ID     int64 `orm:"name(id);ai;notpk;index(idx_id)"`
+	Seq    int64 `orm:"name(seq);pk"`
+}
+
+func (o *aiNotPK) Meta() string {
+	return "name(ai_not_pk)"
+}
+
+type aiNotPKWithoutIndex struct {
+	Tenant int64 `orm:"name(tenant);pk;index(idx_id)"`
+	ID     int64 `orm:"name(id);ai;notpk;index(idx_id)"`
+}
+
+func (o *aiNotPKWithoutIndex) Meta() string {
+	return "name(ai_not_pk_without_index)"
+}
+
+func TestCreateTableSQL_aiNotPK(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&aiNotPK{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := m.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#ai_not_pk}({id} BIGINT NOT NULL AUTO_INCREMENT,"+
+		"{tenant} BIGINT NOT NULL,{seq} BIGINT NOT NULL,"+
+		"CONSTRAINT pk PRIMARY KEY({tenant},{seq}),INDEX idx_id({id}))")
+
+	sqls, err = (&postgres{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 2)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#ai_not_pk}({tenant} BIGINT NOT NULL,"+
+		"{id} BIGSERIAL NOT NULL,{seq} BIGINT NOT NULL,"+
+		"CONSTRAINT ai_not_pkpk PRIMARY KEY({tenant},{seq}))")
+	sqltest.Equal(a, sqls[1], "CREATE INDEX idx_id ON {#ai_not_pk}({id})")
+
+	// sqlite3 的自增列只能是主键
+
+	sqls, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+
+	// 自增列不是任何索引的第一列
+	mod, err = model.New(&aiNotPKWithoutIndex{})
+	a.NotError(err).NotNil(mod)
+	sqls, err = m.CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+}
+
+type nullInt64AI struct {
+	ID      sql.NullInt64 `orm:"name(id);ai"`
+	Version sql.NullInt64 `orm:"name(version);occ"`
+}
+
+func (o *nullInt64AI) Meta() string {
+	return "name(null_int64_ai)"
+}
+
+func TestCreateTableSQL_nullInt64AI(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&nullInt64AI{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := m.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#null_int64_ai}("+
+		"{id} BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT,{version} BIGINT NOT NULL)")
+
+	sqls, err = (&postgres{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#null_int64_ai}("+
+		"{id} BIGSERIAL NOT NULL,{version} BIGINT NOT NULL,CONSTRAINT null_int64_aipk PRIMARY KEY({id}))")
+
+	sqls, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#null_int64_ai}("+
+		"{id} INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,{version} INTEGER NOT NULL)")
+}
+
+type timestamps struct {
+	ID int64 `orm:"name(id);ai"`
+	model.Timestamps
+}
+
+func (o *timestamps) Meta() string {
+	return "name(timestamps)"
+}
+
+func TestCreateTableSQL_timestamps(t *testing.T) {
+	a := assert.New(t)
+
+	mod, err := model.New(&timestamps{})
+	a.NotError(err).NotNil(mod)
+
+	sqls, err := m.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#timestamps}({id} BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT,"+
+		"{created_at} TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,"+
+		"{updated_at} TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)")
+
+	sqls, err = (&postgres{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#timestamps}({id} BIGSERIAL NOT NULL,"+
+		"{created_at} TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,"+
+		"{updated_at} TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,"+
+		"CONSTRAINT timestampspk PRIMARY KEY({id}))")
+
+	sqls, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#timestamps}({id} INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,"+
+		"{created_at} TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,"+
+		"{updated_at} TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)")
 }
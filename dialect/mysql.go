@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/model"
@@ -17,13 +18,42 @@ import (
 
 var mysqlInst *mysql
 
-type mysql struct{}
+func init() {
+	d := Mysql()
+	orm.RegisterDialect(d.Name(), d)
+}
+
+// 可以通过 type 属性指定的字符串类型
+var mysqlStringTypes = []string{"tinytext", "text", "mediumtext", "longtext"}
+
+// 可以通过 type 属性指定的时间类型
+var mysqlTimeTypes = []string{"datetime", "timestamp", "date", "time"}
+
+// 各个特性最低支持的版本
+var mysqlFeatures = map[string]string{
+	orm.FeatureJSON:      "5.7.8",
+	orm.FeatureCheck:     "8.0.16", // 之前的版本会忽略 CHECK 约束
+	orm.FeatureGenerated: "5.7.6",
+	orm.FeatureUpsert:    "0",
+
+	orm.FeatureIndexCollate: "8.0.13", // 以函数索引 ((col COLLATE x)) 的形式实现
+}
+
+type mysql struct {
+	version string
+}
 
 // Mysql 返回一个适配 mysql 的 Dialect 接口
 //
 // 支持以下 meta 属性
 //  charset 字符集，语法为： charset(utf-8)
 //  engine 使用的引擎，语法为： engine(innodb)
+//  auto_increment 自增列的起始值，语法为： auto_increment(1000)
+//  partition 和 partition_values 表分区，语法为： partition(range,created);partition_values(p2017:2018-01-01,pmax:MAXVALUE)
+//
+// 自增列的起始值也可以通过 ai(start) 指定，不能与 auto_increment 同时使用，
+// 通过 orm.DB.ReverseTable 获取的 Model 会以表中自增列的当前值作为起始值；
+// mysql 的自增步长由服务器变量 auto_increment_increment 决定，在 ai 中指定步长会返回错误。
 func Mysql() orm.Dialect {
 	if mysqlInst == nil {
 		mysqlInst = &mysql{}
@@ -32,36 +62,123 @@ func Mysql() orm.Dialect {
 	return mysqlInst
 }
 
+// MysqlVersion 返回一个适配指定版本 mysql 的 Dialect 接口
+//
+// 生成 SQL 时会根据版本号判断是否支持 JSON 类型、CHECK 约束等特性，
+// version 为空时与 Mysql() 的返回值相同。
+func MysqlVersion(version string) orm.Dialect {
+	if version == "" {
+		return Mysql()
+	}
+
+	return &mysql{version: version}
+}
+
+func (m *mysql) Version() string {
+	return m.version
+}
+
+func (m *mysql) SupportsFeature(feature, version string) bool {
+	return supportsFeature(mysqlFeatures, feature, version)
+}
+
+func (m *mysql) Name() string {
+	return "mysql"
+}
+
 func (m *mysql) QuoteTuple() (byte, byte) {
 	return '`', '`'
 }
 
+func (m *mysql) QuoteIdentifier(name string) string {
+	l, r := m.QuoteTuple()
+	return quoteIdentifier(name, l, r)
+}
+
 func (m *mysql) SQL(sql string) (string, error) {
 	return sql, nil
 }
 
-func (m *mysql) CreateTableSQL(model *model.Model) ([]string, error) {
-	w := sqlbuilder.New("CREATE TABLE IF NOT EXISTS ").
-		WriteString("{#").
-		WriteString(model.Name).
-		WriteString("}(")
+func (m *mysql) Placeholder(index int) string {
+	return "?"
+}
+
+// 默认情况下 mysql 会将反斜杠作为转义字符，
+// 除非启用了 NO_BACKSLASH_ESCAPES，所以对反斜杠也进行转义。
+func (m *mysql) QuoteString(s string) string {
+	return quoteString(s, true)
+}
+
+func (m *mysql) FormatValue(v interface{}) (string, error) {
+	return formatValue(v, true, "1", "0")
+}
+
+// mysql 中的 schema 即为数据库名
+func (m *mysql) ExistsTableSQL(schema, tableName string) (string, []interface{}) {
+	buf := sqlbuilder.New("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=")
+	if schema == "" {
+		buf.WriteString("DATABASE()")
+	} else {
+		buf.WriteString(m.QuoteString(schema))
+	}
+
+	return buf.WriteString(" AND table_name=").
+		WriteString(m.QuoteString(tableName)).
+		String(), nil
+}
+
+// mysql 8.0 之后 information_schema 中的统计信息会被缓存，
+// 需要将 information_schema_stats_expiry 设置为 0 才能读取到实时的值。
+func (m *mysql) NextAIValueSQL(schema, tableName string) (string, []interface{}, error) {
+	buf := sqlbuilder.New("SELECT AUTO_INCREMENT FROM information_schema.tables WHERE table_schema=")
+	if schema == "" {
+		buf.WriteString("DATABASE()")
+	} else {
+		buf.WriteString(m.QuoteString(schema))
+	}
+
+	return buf.WriteString(" AND table_name=").
+		WriteString(m.QuoteString(tableName)).
+		String(), nil, nil
+}
+
+func (m *mysql) CreateTableSQL(model *model.Model, ifNotExists bool) ([]string, error) {
+	if len(model.UniqueConds) > 0 {
+		return nil, errors.New("CreateTableSQL: mysql 不支持带条件的唯一索引")
+	}
+
+	if len(model.Check) > 0 && !m.SupportsFeature(orm.FeatureCheck, m.version) {
+		return nil, fmt.Errorf("CreateTableSQL: mysql %s 不支持 check 约束", m.version)
+	}
+
+	w := createTableSQL(model, ifNotExists)
 
 	// 自增列
-	if model.AI != nil {
+	if model.HasAutoIncrement() {
 		if err := createColSQL(m, w, model.AI); err != nil {
-			return nil, err
+			return nil, columnError(model, model.AI, err)
+		}
+		switch {
+		case model.AI.AutoRandom:
+			w.WriteString(" PRIMARY KEY AUTO_RANDOM,")
+		case model.AI.AINotPK: // 主键和索引在后面单独指定
+			if !isKeyPrefix(model, model.AI) {
+				return nil, fmt.Errorf("CreateTableSQL: 自增列 %s 必须是主键或是某一索引的第一列", model.AI.Name)
+			}
+			w.WriteString(" AUTO_INCREMENT,")
+		default:
+			w.WriteString(" PRIMARY KEY AUTO_INCREMENT,")
 		}
-		w.WriteString(" PRIMARY KEY AUTO_INCREMENT,")
 	}
 
 	// 普通列
-	for _, col := range model.Cols {
+	for _, col := range model.ColsOrder {
 		if col.IsAI() { // 忽略 AI 列
 			continue
 		}
 
 		if err := createColSQL(m, w, col); err != nil {
-			return nil, err
+			return nil, columnError(model, col, err)
 		}
 		w.WriteByte(',')
 	}
@@ -74,7 +191,9 @@ func (m *mysql) CreateTableSQL(model *model.Model) ([]string, error) {
 	createConstraints(w, model)
 
 	// index
-	m.createIndexSQL(w, model)
+	if err := m.createIndexSQL(w, model); err != nil {
+		return nil, err
+	}
 
 	w.TruncateLast(1).WriteByte(')')
 
@@ -82,51 +201,284 @@ func (m *mysql) CreateTableSQL(model *model.Model) ([]string, error) {
 		return nil, err
 	}
 
+	if err := m.createPartitionSQL(w, model); err != nil {
+		return nil, err
+	}
+
 	return []string{w.String()}, nil
 }
 
+// 表分区，以 RANGE COLUMNS 的形式生成，可以直接使用日期等非整数类型的值：
+//  PARTITION BY RANGE COLUMNS({created})(PARTITION p2017 VALUES LESS THAN('2018-01-01'),PARTITION pmax VALUES LESS THAN(MAXVALUE))
+func (m *mysql) createPartitionSQL(w *sqlbuilder.SQLBuilder, model *model.Model) error {
+	p := model.Partition
+	if p == nil {
+		return nil
+	}
+
+	// mysql 要求主键和唯一约束必须包含分区依据的列
+	if len(model.PK) > 0 && !containsColumn(model.PK, p.Column) {
+		return fmt.Errorf("分区列 %s 必须包含在主键中", p.Column.Name)
+	}
+	for name, cols := range model.UniqueIndexes {
+		if !containsColumn(cols, p.Column) {
+			return fmt.Errorf("分区列 %s 必须包含在唯一约束 %s 中", p.Column.Name, name)
+		}
+	}
+
+	w.WriteString(" PARTITION BY RANGE COLUMNS(").
+		Quote(p.Column.Name).
+		WriteString(")(")
+	for _, v := range p.Values {
+		w.WriteString("PARTITION ").
+			WriteString(v.Name).
+			WriteString(" VALUES LESS THAN(")
+		if strings.ToUpper(v.LessThan) == "MAXVALUE" {
+			w.WriteString("MAXVALUE")
+		} else if _, err := strconv.ParseFloat(v.LessThan, 64); err == nil {
+			w.WriteString(v.LessThan)
+		} else {
+			w.WriteString(m.QuoteString(v.LessThan))
+		}
+		w.WriteString("),")
+	}
+	w.TruncateLast(1).WriteByte(')')
+
+	return nil
+}
+
 func (m *mysql) createTableOptions(w *sqlbuilder.SQLBuilder, model *model.Model) error {
-	if len(model.Meta["engine"]) == 1 {
-		w.WriteString(" ENGINE=")
-		w.WriteString(model.Meta["engine"][0])
-		w.WriteByte(' ')
-	} else if len(model.Meta["engine"]) > 0 {
-		return errors.New("无效的属性值 engine")
+	if engine, found := model.Engine(); found {
+		w.WriteString(" ENGINE=").WriteString(engine).WriteByte(' ')
 	}
 
-	if len(model.Meta["charset"]) == 1 {
-		w.WriteString(" CHARACTER SET=")
-		w.WriteString(model.Meta["charset"][0])
+	if charset, found := model.Charset(); found {
+		w.WriteString(" CHARACTER SET=").WriteString(charset).WriteByte(' ')
+	}
+
+	if hasAIOptions(model.AI) {
+		if model.AI.AIStep > 1 {
+			return columnError(model, model.AI, errors.New("不支持指定自增列的步长"))
+		}
+
+		if len(model.Meta["auto_increment"]) > 0 {
+			return errors.New("auto_increment 不能与 ai(start) 同时使用")
+		}
+
+		if model.AI.AIStart > 1 {
+			w.WriteString(" AUTO_INCREMENT=").
+				WriteString(strconv.FormatInt(model.AI.AIStart, 10)).
+				WriteByte(' ')
+		}
+	} else if len(model.Meta["auto_increment"]) == 1 {
+		val := model.Meta["auto_increment"][0]
+		if n, err := strconv.ParseUint(val, 10, 64); err != nil || n == 0 {
+			return errors.New("无效的属性值 auto_increment")
+		}
+
+		w.WriteString(" AUTO_INCREMENT=")
+		w.WriteString(val)
 		w.WriteByte(' ')
-	} else if len(model.Meta["charset"]) > 0 {
-		return errors.New("无效的属性值 charset")
+	} else if len(model.Meta["auto_increment"]) > 0 {
+		return errors.New("无效的属性值 auto_increment")
 	}
 
 	return nil
 }
 
-func (m *mysql) createIndexSQL(w *sqlbuilder.SQLBuilder, model *model.Model) {
+func (m *mysql) createIndexSQL(w *sqlbuilder.SQLBuilder, model *model.Model) error {
 	for indexName, cols := range model.KeyIndexes {
+		collates := model.IndexCollates[indexName]
+		if len(collates) > 0 && !m.SupportsFeature(orm.FeatureIndexCollate, m.version) {
+			return fmt.Errorf("CreateTableSQL: mysql %s 不支持为索引 %s 指定排序规则", m.version, indexName)
+		}
+
 		// INDEX index_name (id,lastName)
+		// INDEX index_name ((name COLLATE utf8mb4_general_ci),id)
+		if hasGeometry(cols) {
+			w.WriteString(" SPATIAL")
+		}
 		w.WriteString(" INDEX ").
 			WriteString(indexName).
 			WriteByte('(')
 		for _, col := range cols {
-			w.WriteByte('{').WriteString(col.Name).WriteByte('}')
+			if _, found := collates[col]; found { // 函数索引需要额外的括号
+				w.WriteByte('(')
+				writeIndexColumn(w, col, collates)
+				w.WriteByte(')')
+			} else {
+				w.Quote(col.Name)
+			}
 			w.WriteByte(',')
 		}
 		w.TruncateLast(1) // 去掉最后一个逗号
 
 		w.WriteString("),")
 	}
+
+	return nil
+}
+
+func (m *mysql) LastInsertIDSupported() bool {
+	return true
+}
+
+// mysql 不支持 DEFAULT VALUES，没有可插入的列时，生成 INSERT INTO {#table}() VALUES()。
+func (m *mysql) InsertSQL(model *model.Model, includeAI bool) (string, []string) {
+	return insertSQL(model, 1, includeAI)
+}
+
+func (m *mysql) MultiInsertSQL(model *model.Model, rowCount int, includeAI bool) (string, []string, error) {
+	if rowCount <= 0 {
+		return "", nil, errors.New("MultiInsertSQL: rowCount 必须大于 0")
+	}
+
+	query, names := insertSQL(model, rowCount, includeAI)
+	return query, names, nil
+}
+
+// mysql 由所有的主键和唯一约束判断冲突，
+// 需要更新的列以 VALUES() 引用插入的值，8.0.20 之后虽然已不推荐，但依然可用。
+func (m *mysql) UpsertSQL(model *model.Model, conflictCols, updateCols []string) (string, []string, error) {
+	conflict, update, err := upsertColumns(model, conflictCols, updateCols)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, names := insertSQL(model, 1, hasAI(conflict))
+	buf := sqlbuilder.New(query).WriteString(" ON DUPLICATE KEY UPDATE ")
+	for _, col := range update {
+		buf.Quote(col.Name).WriteString("=VALUES(").Quote(col.Name).WriteString("),")
+	}
+	if occ, found := model.OCCColumn(); found {
+		buf.Quote(occ.Name).WriteByte('=').Quote(occ.Name).WriteString("+1,")
+	}
+	buf.TruncateLast(1)
+
+	return buf.String(), names, nil
+}
+
+func (m *mysql) UpdateSQL(model *model.Model, cols []string) (string, []string, error) {
+	return standardUpdateSQL(model, cols)
+}
+
+func (m *mysql) DeleteSQL(model *model.Model) (string, []string, error) {
+	return standardDeleteSQL(model)
+}
+
+func (m *mysql) CreateViewSQL(name, selectSQL string, orReplace bool) string {
+	return standardCreateViewSQL(name, selectSQL, orReplace)
+}
+
+func (m *mysql) AddConstraintSQL(table, name string, con *model.Constraint) (string, error) {
+	if con != nil && con.Type == model.ConstraintCheck && !m.SupportsFeature(orm.FeatureCheck, m.version) {
+		return "", fmt.Errorf("AddConstraintSQL: mysql %s 不支持 check 约束", m.version)
+	}
+
+	return standardAddConstraintSQL(table, name, con)
+}
+
+// mysql 中删除不同类型的约束，需要使用不同的语法。
+// 其中 DROP CHECK 需要 8.0.19 之后的版本才支持。
+func (m *mysql) DropConstraintSQL(table, name, typ string) (string, error) {
+	buf := sqlbuilder.New("ALTER TABLE ").Quote(table)
+
+	switch typ {
+	case model.ConstraintPK:
+		buf.WriteString(" DROP PRIMARY KEY")
+	case model.ConstraintUnique:
+		buf.WriteString(" DROP INDEX ").WriteString(name)
+	case model.ConstraintFK:
+		buf.WriteString(" DROP FOREIGN KEY ").WriteString(name)
+	case model.ConstraintCheck:
+		buf.WriteString(" DROP CHECK ").WriteString(name)
+	default:
+		return "", fmt.Errorf("DropConstraintSQL: 不支持的约束类型 %s", typ)
+	}
+
+	return buf.String(), nil
+}
+
+// 在 CreateTableSQL 中，索引是直接内嵌在 CREATE TABLE 语句中的，
+// 此函数用于对已经存在的表添加索引。
+//
+// 若索引列中包含空间数据类型，则会生成 SPATIAL INDEX。
+func (m *mysql) CreateIndexSQL(tableName, indexName string, cols []*model.Column, unique bool) string {
+	if !unique && hasGeometry(cols) {
+		query := standardCreateIndexSQL(tableName, indexName, cols, false)
+		return "CREATE SPATIAL " + strings.TrimPrefix(query, "CREATE ")
+	}
+
+	return standardCreateIndexSQL(tableName, indexName, cols, unique)
+}
+
+// mysql 的索引名仅在表中唯一，需要指定表名：
+//  DROP INDEX index_name ON {#table}
+func (m *mysql) DropIndexSQL(tableName, indexName string) string {
+	return sqlbuilder.New("DROP INDEX ").
+		WriteString(indexName).
+		WriteString(" ON ").
+		WriteString(tableName).
+		String()
+}
+
+// col 是否为某一索引的第一列，mysql 要求不作为主键的自增列必须满足此条件。
+//
+// 指定了 notpk 的列不能同时是主键，所以不需要判断主键。
+func isKeyPrefix(m *model.Model, col *model.Column) bool {
+	for _, indexes := range []map[string][]*model.Column{m.KeyIndexes, m.UniqueIndexes} {
+		for _, cols := range indexes {
+			if len(cols) > 0 && cols[0] == col {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cols 中是否包含空间数据类型的列
+func hasGeometry(cols []*model.Column) bool {
+	for _, col := range cols {
+		if col.Geometry != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mysql) SavepointSQL(name string) string {
+	return standardSavepointSQL(name)
+}
+
+func (m *mysql) ReleaseSavepointSQL(name string) string {
+	return standardReleaseSavepointSQL(name)
+}
+
+func (m *mysql) RollbackToSavepointSQL(name string) string {
+	return standardRollbackToSavepointSQL(name)
+}
+
+func (m *mysql) TypeForColumn(col *model.Column) (string, error) {
+	return typeForColumn(m, col)
 }
 
 func (m *mysql) LimitSQL(limit interface{}, offset ...interface{}) (string, []interface{}) {
-	return mysqlLimitSQL(limit, offset...)
+	// mysql 不支持单独的 OFFSET，官方文档建议以最大值代替 LIMIT 的值。
+	return mysqlLimitSQL("18446744073709551615", limit, offset...)
 }
 
+func (m *mysql) JSONContainsSQL(col string) (string, error) {
+	return "JSON_CONTAINS(" + col + ",?)", nil
+}
+
+// mysql 的 TRUNCATE TABLE 会删除并重建表，无论是 InnoDB 还是 MyISAM，
+// 都会将 AUTO_INCREMENT 重置为初始值，所以并不需要用到 ai 参数。
+//
+// 之所以不通过 ALTER TABLE ... AUTO_INCREMENT=1 显式重置，
+// 是因为 mysql 驱动默认不允许在一次 Exec 中执行多条语句。
 func (m *mysql) TruncateTableSQL(table, ai string) string {
-	return "TRUNCATE TABLE " + table
+	return quoteTableName(sqlbuilder.New("TRUNCATE TABLE "), table).String()
 }
 
 func (m *mysql) TransactionalDDL() bool {
@@ -142,67 +494,130 @@ func (m *mysql) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
 		return errors.New("sqlType:无效的col.GoType值")
 	}
 
+	if col.Generated != "" && !m.SupportsFeature(orm.FeatureGenerated, m.version) {
+		return errors.New("sqlType:当前版本不支持生成列")
+	}
+
+	if col.PGArray {
+		return errors.New("sqlType:不支持 pgarray")
+	}
+
+	if registeredType(m.Name(), buf, col) {
+		return nil
+	}
+
+	if col.Geometry != "" {
+		buf.WriteString(strings.ToUpper(col.Geometry))
+		return nil
+	}
+
+	if len(col.Set) > 0 {
+		buf.WriteString("SET(")
+		for _, v := range col.Set {
+			buf.WriteString(m.QuoteString(v)).WriteByte(',')
+		}
+		buf.TruncateLast(1).WriteByte(')')
+		return nil
+	}
+
+	if col.Serialize != "" {
+		if col.Serialize == "json" && col.GoType.Kind() == reflect.Map &&
+			m.SupportsFeature(orm.FeatureJSON, m.version) {
+			buf.WriteString("JSON")
+		} else {
+			buf.WriteString("LONGTEXT")
+		}
+		return nil
+	}
+
+	if err := checkColumnType(col, mysqlStringTypes, mysqlTimeTypes); err != nil {
+		return err
+	}
+
+	if col.Zerofill && col.Len1 <= 0 {
+		return errors.New("sqlType:zerofill 需要通过 len 指定宽度")
+	}
+
 	addIntLen := func() {
 		if col.Len1 > 0 {
 			buf.WriteByte('(').
-				WriteString(strconv.Itoa(col.Len1)).
+				WriteInt(col.Len1).
 				WriteByte(')')
 		}
 	}
 
+	// unsigned 表示 Go 中的类型本身是否为无符号整数，
+	// 有符号的整数，可以通过 unsigned 或是 zerofill 属性指定为无符号。
+	addUnsigned := func(unsigned bool) {
+		if unsigned || col.Unsigned || col.Zerofill {
+			buf.WriteString(" UNSIGNED")
+		}
+
+		if col.Zerofill {
+			buf.WriteString(" ZEROFILL")
+		}
+	}
+
+	addString := func() {
+		switch {
+		case col.Type != "":
+			buf.WriteString(strings.ToUpper(col.Type))
+		case col.Len1 == -1 || col.Len1 > 65533:
+			buf.WriteString("LONGTEXT")
+		default:
+			buf.WriteString("VARCHAR(").WriteInt(col.Len1).WriteByte(')')
+		}
+	}
+
 	switch col.GoType.Kind() {
 	case reflect.Bool:
 		buf.WriteString("BOOLEAN")
 	case reflect.Int8:
 		buf.WriteString("SMALLINT")
 		addIntLen()
+		addUnsigned(false)
 	case reflect.Int16:
 		buf.WriteString("MEDIUMINT")
 		addIntLen()
+		addUnsigned(false)
 	case reflect.Int32:
 		buf.WriteString("INT")
 		addIntLen()
+		addUnsigned(false)
 	case reflect.Int64, reflect.Int: // reflect.Int 大小未知，都当作是 BIGINT 处理
 		buf.WriteString("BIGINT")
 		addIntLen()
+		addUnsigned(false)
 	case reflect.Uint8:
 		buf.WriteString("SMALLINT")
 		addIntLen()
-		buf.WriteString(" UNSIGNED")
+		addUnsigned(true)
 	case reflect.Uint16:
 		buf.WriteString("MEDIUMINT")
 		addIntLen()
-		buf.WriteString(" UNSIGNED")
+		addUnsigned(true)
 	case reflect.Uint32:
 		buf.WriteString("INT")
 		addIntLen()
-		buf.WriteString(" UNSIGNED")
+		addUnsigned(true)
 	case reflect.Uint64, reflect.Uint, reflect.Uintptr:
 		buf.WriteString("BIGINT")
 		addIntLen()
-		buf.WriteString(" UNSIGNED")
+		addUnsigned(true)
 	case reflect.Float32, reflect.Float64:
 		if col.Len1 == 0 || col.Len2 == 0 {
 			return errors.New("请指定长度")
 		}
-		buf.WriteString(fmt.Sprintf("DOUBLE(%d,%d)", col.Len1, col.Len2))
+		buf.WriteString("DOUBLE(").WriteInt(col.Len1).WriteByte(',').WriteInt(col.Len2).WriteByte(')')
 	case reflect.String:
-		if col.Len1 == -1 || col.Len1 > 65533 {
-			buf.WriteString("LONGTEXT")
-		} else {
-			buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
-		}
+		addString()
 	case reflect.Slice, reflect.Array: // []rune,[]byte当作字符串处理
 		k := col.GoType.Elem().Kind()
 		if (k != reflect.Uint8) && (k != reflect.Int32) {
 			return fmt.Errorf("sqlType:不支持[%v]类型的数组", k)
 		}
 
-		if col.Len1 == -1 || col.Len1 > 65533 {
-			buf.WriteString("LONGTEXT")
-		} else {
-			buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
-		}
+		addString()
 	case reflect.Struct:
 		switch col.GoType {
 		case nullBool:
@@ -211,22 +626,39 @@ func (m *mysql) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
 			if col.Len1 == 0 || col.Len2 == 0 {
 				return errors.New("请指定长度")
 			}
-			buf.WriteString(fmt.Sprintf("DOUBLE(%d,%d)", col.Len1, col.Len2))
+			buf.WriteString("DOUBLE(").WriteInt(col.Len1).WriteByte(',').WriteInt(col.Len2).WriteByte(')')
 		case nullInt64:
 			buf.WriteString("BIGINT")
 			addIntLen()
+			addUnsigned(false)
 		case nullString:
-			if col.Len1 == -1 || col.Len1 > 65533 {
-				buf.WriteString("LONGTEXT")
+			addString()
+		case timeType:
+			if col.Type == "" {
+				buf.WriteString("DATETIME")
 			} else {
-				buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
+				buf.WriteString(strings.ToUpper(col.Type))
 			}
-		case timeType:
-			buf.WriteString("DATETIME")
+
+			if col.Type != "date" {
+				if err := timePrecision(buf, col); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
 		}
 	default:
 		return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
 	}
 
+	if col.Charset != "" {
+		buf.WriteString(" CHARACTER SET ").WriteString(col.Charset)
+	}
+
+	if col.Collate != "" {
+		buf.WriteString(" COLLATE ").WriteString(col.Collate)
+	}
+
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm/internal/modeltest"
@@ -87,4 +88,247 @@ func TestMysql_sqlType(t *testing.T) {
 	buf.Reset()
 	a.NotError(m.sqlType(buf, col))
 	sqltest.Equal(a, buf.String(), "BIGINT(5)")
+
+	// 未指定序列化方式的结构体
+	col.GoType = reflect.TypeOf(struct{ ID int }{})
+	buf.Reset()
+	a.Error(m.sqlType(buf, col))
+
+	col.Serialize = "gob"
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "LONGTEXT")
+
+	// map
+	col.GoType = reflect.TypeOf(map[string]string{})
+	col.Serialize = ""
+	buf.Reset()
+	a.Error(m.sqlType(buf, col))
+
+	col.Serialize = "json"
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "JSON")
+
+	col.Serialize = "gob"
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "LONGTEXT")
+}
+
+func TestMysql_QuoteString(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(m.QuoteString("abc"), "'abc'")
+	a.Equal(m.QuoteString("O'Brien"), "'O''Brien'")
+	a.Equal(m.QuoteString(`c:\dir`), `'c:\\dir'`)
+	a.Equal(m.QuoteString(`\'`), `'\\'''`)
+
+	// 默认值中的单引号
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		Name:       "name",
+		GoType:     reflect.TypeOf(""),
+		Len1:       20,
+		HasDefault: true,
+		Default:    "O'Brien",
+	}
+	a.NotError(createColSQL(m, buf, col))
+	sqltest.Equal(a, buf.String(), "{name} VARCHAR(20) NOT NULL DEFAULT 'O''Brien'")
+}
+
+func TestMysql_sqlType_charset(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		GoType:  reflect.TypeOf(""),
+		Len1:    50,
+		Charset: "utf8mb4",
+		Collate: "utf8mb4_bin",
+	}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "VARCHAR(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin")
+
+	col.Charset = ""
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "VARCHAR(50) COLLATE utf8mb4_bin")
+}
+
+func TestMysql_sqlType_type(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		GoType:  reflect.TypeOf(""),
+		Len1:    50,
+		Type:    "mediumtext",
+		Charset: "utf8mb4",
+	}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "MEDIUMTEXT CHARACTER SET utf8mb4")
+
+	col.GoType = reflect.TypeOf([]byte{})
+	col.Type = "text"
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT CHARACTER SET utf8mb4")
+
+	// 不支持的类型
+	col.Type = "blob"
+	buf.Reset()
+	a.Error(m.sqlType(buf, col))
+}
+
+func TestMysql_sqlType_set(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		GoType: reflect.TypeOf(""),
+		Len1:   20,
+		Set:    []string{"read", "write", "o'admin"},
+	}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "SET('read','write','o''admin')")
+}
+
+func TestMysql_sqlType_unsigned(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{GoType: reflect.TypeOf(1), Len1: 11, Unsigned: true}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BIGINT(11) UNSIGNED")
+
+	col.GoType = reflect.TypeOf(int8(1))
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "SMALLINT(11) UNSIGNED")
+
+	// 本身就是无符号类型，不会重复输出
+	col.GoType = reflect.TypeOf(uint32(1))
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "INT(11) UNSIGNED")
+}
+
+func TestMysql_sqlType_zerofill(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{GoType: reflect.TypeOf(int32(1)), Len1: 6, Zerofill: true}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "INT(6) UNSIGNED ZEROFILL")
+
+	// 本身就是无符号类型
+	col.GoType = reflect.TypeOf(uint64(1))
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BIGINT(6) UNSIGNED ZEROFILL")
+
+	// 未指定宽度
+	col.Len1 = 0
+	buf.Reset()
+	a.Error(m.sqlType(buf, col))
+
+	// 其它数据库不支持
+	col.Len1 = 6
+	a.Error((&postgres{}).sqlType(buf, col))
+	a.Error((&sqlite3{}).sqlType(buf, col))
+}
+
+func TestMysql_sqlType_time(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{GoType: reflect.TypeOf(time.Time{})}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "DATETIME")
+
+	col.Len1 = 6
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "DATETIME(6)")
+
+	col.Type = "timestamp"
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TIMESTAMP(6)")
+
+	// date 不需要精度
+	col.Type = "date"
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "DATE")
+
+	// 不支持的类型
+	col.Type = "year"
+	buf.Reset()
+	a.Error(m.sqlType(buf, col))
+
+	// 精度超出范围
+	col.Type = "time"
+	col.Len1 = 7
+	buf.Reset()
+	a.Error(m.sqlType(buf, col))
+}
+
+type aiStart struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (a *aiStart) Meta() string {
+	return "name(ai_start);auto_increment(1000)"
+}
+
+type aiStartInvalid struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (a *aiStartInvalid) Meta() string {
+	return "name(ai_start_invalid);auto_increment(-5)"
+}
+
+func TestMysql_CreateTableOptions_autoIncrement(t *testing.T) {
+	a := assert.New(t)
+	sql := sqlbuilder.New("")
+
+	mod, err := model.New(&aiStart{})
+	a.NotError(err).NotNil(mod)
+	a.NotError(m.createTableOptions(sql, mod))
+	sqltest.Equal(a, sql.String(), "AUTO_INCREMENT=1000")
+
+	sql.Reset()
+	mod, err = model.New(&aiStartInvalid{})
+	a.NotError(err).NotNil(mod)
+	a.Error(m.createTableOptions(sql, mod))
+}
+
+func TestMysql_geometry(t *testing.T) {
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{
+		Name:     "location",
+		GoType:   reflect.TypeOf([]byte{}),
+		Geometry: "polygon",
+	}
+
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "POLYGON")
+
+	query := m.CreateIndexSQL("tbl", "idx_location", []*model.Column{col}, false)
+	sqltest.Equal(a, query, "CREATE SPATIAL INDEX idx_location ON tbl({location})")
+
+	buf.Reset()
+	mod := &model.Model{KeyIndexes: map[string][]*model.Column{"idx_location": {col}}}
+	a.NotError(m.createIndexSQL(buf, mod))
+	sqltest.Equal(a, buf.String(), "SPATIAL INDEX idx_location({location}),")
+
+	// postgres 和 sqlite3 不支持
+	p := &postgres{}
+	a.Error(p.sqlType(buf, col))
+	s := &sqlite3{}
+	a.Error(s.sqlType(buf, col))
 }
@@ -18,7 +18,31 @@ import (
 
 var postgresInst *postgres
 
-type postgres struct{}
+func init() {
+	d := Postgres()
+	orm.RegisterDialect(d.Name(), d)
+}
+
+// 可以通过 type 属性指定的字符串类型
+var postgresStringTypes = []string{"text"}
+
+// 可以通过 type 属性指定的时间类型，timestamp 对应 TIMESTAMPTZ
+var postgresTimeTypes = []string{"timestamp", "date", "time"}
+
+// 各个特性最低支持的版本
+var postgresFeatures = map[string]string{
+	orm.FeatureJSON:      "9.4", // JSONB
+	orm.FeatureCheck:     "0",
+	orm.FeatureGenerated: "12",
+	orm.FeatureUpsert:    "9.5",
+	orm.FeatureReturning: "8.2",
+
+	orm.FeatureIndexCollate: "0",
+}
+
+type postgres struct {
+	version string
+}
 
 // Postgres 返回一个适配 postgresql 的 Dialect 接口
 func Postgres() orm.Dialect {
@@ -29,10 +53,38 @@ func Postgres() orm.Dialect {
 	return postgresInst
 }
 
+// PostgresVersion 返回一个适配指定版本 postgresql 的 Dialect 接口
+//
+// version 为空时与 Postgres() 的返回值相同。
+func PostgresVersion(version string) orm.Dialect {
+	if version == "" {
+		return Postgres()
+	}
+
+	return &postgres{version: version}
+}
+
+func (p *postgres) Version() string {
+	return p.version
+}
+
+func (p *postgres) SupportsFeature(feature, version string) bool {
+	return supportsFeature(postgresFeatures, feature, version)
+}
+
+func (p *postgres) Name() string {
+	return "postgres"
+}
+
 func (p *postgres) QuoteTuple() (byte, byte) {
 	return '"', '"'
 }
 
+func (p *postgres) QuoteIdentifier(name string) string {
+	l, r := p.QuoteTuple()
+	return quoteIdentifier(name, l, r)
+}
+
 // 在有 ? 占位符的情况下，语句中不能包含$字符串
 func (p *postgres) SQL(sql string) (string, error) {
 	if strings.IndexByte(sql, '?') < 0 {
@@ -57,22 +109,53 @@ func (p *postgres) SQL(sql string) (string, error) {
 	return string(ret), nil
 }
 
-func (p *postgres) CreateTableSQL(model *model.Model) ([]string, error) {
-	w := sqlbuilder.New("CREATE TABLE IF NOT EXISTS ").
-		WriteString("{#").
-		WriteString(model.Name).
-		WriteString("}(")
+func (p *postgres) Placeholder(index int) string {
+	return sqlbuilder.New("$").WriteInt(index).String()
+}
+
+// postgresql 默认启用了 standard_conforming_strings，反斜杠不作为转义字符。
+func (p *postgres) QuoteString(s string) string {
+	return quoteString(s, false)
+}
+
+func (p *postgres) FormatValue(v interface{}) (string, error) {
+	return formatValue(v, false, "TRUE", "FALSE")
+}
+
+func (p *postgres) ExistsTableSQL(schema, tableName string) (string, []interface{}) {
+	buf := sqlbuilder.New("SELECT COUNT(*) FROM pg_tables WHERE schemaname=")
+	if schema == "" {
+		buf.WriteString("CURRENT_SCHEMA()")
+	} else {
+		buf.WriteString(p.QuoteString(schema))
+	}
+
+	return buf.WriteString(" AND tablename=").
+		WriteString(p.QuoteString(tableName)).
+		String(), nil
+}
+
+func (p *postgres) NextAIValueSQL(schema, tableName string) (string, []interface{}, error) {
+	return "", nil, sqlbuilder.ErrNotSupported
+}
+
+func (p *postgres) CreateTableSQL(model *model.Model, ifNotExists bool) ([]string, error) {
+	if model.Partition != nil {
+		return nil, fmt.Errorf("CreateTableSQL: %s 不支持表分区", p.Name())
+	}
+
+	w := createTableSQL(model, ifNotExists)
 
 	// 自增和普通列输出是相同的，自增列仅是类型名不相同
-	for _, col := range model.Cols {
+	for _, col := range model.ColsOrder {
 		if err := createColSQL(p, w, col); err != nil {
-			return nil, err
+			return nil, columnError(model, col, err)
 		}
 		w.WriteByte(',')
 	}
 
 	if len(model.PK) > 0 {
-		createPKSQL(w, model.PK, model.Name+pkName) // postgres 主键名需要全局唯一？
+		createPKSQL(w, model.PK, model.PKName()) // postgres 的约束名在 schema 中需要唯一
 		w.WriteByte(',')
 	}
 	createConstraints(w, model)
@@ -80,19 +163,118 @@ func (p *postgres) CreateTableSQL(model *model.Model) ([]string, error) {
 
 	// TODO meta
 
-	indexs, err := createIndexSQL(model)
+	indexs, err := createIndexSQL(p, model)
 	if err != nil {
 		return nil, err
 	}
 	return append([]string{w.String()}, indexs...), nil
 }
 
+// postgres 的驱动不支持 LastInsertId()，需要通过 RETURNING 获取自增列的值。
+func (p *postgres) LastInsertIDSupported() bool {
+	return false
+}
+
+func (p *postgres) InsertSQL(model *model.Model, includeAI bool) (string, []string) {
+	query, names := standardInsertSQL(model, includeAI)
+	if includeAI || !model.HasAutoIncrement() {
+		return query, names
+	}
+
+	return sqlbuilder.New(query).
+		WriteString(" RETURNING ").
+		Quote(model.AI.Name).
+		String(), names
+}
+
+func (p *postgres) MultiInsertSQL(model *model.Model, rowCount int, includeAI bool) (string, []string, error) {
+	return standardMultiInsertSQL(model, rowCount, includeAI)
+}
+
+func (p *postgres) UpsertSQL(model *model.Model, conflictCols, updateCols []string) (string, []string, error) {
+	if !p.SupportsFeature(orm.FeatureUpsert, p.version) {
+		return "", nil, sqlbuilder.ErrNotSupported
+	}
+
+	return standardUpsertSQL(model, conflictCols, updateCols)
+}
+
+func (p *postgres) UpdateSQL(model *model.Model, cols []string) (string, []string, error) {
+	return standardUpdateSQL(model, cols)
+}
+
+func (p *postgres) DeleteSQL(model *model.Model) (string, []string, error) {
+	return standardDeleteSQL(model)
+}
+
+func (p *postgres) CreateViewSQL(name, selectSQL string, orReplace bool) string {
+	return standardCreateViewSQL(name, selectSQL, orReplace)
+}
+
+func (p *postgres) AddConstraintSQL(table, name string, con *model.Constraint) (string, error) {
+	return standardAddConstraintSQL(table, name, con)
+}
+
+func (p *postgres) DropConstraintSQL(table, name, typ string) (string, error) {
+	switch typ {
+	case model.ConstraintPK, model.ConstraintUnique, model.ConstraintFK, model.ConstraintCheck:
+	default:
+		return "", fmt.Errorf("DropConstraintSQL: 不支持的约束类型 %s", typ)
+	}
+
+	return sqlbuilder.New("ALTER TABLE ").
+		Quote(table).
+		WriteString(" DROP CONSTRAINT ").
+		WriteString(name).
+		String(), nil
+}
+
+func (p *postgres) CreateIndexSQL(tableName, indexName string, cols []*model.Column, unique bool) string {
+	return standardCreateIndexSQL(tableName, indexName, cols, unique)
+}
+
+// postgres 的索引名在同一 schema 中是唯一的，不需要指定表名，
+// 但是需要通过表名中的 schema 确定索引所在的 schema。
+func (p *postgres) DropIndexSQL(tableName, indexName string) string {
+	if schema := indexSchema(tableName); schema != "" {
+		return "DROP INDEX " + schema + "." + indexName
+	}
+	return "DROP INDEX " + indexName
+}
+
+func (p *postgres) SavepointSQL(name string) string {
+	return standardSavepointSQL(name)
+}
+
+func (p *postgres) ReleaseSavepointSQL(name string) string {
+	return standardReleaseSavepointSQL(name)
+}
+
+func (p *postgres) RollbackToSavepointSQL(name string) string {
+	return standardRollbackToSavepointSQL(name)
+}
+
+// postgres 以 CHECK 约束代替无符号整数，该约束不属于类型的一部分。
+func (p *postgres) TypeForColumn(col *model.Column) (string, error) {
+	if col != nil && col.Unsigned {
+		c := *col
+		c.Unsigned = false
+		col = &c
+	}
+	return typeForColumn(p, col)
+}
+
 func (p *postgres) LimitSQL(limit interface{}, offset ...interface{}) (string, []interface{}) {
-	return mysqlLimitSQL(limit, offset...)
+	return mysqlLimitSQL("", limit, offset...)
+}
+
+// 仅支持 jsonb 类型的列
+func (p *postgres) JSONContainsSQL(col string) (string, error) {
+	return col + " @> ?", nil
 }
 
 func (p *postgres) TruncateTableSQL(table, ai string) string {
-	w := sqlbuilder.New("TRUNCATE TABLE ").WriteString(table)
+	w := quoteTableName(sqlbuilder.New("TRUNCATE TABLE "), table)
 
 	if ai != "" {
 		w.WriteString(" RESTART IDENTITY")
@@ -105,6 +287,47 @@ func (p *postgres) TransactionalDDL() bool {
 	return true
 }
 
+// 切片元素的类型与 postgres 中数组元素类型的对应关系，
+// int32 和 uint8 组成的切片被当作字符串处理，不会出现在此处。
+var postgresArrayTypes = map[reflect.Kind]string{
+	reflect.Bool:    "BOOLEAN",
+	reflect.Int8:    "SMALLINT",
+	reflect.Int16:   "SMALLINT",
+	reflect.Uint16:  "SMALLINT",
+	reflect.Uint32:  "INT",
+	reflect.Int:     "BIGINT",
+	reflect.Int64:   "BIGINT",
+	reflect.Uint:    "BIGINT",
+	reflect.Uint64:  "BIGINT",
+	reflect.Float32: "REAL",
+	reflect.Float64: "DOUBLE PRECISION",
+	reflect.String:  "TEXT",
+}
+
+// 将通过 pgarray 指定的切片转换成数组类型，比如 []int64 对应 BIGINT[]。
+//
+// 时间类型对应 TIMESTAMPTZ[]。
+func pgArrayType(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
+	if col.Serialize != "" {
+		return errors.New("sqlType:pgarray 不能与 serialize 同时使用")
+	}
+
+	elem := col.GoType.Elem()
+	switch {
+	case elem == timeType:
+		buf.WriteString("TIMESTAMPTZ")
+	default:
+		typ, found := postgresArrayTypes[elem.Kind()]
+		if !found {
+			return fmt.Errorf("sqlType:不支持的数组元素类型:[%v]", elem)
+		}
+		buf.WriteString(typ)
+	}
+
+	buf.WriteString("[]")
+	return nil
+}
+
 // implement base.sqlType
 // 将col转换成sql类型，并写入buf中。
 func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
@@ -116,24 +339,99 @@ func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error
 		return errors.New("sqlType:无效的col.GoType值")
 	}
 
+	if col.Generated != "" {
+		if !p.SupportsFeature(orm.FeatureGenerated, p.version) {
+			return errors.New("sqlType:当前版本不支持生成列")
+		}
+
+		if !col.GeneratedStored {
+			return errors.New("sqlType:生成列只支持 stored")
+		}
+	}
+
+	if col.AutoRandom {
+		return errors.New("sqlType:不支持 autorandom")
+	}
+
+	if col.Zerofill {
+		return errors.New("sqlType:不支持 zerofill")
+	}
+
+	if registeredType(p.Name(), buf, col) {
+		return nil
+	}
+
+	if col.Geometry != "" {
+		return errors.New("sqlType:不支持空间数据类型")
+	}
+
+	if col.PGArray {
+		return pgArrayType(buf, col)
+	}
+
+	if len(col.Set) > 0 { // 不支持 SET 类型，以逗号分隔的文本保存
+		buf.WriteString("TEXT")
+		return nil
+	}
+
+	if col.Serialize != "" {
+		if col.Serialize == "json" && col.GoType.Kind() == reflect.Map &&
+			p.SupportsFeature(orm.FeatureJSON, p.version) {
+			buf.WriteString("JSONB")
+		} else {
+			buf.WriteString("TEXT")
+		}
+		return nil
+	}
+
+	if err := checkColumnType(col, postgresStringTypes, postgresTimeTypes); err != nil {
+		return err
+	}
+
+	addString := func() {
+		switch {
+		case col.Type != "":
+			buf.WriteString(strings.ToUpper(col.Type))
+		case col.Len1 == -1 || col.Len1 > 65533:
+			buf.WriteString("TEXT")
+		default:
+			buf.WriteString("VARCHAR(").WriteInt(col.Len1).WriteByte(')')
+		}
+	}
+
+	// 自增列，指定了起始值或是步长时，使用 IDENTITY 代替 SERIAL
+	addAI := func(serial, typ string) {
+		if !hasAIOptions(col) {
+			buf.WriteString(serial)
+			return
+		}
+
+		buf.WriteString(typ).
+			WriteString(" GENERATED BY DEFAULT AS IDENTITY (START WITH ").
+			WriteString(strconv.FormatInt(col.AIStart, 10)).
+			WriteString(" INCREMENT BY ").
+			WriteString(strconv.FormatInt(col.AIStep, 10)).
+			WriteByte(')')
+	}
+
 	switch col.GoType.Kind() {
 	case reflect.Bool:
 		buf.WriteString("BOOLEAN")
 	case reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16:
 		if col.IsAI() {
-			buf.WriteString("SERIAL")
+			addAI("SERIAL", "SMALLINT")
 		} else {
 			buf.WriteString("SMALLINT")
 		}
 	case reflect.Int32, reflect.Uint32:
 		if col.IsAI() {
-			buf.WriteString("SERIAL")
+			addAI("SERIAL", "INT")
 		} else {
 			buf.WriteString("INT")
 		}
 	case reflect.Int64, reflect.Int, reflect.Uint64, reflect.Uint:
 		if col.IsAI() {
-			buf.WriteString("BIGSERIAL")
+			addAI("BIGSERIAL", "BIGINT")
 		} else {
 			buf.WriteString("BIGINT")
 		}
@@ -141,24 +439,16 @@ func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error
 		if col.Len1 == 0 || col.Len2 == 0 {
 			return errors.New("请指定长度")
 		}
-		buf.WriteString(fmt.Sprintf("DOUBLE(%d,%d)", col.Len1, col.Len2))
+		buf.WriteString("DOUBLE(").WriteInt(col.Len1).WriteByte(',').WriteInt(col.Len2).WriteByte(')')
 	case reflect.String:
-		if col.Len1 == -1 || col.Len1 > 65533 {
-			buf.WriteString("TEXT")
-		} else {
-			buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
-		}
+		addString()
 	case reflect.Slice, reflect.Array: // []rune,[]byte当作字符串处理
 		k := col.GoType.Elem().Kind()
 		if (k != reflect.Uint8) && (k != reflect.Int32) {
 			return errors.New("sqlType:不支持数组类型")
 		}
 
-		if col.Len1 == -1 || col.Len1 > 65533 {
-			buf.WriteString("TEXT")
-		} else {
-			buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
-		}
+		addString()
 	case reflect.Struct:
 		switch col.GoType {
 		case nullBool:
@@ -167,7 +457,7 @@ func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error
 			if col.Len1 == 0 || col.Len2 == 0 {
 				return errors.New("请指定长度")
 			}
-			buf.WriteString(fmt.Sprintf("DOUBLE(%d,%d)", col.Len1, col.Len2))
+			buf.WriteString("DOUBLE(").WriteInt(col.Len1).WriteByte(',').WriteInt(col.Len2).WriteByte(')')
 		case nullInt64:
 			if col.IsAI() {
 				buf.WriteString("BIGSERIAL")
@@ -175,17 +465,38 @@ func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error
 				buf.WriteString("BIGINT")
 			}
 		case nullString:
-			if col.Len1 == -1 || col.Len1 > 65533 {
-				buf.WriteString("TEXT")
-			} else {
-				buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
-			}
+			addString()
 		case timeType:
-			buf.WriteString("TIME")
+			switch col.Type {
+			case "date":
+				buf.WriteString("DATE")
+			case "timestamp":
+				buf.WriteString("TIMESTAMPTZ")
+				if err := timePrecision(buf, col); err != nil {
+					return err
+				}
+			case "time":
+				buf.WriteString("TIME")
+				if err := timePrecision(buf, col); err != nil {
+					return err
+				}
+			default: // 与 mysql 和 sqlite3 的 DATETIME 相对应，同时包含日期和时间
+				buf.WriteString("TIMESTAMP")
+				if err := timePrecision(buf, col); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
 		}
 	default:
 		return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
 	}
 
+	// postgres 不支持无符号整数，以 CHECK 约束代替
+	if col.Unsigned {
+		buf.WriteString(" CHECK(").Quote(col.Name).WriteString(">=0)")
+	}
+
 	return nil
 }
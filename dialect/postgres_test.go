@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm/internal/sqltest"
@@ -57,6 +58,144 @@ func TestPostgres_sqlType(t *testing.T) {
 	sqltest.Equal(a, buf.String(), "BIGINT")
 }
 
+type pgArray struct {
+	ID     int64       `orm:"name(id);ai"`
+	Tags   []string    `orm:"name(tags);pgarray"`
+	Scores []float64   `orm:"name(scores);pgarray"`
+	Flags  []bool      `orm:"name(flags);pgarray"`
+	Times  []time.Time `orm:"name(times);pgarray"`
+}
+
+func (o *pgArray) Meta() string {
+	return "name(pg_array)"
+}
+
+func TestPostgres_sqlType_pgarray(t *testing.T) {
+	p := &postgres{}
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+
+	data := []*struct {
+		v   interface{}
+		typ string
+	}{
+		{v: []bool{}, typ: "BOOLEAN[]"},
+		{v: []int8{}, typ: "SMALLINT[]"},
+		{v: []int16{}, typ: "SMALLINT[]"},
+		{v: []uint16{}, typ: "SMALLINT[]"},
+		{v: []uint32{}, typ: "INT[]"},
+		{v: []int{}, typ: "BIGINT[]"},
+		{v: []int64{}, typ: "BIGINT[]"},
+		{v: []uint{}, typ: "BIGINT[]"},
+		{v: []uint64{}, typ: "BIGINT[]"},
+		{v: []float32{}, typ: "REAL[]"},
+		{v: []float64{}, typ: "DOUBLE PRECISION[]"},
+		{v: []string{}, typ: "TEXT[]"},
+		{v: []time.Time{}, typ: "TIMESTAMPTZ[]"},
+	}
+	for _, item := range data {
+		buf.Reset()
+		col := &model.Column{Name: "arr", GoType: reflect.TypeOf(item.v), PGArray: true}
+		a.NotError(p.sqlType(buf, col))
+		a.Equal(buf.String(), item.typ)
+	}
+
+	// 不能与 serialize 同时使用
+	col := &model.Column{Name: "arr", GoType: reflect.TypeOf([]int64{}), PGArray: true, Serialize: "json"}
+	a.Error(p.sqlType(buf, col))
+
+	mod, err := model.New(&pgArray{})
+	a.NotError(err).NotNil(mod)
+	sqls, err := p.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#pg_array}({id} BIGSERIAL NOT NULL,"+
+		"{tags} TEXT[] NOT NULL,{scores} DOUBLE PRECISION[] NOT NULL,"+
+		"{flags} BOOLEAN[] NOT NULL,{times} TIMESTAMPTZ[] NOT NULL,"+
+		"CONSTRAINT pg_arraypk PRIMARY KEY({id}))")
+
+	// mysql 和 sqlite3 不支持
+	_, err = (&mysql{}).CreateTableSQL(mod, true)
+	a.Error(err)
+	_, err = (&sqlite3{}).CreateTableSQL(mod, true)
+	a.Error(err)
+}
+
+func TestPostgres_sqlType_unsigned(t *testing.T) {
+	p := &postgres{}
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{Name: "age", GoType: reflect.TypeOf(1), Unsigned: true}
+
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BIGINT CHECK({age}>=0)")
+}
+
+func TestPostgres_sqlType_serialize(t *testing.T) {
+	p := &postgres{}
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{GoType: reflect.TypeOf(map[string]string{})}
+
+	a.Error(p.sqlType(buf, col))
+
+	col.Serialize = "json"
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "JSONB")
+
+	col.GoType = reflect.TypeOf(struct{ ID int }{})
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT")
+}
+
+func TestPostgres_sqlType_set(t *testing.T) {
+	p := &postgres{}
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{GoType: reflect.TypeOf(""), Len1: 20, Set: []string{"read", "write"}}
+
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT")
+}
+
+func TestPostgres_sqlType_time(t *testing.T) {
+	p := &postgres{}
+	a := assert.New(t)
+	buf := sqlbuilder.New("")
+	col := &model.Column{GoType: reflect.TypeOf(time.Time{})}
+
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TIMESTAMP")
+
+	// 未指定 type 时，len 依然有效
+	col.Len1 = 6
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TIMESTAMP(6)")
+
+	col.Type = "time"
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TIME(6)")
+
+	col.Type = "timestamp"
+	col.Len1 = 3
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TIMESTAMPTZ(3)")
+
+	col.Type = "date"
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "DATE")
+
+	// 不支持的类型
+	col.Type = "datetime"
+	buf.Reset()
+	a.Error(p.sqlType(buf, col))
+}
+
 func TestPostgres_SQL(t *testing.T) {
 	a := assert.New(t)
 	p := Postgres()
@@ -97,3 +236,13 @@ func BenchmarkPostgres_SQL(b *testing.B) {
 		p.SQL(s1)
 	}
 }
+
+func TestPostgres_QuoteString(t *testing.T) {
+	a := assert.New(t)
+	p := Postgres()
+
+	a.Equal(p.QuoteString("abc"), "'abc'")
+	a.Equal(p.QuoteString("O'Brien"), "'O''Brien'")
+	a.Equal(p.QuoteString(`c:\dir`), `'c:\dir'`)
+	a.Equal(p.QuoteString(`\'`), `'\'''`)
+}
@@ -6,8 +6,10 @@ package dialect
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/model"
@@ -16,12 +18,36 @@ import (
 
 var sqlite3Inst *sqlite3
 
-type sqlite3 struct{}
+func init() {
+	d := Sqlite3()
+	orm.RegisterDialect(d.Name(), d)
+}
+
+// 可以通过 type 属性指定的字符串类型
+var sqlite3StringTypes = []string{"text"}
+
+// 可以通过 type 属性指定的时间类型
+var sqlite3TimeTypes = []string{"datetime", "timestamp", "date", "time"}
+
+// 各个特性最低支持的版本，sqlite3 中的 JSON 以 TEXT 保存，所以不需要判断。
+var sqlite3Features = map[string]string{
+	orm.FeatureCheck:     "0",
+	orm.FeatureGenerated: "3.31.0",
+	orm.FeatureUpsert:    "3.24.0",
+	orm.FeatureReturning: "3.35.0",
+
+	orm.FeatureIndexCollate: "0",
+}
+
+type sqlite3 struct {
+	version string
+}
 
 // Sqlite3 返回一个适配 sqlite3 的 orm.Dialect 接口
 //
 // Meta 可以接受以下参数：
 //  rowid 可以是 rowid(false);rowid(true),rowid，其中只有 rowid(false) 等同于 without rowid
+//  without rowid 的表必须指定主键，且不能包含自增列。
 func Sqlite3() orm.Dialect {
 	if sqlite3Inst == nil {
 		sqlite3Inst = &sqlite3{}
@@ -30,36 +56,97 @@ func Sqlite3() orm.Dialect {
 	return sqlite3Inst
 }
 
+// Sqlite3Version 返回一个适配指定版本 sqlite3 的 Dialect 接口
+//
+// version 为空时与 Sqlite3() 的返回值相同。
+func Sqlite3Version(version string) orm.Dialect {
+	if version == "" {
+		return Sqlite3()
+	}
+
+	return &sqlite3{version: version}
+}
+
+func (s *sqlite3) Version() string {
+	return s.version
+}
+
+func (s *sqlite3) SupportsFeature(feature, version string) bool {
+	return supportsFeature(sqlite3Features, feature, version)
+}
+
+func (s *sqlite3) Name() string {
+	return "sqlite3"
+}
+
 func (s *sqlite3) QuoteTuple() (byte, byte) {
 	return '`', '`'
 }
 
+func (s *sqlite3) QuoteIdentifier(name string) string {
+	l, r := s.QuoteTuple()
+	return quoteIdentifier(name, l, r)
+}
+
 func (s *sqlite3) SQL(sql string) (string, error) {
 	return sql, nil
 }
 
-func (s *sqlite3) CreateTableSQL(model *model.Model) ([]string, error) {
-	w := sqlbuilder.New("CREATE TABLE IF NOT EXISTS ").
-		WriteString("{#").
-		WriteString(model.Name).
-		WriteString("}(")
+func (s *sqlite3) Placeholder(index int) string {
+	return "?"
+}
+
+func (s *sqlite3) QuoteString(str string) string {
+	return quoteString(str, false)
+}
+
+func (s *sqlite3) FormatValue(v interface{}) (string, error) {
+	return formatValue(v, false, "1", "0")
+}
+
+// sqlite3 中的 schema 为附加的数据库名，每个数据库都有各自的 sqlite_master 表。
+func (s *sqlite3) ExistsTableSQL(schema, tableName string) (string, []interface{}) {
+	buf := sqlbuilder.New("SELECT COUNT(*) FROM ")
+	if schema != "" {
+		buf.Quote(schema).WriteByte('.')
+	}
+
+	return buf.WriteString("sqlite_master WHERE type='table' AND name=").
+		WriteString(s.QuoteString(tableName)).
+		String(), nil
+}
+
+func (s *sqlite3) NextAIValueSQL(schema, tableName string) (string, []interface{}, error) {
+	return "", nil, sqlbuilder.ErrNotSupported
+}
+
+func (s *sqlite3) CreateTableSQL(model *model.Model, ifNotExists bool) ([]string, error) {
+	if model.Partition != nil {
+		return nil, fmt.Errorf("CreateTableSQL: %s 不支持表分区", s.Name())
+	}
+
+	w := createTableSQL(model, ifNotExists)
 
 	// 自增列
-	if model.AI != nil {
+	if model.HasAutoIncrement() {
+		if model.AI.AINotPK {
+			return nil, errors.New("CreateTableSQL: sqlite3 的自增列只能是主键")
+		}
+
 		if err := createColSQL(s, w, model.AI); err != nil {
-			return nil, err
+			return nil, columnError(model, model.AI, err)
 		}
 		w.WriteString(" PRIMARY KEY AUTOINCREMENT,")
 	}
 
 	// 普通列
-	for _, col := range model.Cols {
+	for _, col := range model.ColsOrder {
 		if col.IsAI() { // 忽略 AI 列
 			continue
 		}
 
 		if err := createColSQL(s, w, col); err != nil {
-			return nil, err
+			return nil, columnError(model, col, err)
 		}
 		w.WriteByte(',')
 	}
@@ -76,11 +163,53 @@ func (s *sqlite3) CreateTableSQL(model *model.Model) ([]string, error) {
 		return nil, err
 	}
 
-	indexs, err := createIndexSQL(model)
+	sqls := []string{w.String()}
+	if hasAIOptions(model.AI) {
+		seq, err := s.aiStartSQL(model)
+		if err != nil {
+			return nil, err
+		}
+		if seq != "" {
+			sqls = append(sqls, seq)
+		}
+	}
+
+	indexs, err := createIndexSQL(s, model)
 	if err != nil {
 		return nil, err
 	}
-	return append([]string{w.String()}, indexs...), nil
+	return append(sqls, indexs...), nil
+}
+
+// sqlite3 不能指定自增列的步长，起始值则通过向 SQLITE_SEQUENCE 写入初始记录实现。
+func (s *sqlite3) aiStartSQL(model *model.Model) (string, error) {
+	if model.AI.AIStep > 1 {
+		return "", columnError(model, model.AI, errors.New("不支持指定自增列的步长"))
+	}
+
+	if model.AI.AIStart <= 1 {
+		return "", nil
+	}
+
+	// 指定了 schema 的表，其 SQLITE_SEQUENCE 位于对应的数据库中
+	seq := "SQLITE_SEQUENCE"
+	if model.Schema != "" {
+		seq = "{" + model.Schema + "}." + seq
+	}
+
+	name := s.QuoteString("#" + model.Name)
+	return sqlbuilder.New("INSERT INTO ").
+		WriteString(seq).
+		WriteString("(name,seq) SELECT ").
+		WriteString(name).
+		WriteByte(',').
+		WriteString(strconv.FormatInt(model.AI.AIStart-1, 10)).
+		WriteString(" WHERE NOT EXISTS(SELECT 1 FROM ").
+		WriteString(seq).
+		WriteString(" WHERE name=").
+		WriteString(name).
+		WriteByte(')').
+		String(), nil
 }
 
 func (s *sqlite3) createTableOptions(w *sqlbuilder.SQLBuilder, model *model.Model) error {
@@ -91,7 +220,14 @@ func (s *sqlite3) createTableOptions(w *sqlbuilder.SQLBuilder, model *model.Mode
 		}
 
 		if !val {
-			w.WriteString("WITHOUT ROWID")
+			// WITHOUT ROWID 的表必须有主键，且不能使用 AUTOINCREMENT
+			if len(model.PK) == 0 {
+				return errors.New("without rowid 的表必须指定主键")
+			}
+			if model.HasAutoIncrement() {
+				return errors.New("without rowid 的表不能包含自增列")
+			}
+			w.WriteString(" WITHOUT ROWID")
 		}
 	} else if len(model.Meta["rowid"]) > 0 {
 		return errors.New("rowid 只接受一个参数")
@@ -100,17 +236,117 @@ func (s *sqlite3) createTableOptions(w *sqlbuilder.SQLBuilder, model *model.Mode
 	return nil
 }
 
+func (s *sqlite3) LastInsertIDSupported() bool {
+	return true
+}
+
+func (s *sqlite3) InsertSQL(model *model.Model, includeAI bool) (string, []string) {
+	return standardInsertSQL(model, includeAI)
+}
+
+func (s *sqlite3) MultiInsertSQL(model *model.Model, rowCount int, includeAI bool) (string, []string, error) {
+	return standardMultiInsertSQL(model, rowCount, includeAI)
+}
+
+func (s *sqlite3) UpsertSQL(model *model.Model, conflictCols, updateCols []string) (string, []string, error) {
+	if !s.SupportsFeature(orm.FeatureUpsert, s.version) {
+		return "", nil, sqlbuilder.ErrNotSupported
+	}
+
+	return standardUpsertSQL(model, conflictCols, updateCols)
+}
+
+func (s *sqlite3) UpdateSQL(model *model.Model, cols []string) (string, []string, error) {
+	return standardUpdateSQL(model, cols)
+}
+
+func (s *sqlite3) DeleteSQL(model *model.Model) (string, []string, error) {
+	return standardDeleteSQL(model)
+}
+
+// sqlite3 不支持 CREATE OR REPLACE VIEW，只能先删除再创建。
+func (s *sqlite3) CreateViewSQL(name, selectSQL string, orReplace bool) string {
+	buf := sqlbuilder.New("")
+	if orReplace {
+		buf.WriteString("DROP VIEW IF EXISTS ").Quote("#" + name).WriteByte(';')
+	}
+
+	return buf.WriteString("CREATE VIEW ").
+		Quote("#" + name).
+		WriteString(" AS ").
+		WriteString(selectSQL).
+		String()
+}
+
+// sqlite3 的 ALTER TABLE 不支持添加和删除约束，只能通过重建表的方式实现。
+func (s *sqlite3) AddConstraintSQL(table, name string, con *model.Constraint) (string, error) {
+	return "", sqlbuilder.ErrNotSupported
+}
+
+func (s *sqlite3) DropConstraintSQL(table, name, typ string) (string, error) {
+	return "", sqlbuilder.ErrNotSupported
+}
+
+// sqlite3 不允许在 ON 之后的表名中指定 schema，
+// 需要以 CREATE INDEX {schema}.index ON {#tbl} 的形式将 schema 指定在索引名上。
+func (s *sqlite3) CreateIndexSQL(tableName, indexName string, cols []*model.Column, unique bool) string {
+	if schema := indexSchema(tableName); schema != "" {
+		return standardCreateIndexSQL(tableName[len(schema)+1:], schema+"."+indexName, cols, unique)
+	}
+	return standardCreateIndexSQL(tableName, indexName, cols, unique)
+}
+
+// sqlite3 的索引名在整个数据库中是唯一的，不需要指定表名，
+// 但是需要通过表名中的 schema 确定索引所在的数据库。
+func (s *sqlite3) DropIndexSQL(tableName, indexName string) string {
+	if schema := indexSchema(tableName); schema != "" {
+		return "DROP INDEX IF EXISTS " + schema + "." + indexName
+	}
+	return "DROP INDEX IF EXISTS " + indexName
+}
+
+func (s *sqlite3) SavepointSQL(name string) string {
+	return standardSavepointSQL(name)
+}
+
+func (s *sqlite3) ReleaseSavepointSQL(name string) string {
+	return standardReleaseSavepointSQL(name)
+}
+
+func (s *sqlite3) RollbackToSavepointSQL(name string) string {
+	return standardRollbackToSavepointSQL(name)
+}
+
+func (s *sqlite3) TypeForColumn(col *model.Column) (string, error) {
+	return typeForColumn(s, col)
+}
+
 func (s *sqlite3) LimitSQL(limit interface{}, offset ...interface{}) (string, []interface{}) {
-	return mysqlLimitSQL(limit, offset...)
+	// sqlite3 不支持单独的 OFFSET，LIMIT 为负数表示不限制数量。
+	return mysqlLimitSQL("-1", limit, offset...)
 }
 
+func (s *sqlite3) JSONContainsSQL(col string) (string, error) {
+	return "", sqlbuilder.ErrNotSupported
+}
+
+// sqlite3 中的自增列记录在 SQLITE_SEQUENCE 表中，
+// 仅在指定了 ai 时才需要重置，且不存在自增列时，该表可能并不存在。
+//
+// 指定了 schema 的表，其 SQLITE_SEQUENCE 位于对应的数据库中。
 func (s *sqlite3) TruncateTableSQL(table, ai string) string {
-	return sqlbuilder.New("DELETE FROM ").
-		WriteString(table).
-		WriteString(";DELETE FROM SQLITE_SEQUENCE WHERE name='").
-		WriteString(table).
-		WriteString("';").
-		String()
+	buf := quoteTableName(sqlbuilder.New("DELETE FROM "), table)
+
+	if ai != "" {
+		schema, name := splitTableName(table)
+		buf.WriteString(";DELETE FROM ")
+		if schema != "" {
+			buf.Quote(schema).WriteByte('.')
+		}
+		buf.WriteStrings("SQLITE_SEQUENCE WHERE name='", name, "';")
+	}
+
+	return buf.String()
 }
 
 func (s *sqlite3) TransactionalDDL() bool {
@@ -127,6 +363,39 @@ func (s *sqlite3) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
 		return errors.New("sqlType:无效的col.GoType值")
 	}
 
+	if col.AutoRandom {
+		return errors.New("sqlType:不支持 autorandom")
+	}
+
+	if col.Zerofill {
+		return errors.New("sqlType:不支持 zerofill")
+	}
+
+	if col.PGArray {
+		return errors.New("sqlType:不支持 pgarray")
+	}
+
+	if col.Generated != "" && !s.SupportsFeature(orm.FeatureGenerated, s.version) {
+		return errors.New("sqlType:当前版本不支持生成列")
+	}
+
+	if registeredType(s.Name(), buf, col) {
+		return nil
+	}
+
+	if col.Geometry != "" {
+		return errors.New("sqlType:不支持空间数据类型")
+	}
+
+	if col.Serialize != "" || len(col.Set) > 0 {
+		buf.WriteString("TEXT")
+		return nil
+	}
+
+	if err := checkColumnType(col, sqlite3StringTypes, sqlite3TimeTypes); err != nil {
+		return err
+	}
+
 	switch col.GoType.Kind() {
 	case reflect.Bool:
 		buf.WriteString("INTEGER")
@@ -154,8 +423,16 @@ func (s *sqlite3) sqlType(buf *sqlbuilder.SQLBuilder, col *model.Column) error {
 		case nullString:
 			buf.WriteString("TEXT")
 		case timeType:
-			buf.WriteString("DATETIME")
+			if col.Type == "" {
+				buf.WriteString("DATETIME")
+			} else {
+				buf.WriteString(strings.ToUpper(col.Type))
+			}
+		default:
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
 		}
+	default:
+		return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
 	}
 
 	return nil
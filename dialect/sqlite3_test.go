@@ -55,4 +55,85 @@ func TestSqlite3_sqlType(t *testing.T) {
 	buf.Reset()
 	a.NotError(s.sqlType(buf, col))
 	sqltest.Equal(a, buf.String(), "INTEGER")
+
+	// 未指定序列化方式的结构体
+	col.GoType = reflect.TypeOf(struct{ ID int }{})
+	buf.Reset()
+	a.Error(s.sqlType(buf, col))
+
+	col.Serialize = "json"
+	buf.Reset()
+	a.NotError(s.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT")
+
+	// map
+	col.GoType = reflect.TypeOf(map[string]int{})
+	col.Serialize = ""
+	buf.Reset()
+	a.Error(s.sqlType(buf, col))
+
+	// set
+	col.GoType = reflect.TypeOf("")
+	col.Set = []string{"read", "write"}
+	buf.Reset()
+	a.NotError(s.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "TEXT")
+}
+
+func TestSqlite3_QuoteString(t *testing.T) {
+	a := assert.New(t)
+	s := Sqlite3()
+
+	a.Equal(s.QuoteString("abc"), "'abc'")
+	a.Equal(s.QuoteString("O'Brien"), "'O''Brien'")
+	a.Equal(s.QuoteString(`c:\dir`), `'c:\dir'`)
+	a.Equal(s.QuoteString(`\'`), `'\'''`)
+}
+
+type withoutRowid struct {
+	Name  string `orm:"name(name);len(20);pk"`
+	Value string `orm:"name(value);len(50)"`
+}
+
+func (w *withoutRowid) Meta() string {
+	return "name(without_rowid);rowid(false)"
+}
+
+type withoutRowidAI struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (w *withoutRowidAI) Meta() string {
+	return "name(without_rowid_ai);rowid(false)"
+}
+
+type withoutRowidNoPK struct {
+	Name string `orm:"name(name);len(20)"`
+}
+
+func (w *withoutRowidNoPK) Meta() string {
+	return "name(without_rowid_nopk);rowid(false)"
+}
+
+func TestSqlite3_CreateTableSQL_withoutRowid(t *testing.T) {
+	a := assert.New(t)
+	s := &sqlite3{}
+
+	mod, err := model.New(&withoutRowid{})
+	a.NotError(err).NotNil(mod)
+	sqls, err := s.CreateTableSQL(mod, true)
+	a.NotError(err).Equal(len(sqls), 1)
+	sqltest.Equal(a, sqls[0], "CREATE TABLE IF NOT EXISTS {#without_rowid}({name} TEXT NOT NULL,{value} TEXT NOT NULL,CONSTRAINT pk PRIMARY KEY({name})) WITHOUT ROWID")
+
+	// 自增列
+	mod, err = model.New(&withoutRowidAI{})
+	a.NotError(err).NotNil(mod)
+	sqls, err = s.CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
+
+	// 没有主键
+	mod, err = model.New(&withoutRowidNoPK{})
+	a.NotError(err).NotNil(mod)
+	sqls, err = s.CreateTableSQL(mod, true)
+	a.Error(err).Nil(sqls)
 }
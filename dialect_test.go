@@ -0,0 +1,50 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+)
+
+type registerDialect struct {
+	orm.Dialect
+}
+
+func TestRegisterDialect(t *testing.T) {
+	a := assert.New(t)
+
+	// 内置的 Dialect 自动注册
+	d, found := orm.GetDialect("mysql")
+	a.True(found).Equal(d, dialect.Mysql())
+	d, found = orm.GetDialect("postgres")
+	a.True(found).Equal(d, dialect.Postgres())
+	d, found = orm.GetDialect("sqlite3")
+	a.True(found).Equal(d, dialect.Sqlite3())
+
+	d, found = orm.GetDialect("not-exists")
+	a.False(found).Nil(d)
+
+	rd := &registerDialect{Dialect: dialect.Sqlite3()}
+	orm.RegisterDialect("test-register", rd)
+	d, found = orm.GetDialect("test-register")
+	a.True(found).True(d == rd)
+
+	// 重复注册
+	a.Panic(func() {
+		orm.RegisterDialect("test-register", rd)
+	})
+	a.Panic(func() {
+		orm.RegisterDialect("mysql", dialect.Mysql())
+	})
+
+	// nil
+	a.Panic(func() {
+		orm.RegisterDialect("test-nil", nil)
+	})
+}
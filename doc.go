@@ -10,6 +10,14 @@
 //  3. postgres: github.com/lib/pq
 // 其它数据库，用户可以通过实现 Dialect 接口，来实现相应的支持。
 //
+// 自定义的 Go 类型可以通过 dialect.RegisterType() 为各个数据库指定对应的 SQL 类型：
+//  dialect.RegisterType("mysql", reflect.TypeOf(UUID{}), "CHAR(36)")
+//  dialect.RegisterType("postgres", reflect.TypeOf(UUID{}), "UUID")
+//
+// 可以通过 dialect.MysqlVersion("5.7.20") 等函数指定数据库的版本，
+// 生成 SQL 时会根据版本判断是否支持 JSON 类型、CHECK 约束和生成列等特性，
+// 不支持时会返回错误或是以文本类型代替，具体可参考 orm.VersionedDialect。
+//
 //
 //
 // 初始化：
@@ -26,6 +34,10 @@
 //  // 另一个 DB 实例
 //  db2 := orm.NewDB("sqlite3", "./db2", "db2_", dialect.Sqlite3())
 //
+// dialect 包中的 Dialect 会以其名称自动注册，也可以通过名称获取，
+// 方便从配置文件或是环境变量中选择数据库：
+//  d, found := orm.GetDialect(os.Getenv("DB_DRIVER"))
+//
 //
 //
 // 占位符
@@ -38,6 +50,7 @@
 // 数据库为 mysql 时，会被替换成以下语句，然后再执行：
 //  select * from p_user where `group`=1
 // DB.Query(),DB.Exec(),DB.Prepare().DB.Where() 及 Tx 与之对应的函数都可以使用占位符。
+// 单引号包含的字符串中的 {} 不会被替换，具体可参考 sqlbuilder.ReplaceQuotes()。
 //
 // Model 不能指定占位符，它们默认总会使用占位符，且无法取消。
 //
@@ -64,24 +77,41 @@
 // 目前支持以下的 struct tag：
 //
 //  name(fieldName): 将当前的字段映射到数据表中的 fieldName 字段。
+//  未指定时，由 model.SetNamingStrategy 指定的命名策略决定，默认原样使用字段名，
+//  可以指定为 model.SnakeCase，将 UserID 等转换成 user_id。
 //
 //  len(l1, l2): 指定字段的长度。比如 mysql 中的int(5),varchar(255),double(1,2),
 //  不支持该特性的数据，将会忽略该标签的内容，比如 sqlite3。
 //  NOTE:字符串类型必须指定长度，若长度过大或是将长度设置了-1，
 //  想使用类似于 TEXT 等不定长的形式表达。
+//  也可以使用 len(max) 代替 len(-1)。
+//  只能作用于字符串、整数、浮点数和时间类型，浮点数必须同时指定两个值，
+//  时间类型的长度表示小数秒的精度，其它类型指定该属性会返回错误。
 //
 //  nullable(true|false): 相当于定义表结构时的 NULL，建议尽量少用该属性，
 //  若非用不可的话，与之对应的 Go 属性必须声明为 NullString之类的结构。
+//  指针类型的字段默认即为 nullable，可以通过 nullable(false) 取消。
 //
 //  pk: 主键，支持联合主键，给多个字段加上pk的struct tag即可。
 //
-//  ai: 自增，若指定了自增列，则将自动取消其它的 pk 设置。无法指定起始值和步长。
+//  ai 或是 ai(start,step): 自增，若指定了自增列，则将自动取消其它的 pk 设置。
+//  start 和 step 分别为起始值和步长，默认都为 1。mysql 的步长由服务器变量决定，
+//  mysql 和 sqlite3 在创建表时，若指定了步长会返回错误。
 //  可手动设置一个非零值来更改某条数据的 AI 行为。
 //
+//  notpk: 与 ai 一起使用，自增列不再作为主键，也不会取消其它的 pk 设置，
+//  主键和索引由 pk、index 或 unique 另行定义。mysql 要求该列必须是主键或是某一索引的第一列，
+//  sqlite3 不支持。
+//
 //  unique(index_name): 唯一索引，支持联合索引，index_name 为约束名，
 //  会将 index_name 为一样的字段定义为一个联合索引。
+//  可以通过 unique(index_name,where:deleted_at IS NULL) 的形式指定部分索引的条件，
+//  仅 postgres 和 sqlite3 支持，mysql 会在创建表时返回错误。
+//  条件中不能包含逗号和括号，比如 where:status IN (1,2) 会返回错误。
 //
 //  index(index_name): 普通的关键字索引，同 unique 一样会将名称相同的索引定义为一个联合索引。
+//  可以通过 index(index_name,collate:utf8mb4_general_ci) 为索引中的当前列单独指定排序规则，
+//  mysql 8.0.13 之前的版本不支持该功能。
 //
 // occ(true|false) 当前列作为乐观锁字段。
 //
@@ -89,10 +119,85 @@
 //  当一个字段如果是个零值(reflect.Zero())时，将会使用它的默认值，
 //  但是系统无法判断该零值是人为指定，还是未指定被默认初始化零值的，
 //  所以在需要用到零值的字段，最好不要用 default 的 struct tag。
+//  time.Time 类型的列，若默认值为以下关键字(不区分大小写)，则会原样输出，而不是当作字符串：
+//   mysql: CURRENT_TIMESTAMP, NOW()
+//   postgres: CURRENT_TIMESTAMP, CURRENT_DATE, LOCALTIMESTAMP, NOW()
+//   sqlite3: CURRENT_TIMESTAMP, CURRENT_DATE, CURRENT_TIME
+//  使用其它数据库的关键字会返回错误。NOW() 之类带括号的值，需要通过 Model.SetColumnDefault 指定。
+//
+//  charset(name), collate(name): 指定列的字符集和排序规则，仅对字符串类型的列有效，
+//  目前仅 mysql 会输出该内容。
+//
+//  updated: 指定该列在更新时自动设置为当前时间，类型只能是 time.Time，每个表只能指定一个。
+//  也可以直接嵌入 model.Timestamps，得到带有默认值的 created_at 和 updated_at 两列。
+//  通过 DB.Update() 或是 SQL.UpdateWhere() 更新数据时，会自动更新该列的值。
+//
+//  geometry(type): 指定列为空间数据类型，type 可以是 geometry,point,linestring,polygon 等，
+//  不能与 ai,occ,default 同时使用。在该列上创建的普通索引会被定义为 SPATIAL INDEX，
+//  目前仅 mysql 支持。
+//
+//  check(chk_name,expr): 定义列级别的 check 约束，与 Metaer 中的 check 作用相同，约束名不能与其它约束重复。
+//  expr 中不能包含逗号和括号，比如：check(age_chk,{age}>0)。
+//
+//  type(name): 为字符串类型的列指定数据库类型，指定之后将忽略 len 对类型的影响。
+//  可用的值由各个数据库决定，mysql 为 tinytext,text,mediumtext,longtext，postgres 和 sqlite3 为 text。
+//  也可以作用于 time.Time 类型的列，mysql 和 sqlite3 可以是 datetime,timestamp,date,time，
+//  postgres 可以是 timestamp,date,time，其中 timestamp 对应 TIMESTAMPTZ。
+//  未指定时，mysql 和 sqlite3 为 DATETIME，postgres 为 TIMESTAMP。
+//  时间类型可以通过 len(6) 指定小数秒的精度。
+//  也可以采用 type(mysql:json,sqlite3:text) 的形式为各个数据库单独指定类型，
+//  名称与 Dialect.Name() 相同，未指定的数据库采用默认的类型，该类型会原样输出。
+//  未知的数据库名称会返回错误，自定义的 Dialect 需要通过 orm.RegisterDialect 或是
+//  model.RegisterDialectName 注册其名称。类型中不能包含逗号和括号，
+//  所以无法指定 DECIMAL(10,2) 之类带参数的类型。
+//
+//  serialize(json): 指定结构体、map 等类型的列以序列化之后的文本形式保存，可以是 json 或是 gob。
+//  未指定该属性的非内置结构体类型，在创建表时会返回错误。
+//  map 类型以 json 序列化时，mysql 使用 JSON 类型，postgres 使用 JSONB 类型，其它情况都以文本保存。
+//  Insert、Update 等操作会自动完成序列化，其中 gob 序列化之后的内容以 base64 编码保存；
+//  读取时需要调用者自行完成反序列化，若 map 的值为 interface{}，读取时其中的数值会被解析为 float64。
+//
+//  softdelete: 指定软删除的标记列，类型只能是 time.Time 或是 bool，每个表只能有一个。
+//  time.Time 类型以 NULL 表示未删除，所以必须同时指定 nullable，或是使用 *time.Time。
+//  指定之后 Dialect.DeleteSQL 生成的是更新该列的 UPDATE 语句，查询时需要自行过滤已经删除的记录。
+//
+//  unsigned(true|false): 将整数类型的列指定为无符号，即使 Go 中的类型是有符号的，仅对整数类型有效。
+//  mysql 中会添加 UNSIGNED，postgres 中会以 CHECK(col>=0) 代替，sqlite3 则忽略该属性。
+//
+//  zerofill(true|false): 以 0 填充整数至 len 指定的宽度，比如 len(6);zerofill 对应 mysql 中的
+//  INT(6) UNSIGNED ZEROFILL，同时也表示无符号。仅对整数类型有效，且必须通过 len 指定宽度，
+//  仅 mysql 支持，其它数据库在创建表时会返回错误。
+//
+//  readonly(true|false): 只读列，其值由数据库维护，比如触发器或是生成列，不会出现在 INSERT 和 UPDATE 语句中。
+//  可以通过 Model.WritableColumns() 获取除自增列和只读列之外的列。
+//
+//  generated(expr,stored|virtual): 定义生成列，expr 为生成列的表达式，第二个参数表示其值的保存方式，默认为 virtual。
+//  生成列总是只读的，且不能与 ai,default 同时使用。expr 中不能包含逗号和括号。
+//  postgres 仅支持 stored，指定为 virtual 时，在创建表时会返回错误。
+//
+//  set(v1,v2,...): 指定字符串类型的列为 SET 类型，可选值为 v1,v2 等，不能为空或是重复。
+//  仅 mysql 支持 SET 类型，postgres 和 sqlite3 会以 TEXT 类型保存，由调用者自行处理多个值之间的分隔。
+//
+//  pgarray: 将切片保存为 postgres 的数组类型，mysql 和 sqlite3 在创建表时会返回错误。
+//  元素类型与数组类型的对应关系为：bool 为 BOOLEAN[]，int8,int16,uint16 为 SMALLINT[]，
+//  uint32 为 INT[]，int,int64,uint,uint64 为 BIGINT[]，float32 为 REAL[]，
+//  float64 为 DOUBLE PRECISION[]，string 为 TEXT[]，time.Time 为 TIMESTAMPTZ[]。
+//  []byte 和 []rune 依然被当作字符串处理。写入时会转换成数组的文本形式，比如 {1,2,3}，
+//  实现了 driver.Valuer 的类型则由其自行转换；读取时需要能处理该切片类型的 sql.Scanner，
+//  比如 lib/pq 中的 pq.Int64Array、pq.StringArray 等。
+//
+//  autorandom: 以 TiDB 的 AUTO_RANDOM 代替自增，限制与 ai 相同，且不能与 ai 同时使用。
+//  该列同样会被当作自增列处理，仅 mysql(TiDB) 支持，其它数据库在创建表时会返回错误。
+//
+//  prefix(billing_): 只能作用于结构体类型的字段，将该结构体中的字段展开到当前模型中，
+//  每个列名都会加上 billing_ 前缀，结构体中 index、unique 和 fk 指定的约束名也会加上该前缀，
+//  所以同一结构体可以被多次嵌入。不能与其它属性同时使用。
 //
 //  fk(fk_name,refTable,refColName,updateRule,deleteRule):
 //  定义物理外键，最少需要指定 fk_name,refTabl,refColName 三个值。分别对应约束名，
 //  引用的表和引用的字段，updateRule,deleteRule，在不指定的情况下，使用数据库的默认值。
+//  规则只能是 CASCADE、SET NULL、RESTRICT、NO ACTION 和 SET DEFAULT 之一，不区分大小写。
+//  多个列指定相同的 fk_name 时，表示复合外键，这些列引用的表必须相同。
 //
 //  check(chk_name, expr): check 约束。chk_name 为约束名，expr 为该约束的表达式。
 //  check 约束只能在 model.Metaer 接口中指定，而不是像其它约束一样，通过字段的 struct tag 指定。
@@ -106,9 +211,20 @@
 // 只能通过接口的形式，在接口方法中返回一段类似于 struct tag 的字符串，
 // 以达到相同的目的。
 //
-// 在 model.Metaer 中除了可以指定 name(table_name) 和 check(name,expr) 两个属性之外，
+// 在 model.Metaer 中除了可以指定 name(table_name)、schema(schema_name) 和 check(name,expr) 等属性之外，
 // 还可指定一些自定义的属性，这些属性都将会被保存到 Model.Meta 中。
 //
+// 也可以通过实现 model.TableNamer 接口的 TableName() string 方法指定表名。
+// 表名的优先级从高到低依次为：Metaer 中的 name 属性、TableName() 的返回值以及结构体的类型名称。
+//
+// schema(schema_name) 指定表所在的 schema，mysql 中表示数据库名，
+// 建表、删表、清空表以及由 Model 生成的增删改查语句都会以 {schema_name}.{#table_name} 的形式引用该表。
+//
+// partition(range,col) 和 partition_values(name:value,...) 指定按列 col 进行 range 分区，
+// 每个分区包含小于 value 的记录，value 为 MAXVALUE 表示无上限。目前仅 mysql 支持，
+// 且主键和唯一约束中必须包含 col 列：
+//  partition(range,created);partition_values(p2017:2018-01-01,pmax:MAXVALUE)
+//
 //
 //
 // 约束名：
@@ -165,6 +281,10 @@
 // 返回事务对象 Tx，当然并不是所有的数据库都支持事务操作的。
 // Tx拥有一组与 DB 相同的接口，另外还提供了一组以 `Mult` 开头的函数，
 // 用以同时操作多条记录的。
+//
+// 嵌套事务可以通过保存点实现，Dialect.SavepointSQL()、Dialect.ReleaseSavepointSQL()
+// 和 Dialect.RollbackToSavepointSQL() 分别生成创建、释放和回滚保存点的语句，
+// 目前支持的 mysql、postgres 和 sqlite3 都支持多层嵌套的保存点。
 package orm
 
 // 数据表的更改，涉及到很多方面：
@@ -71,6 +71,12 @@ func Obj(obj interface{}, rows *sql.Rows) (int, error) {
 // 键值为字段的值。支持匿名字段，不会转换不可导出(小写字母开头)的
 // 字段，也不会转换 struct tag 以-开头的字段。
 func parseObj(v reflect.Value, ret *map[string]reflect.Value) error {
+	return parseObjPrefix(v, "", ret)
+}
+
+// 功能同 parseObj，但所有的键名都会加上 prefix 前缀。
+// 带 prefix 属性的结构体字段，会以加上该前缀的形式展开其中的字段。
+func parseObjPrefix(v reflect.Value, prefix string, ret *map[string]reflect.Value) error {
 	for v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
@@ -84,7 +90,7 @@ func parseObj(v reflect.Value, ret *map[string]reflect.Value) error {
 		field := vt.Field(i)
 
 		if field.Anonymous {
-			parseObj(v.Field(i), ret)
+			parseObjPrefix(v.Field(i), prefix, ret)
 			continue
 		}
 
@@ -94,21 +100,29 @@ func parseObj(v reflect.Value, ret *map[string]reflect.Value) error {
 				continue
 			}
 
+			if p, found := t.Get(tags, "prefix"); found && len(p) == 1 {
+				if err := parseObjPrefix(v.Field(i), prefix+p[0], ret); err != nil {
+					return err
+				}
+				continue
+			}
+
 			if name, found := t.Get(tags, "name"); found {
-				if _, found := (*ret)[name[0]]; found {
+				if _, found := (*ret)[prefix+name[0]]; found {
 					return ErrInvalidKind
 				}
-				(*ret)[name[0]] = v.Field(i)
+				(*ret)[prefix+name[0]] = v.Field(i)
 				continue
 			}
 		}
 
 		// 未指定 struct tag，则尝试直接使用字段名。
 		if unicode.IsUpper(rune(field.Name[0])) {
-			if _, found := (*ret)[field.Name]; found {
-				return fmt.Errorf("已存在相同名字的字段 %s", field.Name)
+			name := prefix + field.Name
+			if _, found := (*ret)[name]; found {
+				return fmt.Errorf("已存在相同名字的字段 %s", name)
 			}
-			(*ret)[field.Name] = v.Field(i)
+			(*ret)[name] = v.Field(i)
 		}
 	} // end for
 
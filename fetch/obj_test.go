@@ -61,6 +61,28 @@ func TestParseObj(t *testing.T) {
 	a.Equal(1, obj.Group)
 }
 
+func TestParseObj_prefix(t *testing.T) {
+	a := assert.New(t)
+
+	type address struct {
+		Street string `orm:"name(street)"`
+		City   string
+	}
+	obj := &struct {
+		ID      int     `orm:"name(id)"`
+		Billing address `orm:"prefix(billing_)"`
+	}{}
+	mapped := map[string]reflect.Value{}
+
+	err := parseObj(reflect.ValueOf(obj).Elem(), &mapped)
+	a.NotError(err).Equal(3, len(mapped), "长度不相等，导出元素为:[%v]", mapped)
+
+	mapped["billing_street"].SetString("street")
+	a.Equal("street", obj.Billing.Street)
+	mapped["billing_City"].SetString("city")
+	a.Equal("city", obj.Billing.City)
+}
+
 // 初始化一个sql.DB(sqlite3)，方便后面的测试用例使用。
 func initDB(a *assert.Assertion) *sql.DB {
 	db, err := sql.Open("sqlite3", testDBFile)
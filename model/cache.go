@@ -0,0 +1,261 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/issue9/orm/fetch"
+)
+
+// 包级别的 New、Clear 等函数使用的缓存
+var defaultCache = NewCache()
+
+// Cache Model 的缓存
+//
+// 同一类型在同一个 Cache 中只会被解析一次，
+// 可以通过声明多个 Cache 实例，让同一类型在不同的 Cache 中拥有各自的 Model 实例。
+type Cache struct {
+	lock            sync.Mutex
+	items           map[cacheKey]*Model
+	caseInsensitive bool
+	strictIndex     bool
+	noCaching       bool // 为 true 表示不缓存，每次调用 New 都重新解析
+	naming          NamingStrategy
+}
+
+// 缓存的键名，同一类型排除不同的字段，会生成不同的 Model 实例。
+type cacheKey struct {
+	rtype    reflect.Type
+	excludes string // 排序之后的排除字段，以逗号分隔
+}
+
+// NewCache 声明一个新的 Cache 实例
+func NewCache() *Cache {
+	return &Cache{
+		items:  map[cacheKey]*Model{},
+		naming: Identity,
+	}
+}
+
+// New 从一个 obj 声明一个 Model 实例，并缓存在 c 中。
+//
+// obj 可以是一个 struct 实例或是指针。
+func (c *Cache) New(obj interface{}) (*Model, error) {
+	return c.NewExcluding(obj)
+}
+
+// NewExcluding 从一个 obj 声明一个 Model 实例，并忽略 fields 中指定的字段。
+//
+// fields 为 Go 中的字段名，通过 prefix 属性嵌入的结构体中的字段，
+// 以点号连接，比如 Billing.Street。若字段不存在，则返回错误。
+// 其作用与在 struct tag 中指定 orm:"-" 相同，
+// 排除不同字段的 Model 会被分别缓存，与 New 返回的 Model 互不影响。
+func (c *Cache) NewExcluding(obj interface{}, fields ...string) (*Model, error) {
+	rtype, err := structType(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	excludes := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		excludes[field] = false
+	}
+	key := cacheKey{rtype: rtype, excludes: excludesKey(excludes)}
+
+	if !c.noCaching {
+		if m, found := c.items[key]; found {
+			return m, nil
+		}
+	}
+
+	m, err := c.parse(obj, rtype, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.noCaching {
+		c.items[key] = m
+	}
+	return m, nil
+}
+
+// NewUncached 从一个 obj 声明一个 Model 实例，但不会使用或是写入 c 中的缓存。
+//
+// 每次调用都会重新解析 obj，解析规则与 New 相同。
+// 对返回值的修改不会影响 New 等方法返回的实例，适用于需要修改 Model 的场景。
+func (c *Cache) NewUncached(obj interface{}) (*Model, error) {
+	rtype, err := structType(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.parse(obj, rtype, map[string]bool{})
+}
+
+// 获取 obj 对应的结构体类型，obj 可以是结构体或是指向结构体的指针。
+func structType(obj interface{}) (reflect.Type, error) {
+	rval := reflect.ValueOf(obj)
+	for rval.Kind() == reflect.Ptr {
+		rval = rval.Elem()
+	}
+	rtype := rval.Type()
+
+	if rtype.Kind() != reflect.Struct {
+		return nil, fetch.ErrInvalidKind
+	}
+	return rtype, nil
+}
+
+// 按 c 中的规则解析 obj，调用者需要负责加锁。
+func (c *Cache) parse(obj interface{}, rtype reflect.Type, excludes map[string]bool) (*Model, error) {
+	m := &Model{
+		Cols:          map[string]*Column{},
+		KeyIndexes:    map[string][]*Column{},
+		UniqueIndexes: map[string][]*Column{},
+		UniqueConds:   map[string]string{},
+		IndexCollates: map[string]map[*Column]string{},
+		Name:          c.naming.TableName(rtype.Name()),
+		FK:            map[string]*ForeignKey{},
+		Check:         map[string]string{},
+		Meta:          map[string][]string{},
+		constraints:   map[string]conType{},
+
+		caseInsensitive: c.caseInsensitive,
+		strictIndex:     c.strictIndex,
+		naming:          c.naming,
+		excludes:        excludes,
+	}
+
+	if err := m.parseColumns(rtype, "", ""); err != nil {
+		return nil, err
+	}
+
+	for field, used := range excludes {
+		if !used {
+			return nil, fmt.Errorf("排除的字段 %s 不存在", field)
+		}
+	}
+
+	if err := m.parseMeta(obj); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func excludesKey(excludes map[string]bool) string {
+	fields := make([]string, 0, len(excludes))
+	for field := range excludes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return strings.Join(fields, ",")
+}
+
+// Clear 清除所有的 Model 缓存。
+func (c *Cache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.items = map[cacheKey]*Model{}
+}
+
+// SetCaseInsensitive 设置在解析 Model 时，检测列名是否重复是否不区分大小写
+//
+// 部分数据库(比如某些平台上的 mysql)中的列名是不区分大小写的，
+// 此时 Name 和 name 会被当作同一列。指定为 true 之后，此类列名会被当作重复的列名返回错误，
+// 但列名本身依然保留原来的大小写。默认为 false，即区分大小写。
+//
+// 修改该值会清除 c 中所有已经缓存的 Model，以保证所有的 Model 都采用相同的规则。
+func (c *Cache) SetCaseInsensitive(v bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.caseInsensitive = v
+	c.items = map[cacheKey]*Model{}
+}
+
+// SetCaching 设置是否缓存解析之后的 Model
+//
+// 默认为 true。指定为 false 之后，每次调用 New 和 NewExcluding 都会重新解析，
+// 返回的是不同的实例，方便在测试中反复检测 struct tag 的解析过程。
+// 同时会清除 c 中所有已经缓存的 Model，
+// 此时未指定参数的 CheckConstraintNames 将没有可检测的 Model。
+func (c *Cache) SetCaching(enabled bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.noCaching = !enabled
+	c.items = map[cacheKey]*Model{}
+}
+
+// SetStrictIndexNames 设置在解析 Model 时，是否以严格模式检测索引名和唯一约束名
+//
+// mysql 等数据库的唯一约束是以索引的形式实现的，与普通索引共用同一命名空间，
+// 且索引名不区分大小写。
+//
+// 默认为 false，即宽松模式，与之前的行为相同：index 和 unique 本就共用同一命名空间，
+// 但已有的名称是原样保存的，而查找时会将新的名称转换成小写，
+// 所以只有已有的名称全为小写时才能检测到重复，比如 unique(uq_a) 与 index(UQ_A)。
+// 指定为 true 之后，index 和 unique 的名称以不区分大小写的方式比较，
+// 包括完全相同以及仅大小写不同的名称，都会被当作重复的名称返回错误。
+//
+// 修改该值会清除 c 中所有已经缓存的 Model，以保证所有的 Model 都采用相同的规则。
+func (c *Cache) SetStrictIndexNames(v bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.strictIndex = v
+	c.items = map[cacheKey]*Model{}
+}
+
+// SetNamingStrategy 设置在解析 Model 时，未指定 name 属性的表名和列名的命名策略
+//
+// 为 nil 表示 Identity，即原样使用 Go 中的名称，这也是默认值。
+// 通过 name 属性、Metaer 或是 TableNamer 指定的名称不受影响。
+//
+// 修改该值会清除 c 中所有已经缓存的 Model，以保证所有的 Model 都采用相同的规则。
+// 需要注意 fetch 包依然以字段名匹配未指定 name 属性的字段，
+// 非 Identity 的命名策略下，应该使用 orm.ScanRows 等基于 Model 的方法导出数据。
+func (c *Cache) SetNamingStrategy(ns NamingStrategy) {
+	if ns == nil {
+		ns = Identity
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.naming = ns
+	c.items = map[cacheKey]*Model{}
+}
+
+// CheckConstraintNames 检测多个 Model 之间是否存在相同的约束名。
+//
+// 若未指定 ms，则检测 c 中所有已经缓存的 Model，
+// 其它说明可参考包级别的 CheckConstraintNames 函数。
+func (c *Cache) CheckConstraintNames(ms ...*Model) error {
+	if len(ms) == 0 {
+		c.lock.Lock()
+		ms = make([]*Model, 0, len(c.items))
+		for _, m := range c.items {
+			ms = append(ms, m)
+		}
+		c.lock.Unlock()
+	}
+
+	return checkConstraintNames(ms)
+}
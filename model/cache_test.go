@@ -0,0 +1,256 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+func TestCache(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	c1 := NewCache()
+	c2 := NewCache()
+
+	m1, err := c1.New(&modeltest.User{})
+	a.NotError(err).NotNil(m1)
+	a.Equal(len(c1.items), 1).Equal(len(c2.items), 0)
+	a.Equal(len(defaultCache.items), 0)
+
+	// 同一个 Cache 中返回相同的实例
+	m, err := c1.New(&modeltest.User{})
+	a.NotError(err)
+	a.True(m == m1)
+
+	// 不同 Cache 中的实例相互独立
+	m2, err := c2.New(&modeltest.User{})
+	a.NotError(err).NotNil(m2)
+	a.True(m1 != m2)
+	a.NotError(m2.RemoveColumn("password"))
+	a.NotNil(m1.Cols["password"]).Nil(m2.Cols["password"])
+
+	c1.Clear()
+	a.Equal(len(c1.items), 0).Equal(len(c2.items), 1)
+}
+
+func TestCache_NewExcluding(t *testing.T) {
+	a := assert.New(t)
+	c := NewCache()
+
+	m1, err := c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m1)
+	a.NotNil(m1.Cols["password"])
+
+	m2, err := c.NewExcluding(&modeltest.User{}, "Password")
+	a.NotError(err).NotNil(m2)
+	a.True(m1 != m2).
+		Nil(m2.Cols["password"]).
+		NotNil(m2.Cols["id"]).
+		NotNil(m1.Cols["password"]) // 不影响默认的 Model
+	a.Equal(len(c.items), 2)
+
+	// 排除字段的顺序不影响缓存
+	m3, err := c.NewExcluding(&modeltest.User{}, "Username", "Password")
+	a.NotError(err).NotNil(m3)
+	m4, err := c.NewExcluding(&modeltest.User{}, "Password", "Username")
+	a.NotError(err)
+	a.True(m3 == m4).True(m3 != m2)
+	a.Equal(len(c.items), 3)
+	a.Nil(m3.Cols["Username"]).Empty(m3.UniqueIndexes)
+
+	// 不指定字段，等同于 New
+	m, err := c.NewExcluding(&modeltest.User{})
+	a.NotError(err).True(m == m1)
+
+	// 同一张表的不同实例，不作为约束名冲突
+	a.NotError(c.CheckConstraintNames())
+
+	// 不存在的字段
+	m, err = c.NewExcluding(&modeltest.User{}, "NotExists")
+	a.Error(err).Nil(m)
+
+	// prefix 嵌入的字段
+	type address struct {
+		Street string `orm:"name(street);len(50)"`
+		City   string `orm:"name(city);len(20)"`
+	}
+	type order struct {
+		ID      int64   `orm:"name(id);ai"`
+		Billing address `orm:"prefix(billing_)"`
+	}
+	m, err = c.NewExcluding(&order{}, "Billing.City")
+	a.NotError(err).NotNil(m)
+	a.NotNil(m.Cols["billing_street"]).Nil(m.Cols["billing_city"])
+
+	// 包级别的函数
+	defer Clear()
+	m, err = NewExcluding(&modeltest.User{}, "Password")
+	a.NotError(err).NotNil(m)
+	a.Nil(m.Cols["password"])
+}
+
+func TestCache_NewUncached(t *testing.T) {
+	a := assert.New(t)
+	c := NewCache()
+
+	m1, err := c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m1)
+
+	m2, err := c.NewUncached(&modeltest.User{})
+	a.NotError(err).NotNil(m2)
+	a.True(m1 != m2).Equal(m1.Name, m2.Name)
+	a.Equal(len(c.items), 1)
+
+	// 每次返回不同的实例，且修改不影响缓存中的 Model
+	m3, err := c.NewUncached(&modeltest.User{})
+	a.NotError(err).NotNil(m3)
+	a.True(m2 != m3)
+	a.NotError(m2.RemoveColumn("password"))
+	a.NotNil(m1.Cols["password"]).NotNil(m3.Cols["password"])
+
+	m, err := c.NewUncached(5)
+	a.Error(err).Nil(m)
+}
+
+func TestCache_SetCaseInsensitive(t *testing.T) {
+	a := assert.New(t)
+
+	type caseCols struct {
+		Name  string `orm:"name(Name)"`
+		Name2 string `orm:"name(name)"`
+	}
+
+	c := NewCache()
+	m, err := c.New(&caseCols{})
+	a.NotError(err).NotNil(m)
+	a.Equal(len(c.items), 1)
+
+	// 修改之后会清除缓存
+	c.SetCaseInsensitive(true)
+	a.Equal(len(c.items), 0)
+	m, err = c.New(&caseCols{})
+	a.Error(err).Nil(m)
+
+	// 保留原来的大小写
+	m, err = c.New(&modeltest.UserInfo{})
+	a.NotError(err).NotNil(m)
+	a.NotNil(m.Cols["firstName"])
+	col, found := m.FindColumn("FIRSTNAME")
+	a.True(found).Equal(col, m.Cols["firstName"])
+
+	// 通过 AddColumn 添加的列同样会被检测
+	col = &Column{Name: "FIRSTNAME", GoName: "FirstName2", GoType: m.Cols["firstName"].GoType}
+	a.Error(m.AddColumn(col))
+
+	c.SetCaseInsensitive(false)
+	m, err = c.New(&caseCols{})
+	a.NotError(err).NotNil(m)
+	col, found = m.FindColumn("NAME")
+	a.False(found).Nil(col)
+}
+
+func TestCache_SetCaching(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewCache()
+	m1, err := c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m1)
+	m2, err := c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m2)
+	a.True(m1 == m2)
+
+	c.SetCaching(false)
+	a.Equal(len(c.items), 0)
+	m1, err = c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m1)
+	m2, err = c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m2)
+	a.True(m1 != m2)
+	a.Equal(m1.Name, m2.Name)
+	a.Equal(len(c.items), 0)
+
+	c.SetCaching(true)
+	m1, err = c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m1)
+	m2, err = c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m2)
+	a.True(m1 == m2)
+}
+
+func TestCache_SetStrictIndexNames(t *testing.T) {
+	a := assert.New(t)
+
+	type indexUnique struct {
+		A int `orm:"unique(Uq_a)"`
+		B int `orm:"index(uQ_A)"`
+	}
+
+	type uniques struct {
+		A int `orm:"unique(Uq_a)"`
+		B int `orm:"unique(uQ_a)"`
+	}
+
+	type sameName struct {
+		A int `orm:"unique(Uq_a)"`
+		B int `orm:"index(Uq_a)"`
+	}
+
+	type lowerName struct {
+		A int `orm:"unique(uq_a)"`
+		B int `orm:"index(UQ_A)"`
+	}
+
+	// 宽松模式，与之前的行为相同，仅已有的名称为小写时才能检测到重复
+	c := NewCache()
+	m, err := c.New(&indexUnique{})
+	a.NotError(err).NotNil(m)
+	m, err = c.New(&uniques{})
+	a.NotError(err).NotNil(m)
+	m, err = c.New(&sameName{})
+	a.NotError(err).NotNil(m)
+	a.Equal(len(c.items), 3)
+	m, err = c.New(&lowerName{})
+	a.Error(err).Nil(m)
+
+	// 修改之后会清除缓存
+	c.SetStrictIndexNames(true)
+	a.Equal(len(c.items), 0)
+	m, err = c.New(&indexUnique{})
+	a.Error(err).Nil(m)
+	m, err = c.New(&uniques{})
+	a.Error(err).Nil(m)
+	m, err = c.New(&sameName{})
+	a.Error(err).Nil(m)
+	m, err = c.New(&lowerName{})
+	a.Error(err).Nil(m)
+
+	// 同一约束由多列组成，不受影响
+	m, err = c.New(&modeltest.User{})
+	a.NotError(err).NotNil(m)
+
+	c.SetStrictIndexNames(false)
+	m, err = c.New(&indexUnique{})
+	a.NotError(err).NotNil(m)
+}
+
+func TestCache_CheckConstraintNames(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewCache()
+	_, err := c.New(&modeltest.User{})
+	a.NotError(err)
+	_, err = c.New(&modeltest.Admin{})
+	a.NotError(err)
+
+	// User 与 Admin 中包含了相同的约束名
+	a.Error(c.CheckConstraintNames())
+
+	// 空的 Cache
+	a.NotError(NewCache().CheckConstraintNames())
+}
@@ -5,8 +5,25 @@
 package model
 
 import (
+	"bytes"
+	"database/sql"
+	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/issue9/conv"
+)
+
+var (
+	nullString  = reflect.TypeOf(sql.NullString{})
+	nullInt64   = reflect.TypeOf(sql.NullInt64{})
+	nullFloat64 = reflect.TypeOf(sql.NullFloat64{})
+	nullBool    = reflect.TypeOf(sql.NullBool{})
+	timeType    = reflect.TypeOf(time.Time{})
 )
 
 // Column 列结构
@@ -17,22 +34,125 @@ type Column struct {
 	Len1     int          // 长度1，仅对部分类型启作用
 	Len2     int          // 长度2，仅对部分类型启作用
 	Nullable bool         // 是否可以为 NULL
-	GoType   reflect.Type // Go 语言中的数据类型
-	Zero     interface{}  // GoType 的零值
-	GoName   string       // 结构字段名
+	GoType   reflect.Type // Go 语言中的数据类型，指针类型则为其指向的类型
+	Zero     interface{}  // 字段类型的零值
+	GoName   string       // 结构字段名，通过 prefix 嵌入的字段以点号分隔各级字段名
 
 	HasDefault bool
 	Default    string // 默认值
+
+	Charset string // 字符集，仅对字符串类型启作用
+	Collate string // 排序规则，仅对字符串类型启作用
+
+	Geometry string // 空间数据类型，比如 point, polygon 等，为空表示非空间数据类型
+
+	Type string // 通过 type 属性指定的数据库类型，仅对字符串和时间类型启作用，为空表示使用默认类型
+
+	// 通过 type(mysql:json,sqlite3:text) 为不同数据库单独指定的类型，
+	// 键名为 Dialect.Name() 的返回值，键值会原样作为该数据库中的列类型，
+	// 可作用于任意类型，未指定的数据库依然采用默认的类型。
+	// 类型中不能包含逗号和括号，比如 DECIMAL(10,2)。
+	DialectTypes map[string]string
+
+	Unsigned bool // 是否为无符号整数，仅对整数类型启作用，Go 中的无符号类型不需要指定
+	Zerofill bool // 是否以 0 填充至 len 指定的宽度，仅对整数类型启作用，同时也表示无符号
+
+	AutoRandom bool // 以 TiDB 的 AUTO_RANDOM 代替自增，同时该列也是 Model.AI
+
+	// 自增列的起始值和步长，通过 ai(start,step) 指定，默认都为 1，仅对自增列启作用。
+	AIStart int64
+	AIStep  int64
+
+	// 通过 notpk 指定自增列不作为主键，仅对 ai 指定的自增列启作用。
+	// 此时主键和索引由 pk、index 或 unique 另行定义，自增列可以是其中的一列。
+	AINotPK bool
+
+	Readonly bool // 只读列，由数据库维护其值，不会出现在 INSERT 和 UPDATE 语句中
+
+	Set []string // SET 类型的可选值，仅对字符串类型启作用，为空表示非 SET 类型
+
+	// 生成列的表达式，为空表示非生成列。
+	// 生成列总是只读的，GeneratedStored 表示其值是否需要实际存储。
+	Generated       string
+	GeneratedStored bool
+
+	// 序列化方式，可以是 json 或是 gob，为空表示不需要序列化。
+	// 指定了序列化方式的列，在数据库中以文本的形式保存。
+	Serialize string
+
+	// 通过 pgarray 指定以 postgres 的数组类型保存切片，仅 postgres 支持。
+	// 切片元素只能是布尔、整数、浮点数、字符串以及 time.Time 类型，
+	// 其中 []byte 和 []rune 依然被当作字符串处理。
+	PGArray bool
 }
 
+// 声明一个新的 Column 实例。
+//
+// 列名由 m 的命名策略决定，之后可以通过 name 属性修改。
+// 若字段为指针类型，则该列默认为可以为 NULL，GoType 为指针指向的类型。
+// 可以通过 nullable(false) 重新将其指定为 NOT NULL。
 func (m *Model) newColumn(field reflect.StructField) *Column {
-	return &Column{
+	name := field.Name
+	if m.naming != nil {
+		name = m.naming.ColumnName(name)
+	}
+
+	col := &Column{
 		GoType: field.Type,
 		Zero:   reflect.Zero(field.Type).Interface(),
-		Name:   field.Name,
+		Name:   name,
 		model:  m,
 		GoName: field.Name,
 	}
+
+	if field.Type.Kind() == reflect.Ptr {
+		col.Nullable = true
+		col.GoType = field.Type.Elem()
+	}
+
+	return col
+}
+
+// IsZero 判断 v 是否为当前列的零值
+//
+// v 为该列对应字段的值，指针类型的字段仅在为 nil 时才是零值；
+// sql.NullString 等类型以其 Valid 字段为准。
+// 零值本身可以通过 Column.Zero 获取。
+//
+// Zero 在此之前已经是 Column 的导出字段，为了不破坏已有的代码，
+// 并没有将其改为 Zero() reflect.Value 方法。
+// 切片和 map 等类型无法通过 == 与 Zero 比较，判断零值时应该始终使用 IsZero。
+func (c *Column) IsZero(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+
+	if v.Kind() == reflect.Ptr {
+		return v.IsNil()
+	}
+
+	if t := v.Type(); t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" {
+		if valid := v.FieldByName("Valid"); valid.IsValid() && valid.Kind() == reflect.Bool {
+			return !valid.Bool()
+		}
+	}
+
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// FieldValue 从 rval 中获取当前列对应的字段值
+//
+// rval 为 Model 对应的结构体实例，
+// 通过 prefix 属性嵌入的字段，其 GoName 以点号分隔，会逐级查找。
+// 找不到对应的字段时，返回的值其 IsValid() 为 false。
+func (c *Column) FieldValue(rval reflect.Value) reflect.Value {
+	for _, name := range strings.Split(c.GoName, ".") {
+		if rval = rval.FieldByName(name); !rval.IsValid() {
+			break
+		}
+	}
+
+	return rval
 }
 
 // IsAI 当前列是否为自增列
@@ -40,43 +160,522 @@ func (c *Column) IsAI() bool {
 	return (c.model != nil) && (c.model.AI == c)
 }
 
+// Len 返回列的长度信息
+//
+// 对应 struct tag 中的 len 属性，未指定时均为 0；
+// 其中 -1 表示最大长度，即 len(max)。
+func (c *Column) Len() (int, int) {
+	return c.Len1, c.Len2
+}
+
+// SetDefault 为列指定默认值
+//
+// 作用与 struct tag 中的 default 属性相同，v 会被转换成字符串保存在 Default 中。
+// 自增列、主键和空间数据类型的列不能设置默认值。
+//
+// Model 实例会被缓存，修改会影响到之后所有通过 New 获取的同一 Model 实例。
+func (c *Column) SetDefault(v interface{}) error {
+	if c.model == nil {
+		return propertyError(c.Name, "default", KindValue, "未关联到 Model")
+	}
+
+	val, err := conv.String(v)
+	if err != nil {
+		return err
+	}
+
+	return c.model.setDefault(c, []string{val})
+}
+
+// IsString 是否为字符串类型，包括 []byte 和 []rune 以及 sql.NullString
+func (c *Column) IsString() bool {
+	if c.GoType == nil {
+		return false
+	}
+
+	switch c.GoType.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice, reflect.Array:
+		k := c.GoType.Elem().Kind()
+		return k == reflect.Uint8 || k == reflect.Int32
+	default:
+		return c.GoType == nullString
+	}
+}
+
+// charset(utf8mb4)
+func (c *Column) setCharset(vals []string) error {
+	if len(vals) != 1 {
+		return propertyError(c.Name, "charset", KindArgs, "只能带一个参数")
+	}
+
+	if !c.IsString() {
+		return propertyError(c.Name, "charset", KindType, "只能作用于字符串类型")
+	}
+
+	c.Charset = vals[0]
+	return nil
+}
+
+// collate(utf8mb4_bin)
+func (c *Column) setCollate(vals []string) error {
+	if len(vals) != 1 {
+		return propertyError(c.Name, "collate", KindArgs, "只能带一个参数")
+	}
+
+	if !c.IsString() {
+		return propertyError(c.Name, "collate", KindType, "只能作用于字符串类型")
+	}
+
+	c.Collate = vals[0]
+	return nil
+}
+
+// type(mediumtext) or type(timestamp)
+//
+// 是否为可用的类型由各个 dialect 决定。
+func (c *Column) setType(vals []string) error {
+	if len(vals) > 0 && strings.IndexByte(vals[0], ':') >= 0 {
+		return c.setDialectTypes(vals)
+	}
+
+	if len(vals) != 1 {
+		return propertyError(c.Name, "type", KindArgs, "只能带一个参数")
+	}
+
+	if !c.IsString() && !c.IsTime() {
+		return propertyError(c.Name, "type", KindType, "只能作用于字符串和时间类型")
+	}
+
+	c.Type = strings.ToLower(vals[0])
+	return nil
+}
+
+// 可以在 type(dialect:type) 中使用的数据库名称
+var (
+	dialectNames = map[string]bool{
+		"mysql":    true,
+		"postgres": true,
+		"sqlite3":  true,
+	}
+	dialectNamesMux sync.RWMutex
+)
+
+// RegisterDialectName 添加可以在 type(dialect:type) 中使用的数据库名称
+//
+// name 与 Dialect.Name() 的返回值相同，不区分大小写。
+// 内置的 mysql、postgres 和 sqlite3 不需要添加，
+// 通过 orm.RegisterDialect 注册的 Dialect 也会自动添加。
+func RegisterDialectName(name string) {
+	dialectNamesMux.Lock()
+	defer dialectNamesMux.Unlock()
+	dialectNames[strings.ToLower(name)] = true
+}
+
+func isDialectName(name string) bool {
+	dialectNamesMux.RLock()
+	defer dialectNamesMux.RUnlock()
+	return dialectNames[name]
+}
+
+// type(mysql:json,sqlite3:text)
+//
+// 逗号和括号会被当作属性的分隔符，
+// 所以无法通过此方式指定 DECIMAL(10,2) 之类带参数的类型。
+func (c *Column) setDialectTypes(vals []string) error {
+	types := make(map[string]string, len(vals))
+	for _, v := range vals {
+		index := strings.IndexByte(v, ':')
+		if index <= 0 || index == len(v)-1 {
+			return propertyError(c.Name, "type", KindValue, "格式必须为 dialect:type")
+		}
+
+		name := strings.ToLower(v[:index])
+		if !isDialectName(name) {
+			return propertyError(c.Name, "type", KindValue, "未知的数据库 "+name)
+		}
+		if _, found := types[name]; found {
+			return propertyError(c.Name, "type", KindDuplicate, "重复的数据库 "+name)
+		}
+		types[name] = v[index+1:]
+	}
+
+	c.DialectTypes = types
+	return nil
+}
+
+// set(read,write,admin)
+func (c *Column) setSet(vals []string) error {
+	if len(vals) == 0 {
+		return propertyError(c.Name, "set", KindArgs, "至少需要一个值")
+	}
+
+	if !c.IsString() {
+		return propertyError(c.Name, "set", KindType, "只能作用于字符串类型")
+	}
+
+	for i, v := range vals {
+		if v == "" {
+			return propertyError(c.Name, "set", KindValue, "不能包含空值")
+		}
+
+		for _, v2 := range vals[i+1:] {
+			if v == v2 {
+				return propertyError(c.Name, "set", KindValue, "存在重复的值 "+v)
+			}
+		}
+	}
+
+	c.Set = vals
+	return nil
+}
+
+// serialize(json)
+func (c *Column) setSerialize(vals []string) error {
+	if len(vals) != 1 {
+		return propertyError(c.Name, "serialize", KindArgs, "只能带一个参数")
+	}
+
+	switch c.GoType.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return propertyError(c.Name, "serialize", KindType, "只能作用于结构体、map 和数组类型")
+	}
+
+	switch v := strings.ToLower(vals[0]); v {
+	case "json", "gob":
+		c.Serialize = v
+	default:
+		return propertyError(c.Name, "serialize", KindValue, "不支持的序列化方式")
+	}
+
+	return nil
+}
+
+// pgarray
+func (c *Column) setPGArray(vals []string) error {
+	if len(vals) != 0 {
+		return propertyError(c.Name, "pgarray", KindArgs, "太多的值")
+	}
+
+	if c.GoType.Kind() != reflect.Slice {
+		return propertyError(c.Name, "pgarray", KindType, "只能作用于切片类型")
+	}
+
+	elem := c.GoType.Elem()
+	switch elem.Kind() {
+	case reflect.Uint8, reflect.Int32:
+		return propertyError(c.Name, "pgarray", KindType, "[]byte 和 []rune 被当作字符串处理")
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		if elem != timeType {
+			return propertyError(c.Name, "pgarray", KindType, "切片元素只能是布尔、数值、字符串或是时间类型")
+		}
+	}
+
+	c.PGArray = true
+	return nil
+}
+
 // 从参数中获取 Column 的 len1 和 len2 变量。
 // len(len1,len2)
+//
+// len(max) 等同于 len(-1)，表示字符串使用最大长度，
+// 即 mysql 中的 LONGTEXT 和 postgres 中的 TEXT 等。
+//
+// 只能作用于字符串、整数、浮点数和时间类型，其中浮点数必须同时指定两个值，
+// 时间类型的长度表示小数秒的精度。
 func (c *Column) setLen(vals []string) (err error) {
+	isFloat := c.isFloat()
+	switch {
+	case isFloat:
+		if len(vals) != 2 {
+			return propertyError(c.Name, "len", KindArgs, "浮点数必须同时指定两个值")
+		}
+	case c.IsString():
+	case c.IsInteger(), c.IsTime():
+		if len(vals) > 1 {
+			return propertyError(c.Name, "len", KindArgs, "只能带一个参数")
+		}
+	default:
+		return propertyError(c.Name, "len", KindType, "只能作用于字符串、整数、浮点数和时间类型")
+	}
+
 	switch len(vals) {
 	case 0:
 	case 1:
-		c.Len1, err = strconv.Atoi(vals[0])
+		if strings.ToLower(vals[0]) == "max" {
+			if !c.IsString() {
+				return propertyError(c.Name, "len", KindValue, "max 只能作用于字符串类型")
+			}
+			c.Len1 = -1
+			return nil
+		}
+		if c.Len1, err = strconv.Atoi(vals[0]); err != nil {
+			return propertyError(c.Name, "len", KindValue, err.Error())
+		}
 	case 2:
 		if c.Len1, err = strconv.Atoi(vals[0]); err != nil {
-			return err
+			return propertyError(c.Name, "len", KindValue, err.Error())
+		}
+
+		if c.Len2, err = strconv.Atoi(vals[1]); err != nil {
+			return propertyError(c.Name, "len", KindValue, err.Error())
+		}
+	default:
+		return propertyError(c.Name, "len", KindArgs, "过多的参数")
+	}
+
+	if c.Len1 < -1 || c.Len2 < -1 {
+		return propertyError(c.Name, "len", KindValue, "长度不能小于 -1")
+	}
+
+	// DECIMAL(2,10) 之类的精度小于小数位数的定义是无效的
+	if isFloat && c.Len2 > c.Len1 {
+		return propertyError(c.Name, "len", KindValue, "小数位数不能大于精度")
+	}
+
+	return nil
+}
+
+// IsInteger 是否为整数类型，包括 sql.NullInt64
+func (c *Column) IsInteger() bool {
+	if c.GoType == nil {
+		return false
+	}
+
+	switch c.GoType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return c.GoType == nullInt64
+	}
+}
+
+// 是否为浮点数类型，包括 sql.NullFloat64
+func (c *Column) isFloat() bool {
+	if c.GoType == nil {
+		return false
+	}
+
+	switch c.GoType.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return c.GoType == nullFloat64
+	}
+}
+
+// IsNumeric 是否为数值类型，即整数或是浮点数，包括 sql.NullInt64 和 sql.NullFloat64
+func (c *Column) IsNumeric() bool {
+	return c.IsInteger() || c.isFloat()
+}
+
+// IsTime 是否为 time.Time 类型
+func (c *Column) IsTime() bool {
+	return c.GoType == timeType
+}
+
+// IsBool 是否为布尔类型，包括 sql.NullBool
+func (c *Column) IsBool() bool {
+	if c.GoType == nil {
+		return false
+	}
+
+	return c.GoType.Kind() == reflect.Bool || c.GoType == nullBool
+}
+
+// unsigned; or unsigned(true);
+func (c *Column) setUnsigned(vals []string) (err error) {
+	if !c.IsInteger() {
+		return propertyError(c.Name, "unsigned", KindType, "只能作用于整数类型")
+	}
+
+	switch len(vals) {
+	case 0:
+		c.Unsigned = true
+	case 1:
+		if c.Unsigned, err = strconv.ParseBool(vals[0]); err != nil {
+			return propertyError(c.Name, "unsigned", KindValue, err.Error())
 		}
+	default:
+		return propertyError(c.Name, "unsigned", KindArgs, "过多的参数值")
+	}
+
+	return nil
+}
+
+// zerofill; or zerofill(true);
+func (c *Column) setZerofill(vals []string) (err error) {
+	if !c.IsInteger() {
+		return propertyError(c.Name, "zerofill", KindType, "只能作用于整数类型")
+	}
+
+	switch len(vals) {
+	case 0:
+		c.Zerofill = true
+	case 1:
+		if c.Zerofill, err = strconv.ParseBool(vals[0]); err != nil {
+			return propertyError(c.Name, "zerofill", KindValue, err.Error())
+		}
+	default:
+		return propertyError(c.Name, "zerofill", KindArgs, "过多的参数值")
+	}
+
+	return nil
+}
 
-		c.Len2, err = strconv.Atoi(vals[1])
+// readonly 或是 readonly(true)
+func (c *Column) setReadonly(vals []string) (err error) {
+	switch len(vals) {
+	case 0:
+		c.Readonly = true
+	case 1:
+		if c.Readonly, err = strconv.ParseBool(vals[0]); err != nil {
+			return propertyError(c.Name, "readonly", KindValue, err.Error())
+		}
 	default:
-		return propertyError(c.Name, "len", "过多的参数")
+		return propertyError(c.Name, "readonly", KindArgs, "过多的参数值")
 	}
 
-	return
+	return nil
+}
+
+// notpk
+func (c *Column) setNotPK(vals []string) error {
+	if len(vals) != 0 {
+		return propertyError(c.Name, "notpk", KindArgs, "太多的值")
+	}
+
+	c.AINotPK = true
+	return nil
 }
 
 // 从 vals 中分析，得出 Column.Nullable 的值。
 // nullable; or nullable(true);
+//
+// 与 ai 和 occ 的冲突由 Model.parseColumn 在所有属性处理完之后再判断。
 func (c *Column) setNullable(vals []string) (err error) {
-	if c.IsAI() {
-		return propertyError(c.Name, "nullable", "自增列不能设置此值")
-	}
-
 	switch len(vals) {
 	case 0:
 		c.Nullable = true
 	case 1:
 		if c.Nullable, err = strconv.ParseBool(vals[0]); err != nil {
-			return err
+			return propertyError(c.Name, "nullable", KindValue, err.Error())
 		}
 	default:
-		return propertyError(c.Name, "nullable", "过多的参数值")
+		return propertyError(c.Name, "nullable", KindArgs, "过多的参数值")
 	}
 
 	return nil
 }
+
+// 列的各个属性的文本描述，用于 Model.String()
+func (c *Column) flags() string {
+	buf := new(bytes.Buffer)
+
+	if c.Len2 != 0 {
+		fmt.Fprintf(buf, " len(%d,%d)", c.Len1, c.Len2)
+	} else if c.Len1 != 0 {
+		fmt.Fprintf(buf, " len(%d)", c.Len1)
+	}
+
+	if c.AutoRandom {
+		buf.WriteString(" autorandom")
+	} else if c.IsAI() {
+		buf.WriteString(" ai")
+		if c.AIStart > 1 || c.AIStep > 1 {
+			fmt.Fprintf(buf, "(%d,%d)", c.AIStart, c.AIStep)
+		}
+		if c.AINotPK {
+			buf.WriteString(" notpk")
+		}
+	}
+
+	if c.Nullable {
+		buf.WriteString(" nullable")
+	}
+
+	if c.HasDefault {
+		fmt.Fprintf(buf, " default(%s)", c.Default)
+	}
+
+	if c.model != nil {
+		switch c {
+		case c.model.OCC:
+			buf.WriteString(" occ")
+		case c.model.Updated:
+			buf.WriteString(" updated")
+		case c.model.SoftDelete:
+			buf.WriteString(" softdelete")
+		}
+	}
+
+	if c.Unsigned {
+		buf.WriteString(" unsigned")
+	}
+
+	if c.Zerofill {
+		buf.WriteString(" zerofill")
+	}
+
+	if c.Type != "" {
+		fmt.Fprintf(buf, " type(%s)", c.Type)
+	}
+
+	if len(c.DialectTypes) > 0 {
+		names := make([]string, 0, len(c.DialectTypes))
+		for name := range c.DialectTypes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		types := make([]string, 0, len(names))
+		for _, name := range names {
+			types = append(types, name+":"+c.DialectTypes[name])
+		}
+		fmt.Fprintf(buf, " type(%s)", strings.Join(types, ","))
+	}
+
+	if c.Charset != "" {
+		fmt.Fprintf(buf, " charset(%s)", c.Charset)
+	}
+
+	if c.Collate != "" {
+		fmt.Fprintf(buf, " collate(%s)", c.Collate)
+	}
+
+	if c.Geometry != "" {
+		fmt.Fprintf(buf, " geometry(%s)", c.Geometry)
+	}
+
+	if c.Serialize != "" {
+		fmt.Fprintf(buf, " serialize(%s)", c.Serialize)
+	}
+
+	if c.PGArray {
+		buf.WriteString(" pgarray")
+	}
+
+	if len(c.Set) > 0 {
+		fmt.Fprintf(buf, " set(%s)", strings.Join(c.Set, ","))
+	}
+
+	if c.Generated != "" {
+		kind := "virtual"
+		if c.GeneratedStored {
+			kind = "stored"
+		}
+		fmt.Fprintf(buf, " generated(%s,%s)", c.Generated, kind)
+	}
+
+	if c.Readonly {
+		buf.WriteString(" readonly")
+	}
+
+	return buf.String()
+}
@@ -5,19 +5,194 @@
 package model
 
 import (
+	"database/sql"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
+	"github.com/issue9/orm/internal/modeltest"
 )
 
+func TestModel_newColumn(t *testing.T) {
+	a := assert.New(t)
+	m := &Model{}
+
+	type obj struct {
+		Int    int
+		PtrInt *int
+		PtrStr *string
+	}
+	rtype := reflect.TypeOf(obj{})
+
+	field, _ := rtype.FieldByName("Int")
+	col := m.newColumn(field)
+	a.False(col.Nullable).Equal(col.GoType, reflect.TypeOf(1))
+
+	field, _ = rtype.FieldByName("PtrInt")
+	col = m.newColumn(field)
+	a.True(col.Nullable).
+		Equal(col.GoType, reflect.TypeOf(1)).
+		Nil(col.Zero)
+
+	// nullable(false) 可以覆盖指针类型的默认值
+	field, _ = rtype.FieldByName("PtrStr")
+	col = m.newColumn(field)
+	a.True(col.Nullable).Equal(col.GoType, reflect.TypeOf(""))
+	a.NotError(col.setNullable([]string{"false"})).False(col.Nullable)
+}
+
 func TestColumn_SetLen(t *testing.T) {
 	a := assert.New(t)
-	col := &Column{}
+	col := &Column{GoType: reflect.TypeOf("")}
 
 	a.NotError(col.setLen([]string{})).Equal(col.Len1, 0).Equal(col.Len2, 0)
 	a.NotError(col.setLen([]string{"1", "2"})).Equal(col.Len1, 1).Equal(col.Len2, 2)
 	a.Error(col.setLen([]string{"1", "2", "3"}))
 	a.Error(col.setLen([]string{"1", "one"}))
+
+	// max
+	a.NotError(col.setLen([]string{"max"})).Equal(col.Len1, -1)
+	a.NotError(col.setLen([]string{"MAX"})).Equal(col.Len1, -1)
+	a.NotError(col.setLen([]string{"-1"})).Equal(col.Len1, -1)
+
+	// 小于 -1
+	a.Error(col.setLen([]string{"-2"}))
+	a.Error(col.setLen([]string{"1", "-2"}))
+}
+
+func TestColumn_Len(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf("")}
+	l1, l2 := col.Len()
+	a.Equal(l1, 0).Equal(l2, 0)
+
+	a.NotError(col.setLen([]string{"max"}))
+	l1, l2 = col.Len()
+	a.Equal(l1, -1).Equal(l2, 0)
+
+	col = &Column{GoType: reflect.TypeOf(float64(0))}
+	a.NotError(col.setLen([]string{"10", "2"}))
+	l1, l2 = col.Len()
+	a.Equal(l1, 10).Equal(l2, 2)
+}
+
+func TestColumn_classifiers(t *testing.T) {
+	a := assert.New(t)
+
+	data := []*struct {
+		v                                     interface{}
+		integer, numeric, str, isTime, isBool bool
+	}{
+		{v: 1, integer: true, numeric: true},
+		{v: uint8(1), integer: true, numeric: true},
+		{v: sql.NullInt64{}, integer: true, numeric: true},
+		{v: 1.5, numeric: true},
+		{v: sql.NullFloat64{}, numeric: true},
+		{v: "", str: true},
+		{v: []byte{}, str: true},
+		{v: []rune{}, str: true},
+		{v: sql.NullString{}, str: true},
+		{v: time.Time{}, isTime: true},
+		{v: true, isBool: true},
+		{v: sql.NullBool{}, isBool: true},
+		{v: struct{}{}},
+	}
+
+	for i, item := range data {
+		col := &Column{GoType: reflect.TypeOf(item.v)}
+		a.Equal(col.IsInteger(), item.integer, "IsInteger 错误 @ %d", i).
+			Equal(col.IsNumeric(), item.numeric, "IsNumeric 错误 @ %d", i).
+			Equal(col.IsString(), item.str, "IsString 错误 @ %d", i).
+			Equal(col.IsTime(), item.isTime, "IsTime 错误 @ %d", i).
+			Equal(col.IsBool(), item.isBool, "IsBool 错误 @ %d", i)
+	}
+
+	// 未指定类型
+	col := &Column{}
+	a.False(col.IsInteger()).False(col.IsNumeric()).False(col.IsString()).
+		False(col.IsTime()).False(col.IsBool())
+}
+
+func TestColumn_SetPGArray(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf([]int64{})}
+	a.NotError(col.setPGArray(nil)).True(col.PGArray)
+	a.Contains(col.flags(), "pgarray")
+
+	col = &Column{GoType: reflect.TypeOf([]string{})}
+	a.NotError(col.setPGArray(nil)).True(col.PGArray)
+
+	col = &Column{GoType: reflect.TypeOf([]time.Time{})}
+	a.NotError(col.setPGArray(nil)).True(col.PGArray)
+
+	// 参数错误
+	col = &Column{GoType: reflect.TypeOf([]int64{})}
+	a.Error(col.setPGArray([]string{"true"})).False(col.PGArray)
+
+	// 非切片
+	col = &Column{GoType: reflect.TypeOf(int64(1))}
+	a.Error(col.setPGArray(nil)).False(col.PGArray)
+	col = &Column{GoType: reflect.TypeOf([2]int64{})}
+	a.Error(col.setPGArray(nil)).False(col.PGArray)
+
+	// []byte 和 []rune
+	col = &Column{GoType: reflect.TypeOf([]byte{})}
+	a.Error(col.setPGArray(nil)).False(col.PGArray)
+	col = &Column{GoType: reflect.TypeOf([]rune{})}
+	a.Error(col.setPGArray(nil)).False(col.PGArray)
+
+	// 非标量元素
+	col = &Column{GoType: reflect.TypeOf([]struct{}{})}
+	a.Error(col.setPGArray(nil)).False(col.PGArray)
+	col = &Column{GoType: reflect.TypeOf([][]int64{})}
+	a.Error(col.setPGArray(nil)).False(col.PGArray)
+}
+
+func TestColumn_SetLen_type(t *testing.T) {
+	a := assert.New(t)
+
+	// 整数
+	col := &Column{GoType: reflect.TypeOf(int64(0))}
+	a.NotError(col.setLen([]string{"11"})).Equal(col.Len1, 11)
+	a.Error(col.setLen([]string{"11", "2"}))
+	a.Error(col.setLen([]string{"max"}))
+	col = &Column{GoType: reflect.TypeOf(sql.NullInt64{})}
+	a.NotError(col.setLen([]string{"11"})).Equal(col.Len1, 11)
+
+	// 浮点数必须同时指定两个值
+	col = &Column{GoType: reflect.TypeOf(float64(0))}
+	a.NotError(col.setLen([]string{"10", "2"})).Equal(col.Len1, 10).Equal(col.Len2, 2)
+	a.Error(col.setLen([]string{"10"}))
+	a.Error(col.setLen([]string{}))
+	a.Error(col.setLen([]string{"2", "10"})) // 小数位数大于精度
+	a.NotError(col.setLen([]string{"10", "10"}))
+	col = &Column{GoType: reflect.TypeOf(sql.NullFloat64{})}
+	a.Error(col.setLen([]string{"10"}))
+
+	// 时间
+	col = &Column{GoType: reflect.TypeOf(time.Time{})}
+	a.NotError(col.setLen([]string{"3"})).Equal(col.Len1, 3)
+	a.Error(col.setLen([]string{"3", "1"}))
+
+	// 字节数组
+	col = &Column{GoType: reflect.TypeOf([]byte{})}
+	a.NotError(col.setLen([]string{"max"})).Equal(col.Len1, -1)
+
+	// 不支持的类型
+	col = &Column{GoType: reflect.TypeOf(true)}
+	a.Error(col.setLen([]string{"10"}))
+	col = &Column{GoType: reflect.TypeOf(map[string]string{})}
+	a.Error(col.setLen([]string{"10"}))
+	col = &Column{GoType: reflect.TypeOf([]int{})}
+	a.Error(col.setLen([]string{"10"}))
+	col = &Column{GoType: reflect.TypeOf(struct{}{})}
+	a.Error(col.setLen([]string{"10"}))
+	col = &Column{}
+	a.Error(col.setLen([]string{"10"}))
 }
 
 func TestColumn_SetNullable(t *testing.T) {
@@ -34,3 +209,192 @@ func TestColumn_SetNullable(t *testing.T) {
 	a.Error(col.setNullable([]string{"1", "2"}))
 	a.Error(col.setNullable([]string{"T1"}))
 }
+
+func TestColumn_SetCharset(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf("")}
+	a.NotError(col.setCharset([]string{"utf8mb4"})).Equal(col.Charset, "utf8mb4")
+	a.NotError(col.setCollate([]string{"utf8mb4_bin"})).Equal(col.Collate, "utf8mb4_bin")
+	a.Error(col.setCharset([]string{}))
+	a.Error(col.setCollate([]string{"1", "2"}))
+
+	col = &Column{GoType: reflect.TypeOf([]byte{})}
+	a.NotError(col.setCharset([]string{"utf8mb4"}))
+
+	col = &Column{GoType: reflect.TypeOf(sql.NullString{})}
+	a.NotError(col.setCollate([]string{"utf8mb4_bin"}))
+
+	// 非字符串类型
+	col = &Column{GoType: reflect.TypeOf(1)}
+	a.Error(col.setCharset([]string{"utf8mb4"}))
+	a.Error(col.setCollate([]string{"utf8mb4_bin"}))
+}
+
+func TestColumn_SetType(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf("")}
+	a.NotError(col.setType([]string{"MediumText"})).Equal(col.Type, "mediumtext")
+	a.Error(col.setType([]string{}))
+	a.Error(col.setType([]string{"text", "longtext"}))
+
+	// 非字符串类型
+	col = &Column{GoType: reflect.TypeOf(1)}
+	a.Error(col.setType([]string{"text"}))
+
+	// 按数据库指定类型，可用于任意类型
+	col = &Column{GoType: reflect.TypeOf(map[string]string{})}
+	a.NotError(col.setType([]string{"mysql:json", "SQLite3:TEXT"}))
+	a.Equal(col.DialectTypes, map[string]string{"mysql": "json", "sqlite3": "TEXT"}).
+		Empty(col.Type)
+	a.Error(col.setType([]string{"mysql:json", "text"}))
+	a.Error(col.setType([]string{"mysql:json", "mysql:text"}))
+	a.Error(col.setType([]string{"mysql:"}))
+	a.Error(col.setType([]string{":json"}))
+
+	// 未知的数据库
+	err := col.setType([]string{"sqlite:text"})
+	var perr *ParseError
+	a.True(errors.As(err, &perr)).Equal(perr.Kind, KindValue)
+	RegisterDialectName("SQLite")
+	a.NotError(col.setType([]string{"sqlite:text"}))
+	a.Equal(col.DialectTypes, map[string]string{"sqlite": "text"})
+}
+
+func TestColumn_SetSerialize(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf(struct{ ID int }{})}
+	a.NotError(col.setSerialize([]string{"JSON"})).Equal(col.Serialize, "json")
+	a.NotError(col.setSerialize([]string{"gob"})).Equal(col.Serialize, "gob")
+	a.Error(col.setSerialize([]string{"xml"}))
+	a.Error(col.setSerialize([]string{}))
+	a.Error(col.setSerialize([]string{"json", "gob"}))
+
+	col = &Column{GoType: reflect.TypeOf(map[string]int{})}
+	a.NotError(col.setSerialize([]string{"json"}))
+
+	// 非结构体
+	col = &Column{GoType: reflect.TypeOf(1)}
+	a.Error(col.setSerialize([]string{"json"}))
+}
+
+func TestColumn_SetSet(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf("")}
+	a.NotError(col.setSet([]string{"read", "write", "admin"}))
+	a.Equal(col.Set, []string{"read", "write", "admin"})
+	a.Error(col.setSet([]string{}))
+	a.Error(col.setSet([]string{"read", ""}))
+	a.Error(col.setSet([]string{"read", "write", "read"}))
+
+	col = &Column{GoType: reflect.TypeOf(sql.NullString{})}
+	a.NotError(col.setSet([]string{"read"}))
+
+	// 非字符串类型
+	col = &Column{GoType: reflect.TypeOf(1)}
+	a.Error(col.setSet([]string{"read"}))
+}
+
+func TestColumn_SetType_time(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf(time.Time{})}
+	a.NotError(col.setType([]string{"TIMESTAMP"})).Equal(col.Type, "timestamp")
+}
+
+func TestColumn_SetDefault(t *testing.T) {
+	Clear()
+	defer Clear() // 修改了缓存中的 Model
+	a := assert.New(t)
+
+	m, err := New(&modeltest.UserInfo{})
+	a.NotError(err).NotNil(m)
+
+	col := m.Cols["firstName"]
+	a.NotError(col.SetDefault("f1"))
+	a.True(col.HasDefault).Equal(col.Default, "f1")
+
+	a.NotError(m.SetColumnDefault("lastName", 5))
+	a.True(m.Cols["lastName"].HasDefault).Equal(m.Cols["lastName"].Default, "5")
+
+	// 主键
+	a.Error(m.SetColumnDefault("uid", 1))
+
+	// 不存在的列
+	a.Error(m.SetColumnDefault("not-exists", 1))
+
+	// 自增列
+	m, err = New(&modeltest.Group{})
+	a.NotError(err).NotNil(m)
+	a.Error(m.Cols["id"].SetDefault(1))
+
+	// 未关联 Model
+	col = &Column{GoType: reflect.TypeOf("")}
+	a.Error(col.SetDefault("abc"))
+}
+
+func TestColumn_IsZero(t *testing.T) {
+	a := assert.New(t)
+	col := &Column{}
+
+	a.True(col.IsZero(reflect.Value{}))
+	a.True(col.IsZero(reflect.ValueOf(0)))
+	a.False(col.IsZero(reflect.ValueOf(5)))
+	a.True(col.IsZero(reflect.ValueOf("")))
+	a.True(col.IsZero(reflect.ValueOf(time.Time{})))
+	a.False(col.IsZero(reflect.ValueOf(time.Now())))
+
+	// 无法直接比较的类型
+	a.True(col.IsZero(reflect.ValueOf([]byte(nil))))
+	a.False(col.IsZero(reflect.ValueOf([]byte("1"))))
+	a.True(col.IsZero(reflect.ValueOf(map[string]int(nil))))
+
+	// sql.NullXX
+	a.True(col.IsZero(reflect.ValueOf(sql.NullString{String: "abc"})))
+	a.False(col.IsZero(reflect.ValueOf(sql.NullString{Valid: true})))
+	a.False(col.IsZero(reflect.ValueOf(sql.NullInt64{Valid: true})))
+
+	// 指针
+	var i *int
+	a.True(col.IsZero(reflect.ValueOf(i)))
+	i = new(int)
+	a.False(col.IsZero(reflect.ValueOf(i)))
+}
+
+func TestColumn_SetZerofill(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf(int32(1))}
+	a.NotError(col.setZerofill(nil)).True(col.Zerofill)
+	a.NotError(col.setZerofill([]string{"false"})).False(col.Zerofill)
+	a.Error(col.setZerofill([]string{"T1"}))
+	a.Error(col.setZerofill([]string{"true", "false"}))
+
+	// 非整数类型
+	col = &Column{GoType: reflect.TypeOf(1.1)}
+	a.Error(col.setZerofill(nil))
+	col = &Column{GoType: reflect.TypeOf("")}
+	a.Error(col.setZerofill(nil))
+}
+
+func TestColumn_SetUnsigned(t *testing.T) {
+	a := assert.New(t)
+
+	col := &Column{GoType: reflect.TypeOf(1)}
+	a.NotError(col.setUnsigned(nil)).True(col.Unsigned)
+	a.NotError(col.setUnsigned([]string{"false"})).False(col.Unsigned)
+	a.Error(col.setUnsigned([]string{"T1"}))
+	a.Error(col.setUnsigned([]string{"true", "false"}))
+
+	col = &Column{GoType: reflect.TypeOf(sql.NullInt64{})}
+	a.NotError(col.setUnsigned(nil)).True(col.Unsigned)
+
+	// 非整数类型
+	col = &Column{GoType: reflect.TypeOf(1.1)}
+	a.Error(col.setUnsigned(nil))
+	col = &Column{GoType: reflect.TypeOf("")}
+	a.Error(col.setUnsigned(nil))
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package model
+
+import "fmt"
+
+// ErrorKind 表示 ParseError 的错误类型
+type ErrorKind int8
+
+// ParseError.Kind 的可用值
+const (
+	KindUnknownAttr ErrorKind = iota + 1 // 未知的属性
+	KindArgs                             // 参数个数不正确
+	KindType                             // 属性不能作用于该字段的类型
+	KindValue                            // 无效的参数值
+	KindConflict                         // 与其它属性或是约束相冲突
+	KindDuplicate                        // 重复的约束名、列名或是重复指定了只能有一个的属性
+)
+
+// ParseError 分析 Model 时产生的错误
+//
+// 可以通过 errors.As 获取该错误，并根据 Kind 判断具体的错误类型。
+type ParseError struct {
+	Field   string // 字段名，若是由 Metaer 接口产生的，则为 Metaer
+	Attr    string // 发生错误的属性名，比如 pk、fk 等
+	Kind    ErrorKind
+	Message string
+}
+
+func propertyError(field, name string, kind ErrorKind, message string) error {
+	return &ParseError{
+		Field:   field,
+		Attr:    name,
+		Kind:    kind,
+		Message: message,
+	}
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s 的 %s 属性发生以下错误: %s", err.Field, err.Attr, err.Message)
+}
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindUnknownAttr:
+		return "unknown attr"
+	case KindArgs:
+		return "args"
+	case KindType:
+		return "type"
+	case KindValue:
+		return "value"
+	case KindConflict:
+		return "conflict"
+	case KindDuplicate:
+		return "duplicate"
+	default:
+		return "<unknown>"
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/issue9/assert"
+)
+
+func TestParseError(t *testing.T) {
+	a := assert.New(t)
+
+	err := propertyError("ID", "pk", KindArgs, "太多的值")
+	a.Equal(err.Error(), "ID 的 pk 属性发生以下错误: 太多的值")
+
+	var perr *ParseError
+	a.True(errors.As(err, &perr))
+	a.Equal(perr.Field, "ID").
+		Equal(perr.Attr, "pk").
+		Equal(perr.Kind, KindArgs).
+		Equal(perr.Message, "太多的值")
+}
+
+func TestNew_ParseError(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	kind := func(obj interface{}) ErrorKind {
+		m, err := New(obj)
+		a.Error(err).Nil(m)
+
+		var perr *ParseError
+		a.True(errors.As(err, &perr))
+		return perr.Kind
+	}
+
+	type unknownAttr struct {
+		ID int64 `orm:"name(id);unknown"`
+	}
+	a.Equal(kind(&unknownAttr{}), KindUnknownAttr)
+
+	type args struct {
+		ID int64 `orm:"name(id);pk(1)"`
+	}
+	a.Equal(kind(&args{}), KindArgs)
+
+	type typ struct {
+		Name string `orm:"name(name);len(20);unsigned"`
+	}
+	a.Equal(kind(&typ{}), KindType)
+
+	type value struct {
+		Name string `orm:"name(name);len(20);geometry(abc)"`
+	}
+	a.Equal(kind(&value{}), KindValue)
+
+	// 无法解析的参数值
+	type lenValue struct {
+		Name string `orm:"name(name);len(abc)"`
+	}
+	a.Equal(kind(&lenValue{}), KindValue)
+
+	type len2Value struct {
+		Price float64 `orm:"name(price);len(5,abc)"`
+	}
+	a.Equal(kind(&len2Value{}), KindValue)
+
+	type nullableValue struct {
+		Name string `orm:"name(name);len(20);nullable(abc)"`
+	}
+	a.Equal(kind(&nullableValue{}), KindValue)
+
+	type unsignedValue struct {
+		Age int64 `orm:"name(age);unsigned(abc)"`
+	}
+	a.Equal(kind(&unsignedValue{}), KindValue)
+
+	type readonlyValue struct {
+		Age int64 `orm:"name(age);readonly(abc)"`
+	}
+	a.Equal(kind(&readonlyValue{}), KindValue)
+
+	type zerofillValue struct {
+		Age int64 `orm:"name(age);zerofill(abc)"`
+	}
+	a.Equal(kind(&zerofillValue{}), KindValue)
+
+	type occValue struct {
+		Version int64 `orm:"name(version);occ(abc)"`
+	}
+	a.Equal(kind(&occValue{}), KindValue)
+
+	type conflict struct {
+		ID int64 `orm:"name(id);ai;nullable"`
+	}
+	a.Equal(kind(&conflict{}), KindConflict)
+
+	type duplicate struct {
+		ID   int64 `orm:"name(id);unique(u_id)"`
+		Name int64 `orm:"name(name);index(u_id)"`
+	}
+	a.Equal(kind(&duplicate{}), KindDuplicate)
+
+	type dupColumn struct {
+		ID   int64 `orm:"name(id)"`
+		Name int64 `orm:"name(id)"`
+	}
+	a.Equal(kind(&dupColumn{}), KindDuplicate)
+}
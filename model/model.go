@@ -6,101 +6,94 @@
 package model
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"unicode"
 
-	"github.com/issue9/orm/fetch"
 	"github.com/issue9/orm/internal/tags"
 )
 
-// model 缓存
-var models = &modelsMap{items: map[reflect.Type]*Model{}}
-
-type modelsMap struct {
-	sync.Mutex
-	items map[reflect.Type]*Model
-}
-
 // Model 表示一个数据库的表模型。数据结构从字段和字段的 struct tag 中分析得出。
 type Model struct {
-	Name          string                 // 表的名称
-	Cols          map[string]*Column     // 所有的列
-	KeyIndexes    map[string][]*Column   // 索引列
-	UniqueIndexes map[string][]*Column   // 唯一索引列
-	FK            map[string]*ForeignKey // 外键
-	PK            []*Column              // 主键
-	AI            *Column                // 自增列
-	OCC           *Column                // 乐观锁
-	Check         map[string]string      // Check 键名为约束名，键值为约束表达式
-	Meta          map[string][]string    // 表级别的数据，如存储引擎，表名和字符集等。
+	Name          string                        // 表的名称
+	Schema        string                        // 表所在的 schema，mysql 中表示数据库名，为空表示默认值
+	Cols          map[string]*Column            // 所有的列
+	ColsOrder     []*Column                     // 所有的列，按结构体中字段的声明顺序排列
+	KeyIndexes    map[string][]*Column          // 索引列
+	IndexCollates map[string]map[*Column]string // 索引列的排序规则，键名分别为索引名和列，仅部分数据库支持
+	UniqueIndexes map[string][]*Column          // 唯一索引列
+	UniqueConds   map[string]string             // 唯一索引的条件，键名为约束名，仅部分数据库支持
+	FK            map[string]*ForeignKey        // 外键
+	PK            []*Column                     // 主键
+	AI            *Column                       // 自增列
+	OCC           *Column                       // 乐观锁
+	Updated       *Column                       // 在更新时自动设置为当前时间的列
+	SoftDelete    *Column                       // 软删除标记列，可以是 time.Time 或是 bool
+	Check         map[string]string             // Check 键名为约束名，键值为约束表达式
+	Meta          map[string][]string           // 表级别的数据，如存储引擎，表名和字符集等。
+	Partition     *Partition                    // 表分区，仅部分数据库支持
 
 	constraints map[string]conType // 约束名缓存
-}
 
-func propertyError(field, name, message string) error {
-	return fmt.Errorf("%s 的 %s 属性发生以下错误: %s", field, name, message)
+	caseInsensitive bool            // 检测列名是否重复时是否不区分大小写
+	strictIndex     bool            // index 和 unique 的名称是否以严格模式检测
+	naming          NamingStrategy  // 未指定 name 属性时的命名策略
+	excludes        map[string]bool // 需要忽略的字段，键值表示该字段是否已经被匹配
 }
 
 // New 从一个 obj 声明一个 Model 实例。
 // obj 可以是一个 struct 实例或是指针。
+//
+// 返回的 Model 会被缓存在默认的 Cache 中。
 func New(obj interface{}) (*Model, error) {
-	rval := reflect.ValueOf(obj)
-	for rval.Kind() == reflect.Ptr {
-		rval = rval.Elem()
-	}
-	rtype := rval.Type()
-
-	if rtype.Kind() != reflect.Struct {
-		return nil, fetch.ErrInvalidKind
-	}
-
-	models.Lock()
-	defer models.Unlock()
-
-	if m, found := models.items[rtype]; found {
-		return m, nil
-	}
-
-	m := &Model{
-		Cols:          map[string]*Column{},
-		KeyIndexes:    map[string][]*Column{},
-		UniqueIndexes: map[string][]*Column{},
-		Name:          rtype.Name(),
-		FK:            map[string]*ForeignKey{},
-		Check:         map[string]string{},
-		Meta:          map[string][]string{},
-		constraints:   map[string]conType{},
-	}
-
-	if err := m.parseColumns(rval); err != nil {
-		return nil, err
-	}
+	return defaultCache.New(obj)
+}
 
-	if err := m.parseMeta(obj); err != nil {
-		return nil, err
-	}
+// NewExcluding 从一个 obj 声明一个 Model 实例，并忽略 fields 中指定的字段。
+//
+// 返回的 Model 会被缓存在默认的 Cache 中，具体说明可参考 Cache.NewExcluding。
+func NewExcluding(obj interface{}, fields ...string) (*Model, error) {
+	return defaultCache.NewExcluding(obj, fields...)
+}
 
-	models.items[rtype] = m
-	return m, nil
+// NewUncached 以默认 Cache 的规则从 obj 声明一个不会被缓存的 Model 实例。
+//
+// 具体说明可参考 Cache.NewUncached。
+func NewUncached(obj interface{}) (*Model, error) {
+	return defaultCache.NewUncached(obj)
 }
 
-// 将 rval 中的结构解析到 m 中。支持匿名字段
-func (m *Model) parseColumns(rval reflect.Value) error {
-	rtype := rval.Type()
+// 将 rtype 中的结构解析到 m 中。支持匿名字段
+//
+// prefix 为列名前缀，goPrefix 为字段名前缀，
+// 仅在解析通过 prefix 属性嵌入的结构体时才不为空。
+func (m *Model) parseColumns(rtype reflect.Type, prefix, goPrefix string) error {
 	num := rtype.NumField()
 	for i := 0; i < num; i++ {
 		field := rtype.Field(i)
 
 		if field.Anonymous {
-			m.parseColumns(rval.Field(i))
+			if unicode.IsLower(rune(field.Name[0])) { // 忽略以小写字母开头的匿名字段
+				continue
+			}
+
+			if m.exclude(goPrefix + field.Name) {
+				continue
+			}
+
+			// 匿名字段的字段可以直接通过 FieldByName 访问，所以 goPrefix 不变。
+			if err := m.parseColumns(field.Type, prefix, goPrefix); err != nil {
+				return err
+			}
 			continue
 		}
 
-		if err := m.parseColumn(field); err != nil {
+		if err := m.parseColumn(field, prefix, goPrefix); err != nil {
 			return err
 		}
 	}
@@ -108,22 +101,36 @@ func (m *Model) parseColumns(rval reflect.Value) error {
 	return nil
 }
 
+// 字段 name 是否需要被忽略，同时标记该字段已经被匹配。
+func (m *Model) exclude(name string) bool {
+	if _, found := m.excludes[name]; !found {
+		return false
+	}
+	m.excludes[name] = true
+	return true
+}
+
 // 分析一个字段。
-func (m *Model) parseColumn(field reflect.StructField) (err error) {
+func (m *Model) parseColumn(field reflect.StructField, prefix, goPrefix string) (err error) {
 	if unicode.IsLower(rune(field.Name[0])) { // 忽略以小写字母开头的字段
 		return nil
 	}
 
 	tagTxt := field.Tag.Get("orm")
-	if tagTxt == "-" {
+	if tagTxt == "-" || m.exclude(goPrefix+field.Name) {
 		return nil
 	}
 
+	if tags.Has(tagTxt, "prefix") {
+		return m.parsePrefix(field, tagTxt, prefix, goPrefix)
+	}
+
 	col := m.newColumn(field)
+	col.Name = prefix + col.Name
+	col.GoName = goPrefix + col.GoName
 
 	if len(tagTxt) == 0 { // 没有附加的 struct tag，直接取得几个关键信息返回。
-		m.Cols[col.Name] = col
-		return nil
+		return m.addColumn(col)
 	}
 
 	tags := tags.Parse(tagTxt)
@@ -131,43 +138,188 @@ func (m *Model) parseColumn(field reflect.StructField) (err error) {
 		switch k {
 		case "name": // name(colname)
 			if len(v) != 1 {
-				return propertyError(col.Name, "name", "过多的参数值")
+				return propertyError(col.Name, "name", KindArgs, "过多的参数值")
 			}
-			col.Name = v[0]
+			col.Name = prefix + v[0]
 		case "index":
-			err = m.setIndex(col, v)
+			err = m.setIndex(col, prefixConstraint(prefix, v))
 		case "pk":
 			err = m.setPK(col, v)
 		case "unique":
-			err = m.setUnique(col, v)
+			err = m.setUnique(col, prefixConstraint(prefix, v))
 		case "nullable":
 			err = col.setNullable(v)
 		case "ai":
 			err = m.setAI(col, v)
+		case "autorandom":
+			err = m.setAutoRandom(col, v)
+		case "notpk":
+			err = col.setNotPK(v)
 		case "len":
 			err = col.setLen(v)
 		case "fk":
-			err = m.setFK(col, v)
+			err = m.setFK(col, prefixConstraint(prefix, v))
 		case "default":
 			err = m.setDefault(col, v)
 		case "occ":
 			err = m.setOCC(col, v)
+		case "charset":
+			err = col.setCharset(v)
+		case "collate":
+			err = col.setCollate(v)
+		case "updated":
+			err = m.setUpdated(col, v)
+		case "softdelete":
+			err = m.setSoftDelete(col, v)
+		case "unsigned":
+			err = col.setUnsigned(v)
+		case "zerofill":
+			err = col.setZerofill(v)
+		case "readonly":
+			err = col.setReadonly(v)
+		case "geometry":
+			err = m.setGeometry(col, v)
+		case "generated":
+			err = m.setGenerated(col, v)
+		case "check":
+			err = m.setCheck(col.Name, v)
+		case "type":
+			err = col.setType(v)
+		case "serialize":
+			err = col.setSerialize(v)
+		case "set":
+			err = col.setSet(v)
+		case "pgarray":
+			err = col.setPGArray(v)
 		default:
-			err = propertyError(col.Name, k, "未知的属性")
+			err = propertyError(col.Name, k, KindUnknownAttr, "未知的属性")
 		}
 
 		if err != nil {
 			return err
 		}
 	}
+	// tags 的顺序是不固定的，readonly(false) 可能在 generated 之后才被处理。
+	if col.Generated != "" {
+		col.Readonly = true
+	}
+
+	// 指针类型的列默认可以为 NULL，而 nullable(false) 可能在 ai 和 occ 之后才被处理，
+	// 所以自增列和乐观锁列是否可以为 NULL 也需要在最后判断。
+	if col.Nullable {
+		switch {
+		case m.AI == col && col.AutoRandom:
+			return propertyError(col.Name, "autorandom", KindConflict, "不能与 nullable 并存")
+		case m.AI == col:
+			return propertyError(col.Name, "ai", KindConflict, "不能与 nullable 并存")
+		case m.OCC == col:
+			return propertyError(col.Name, "occ", KindConflict, "允许为空的列不能作为乐观锁列")
+		}
+	}
+
+	// 时间类型的软删除列以 NULL 表示未删除，同样需要在最后判断 nullable。
+	if m.SoftDelete == col && col.IsTime() && !col.Nullable {
+		return propertyError(col.Name, "softdelete", KindConflict, "time.Time 类型的软删除列必须可以为 NULL")
+	}
+
+	// 同理，notpk 可能在 ai 之后才被处理，所以主键也要在最后再决定。
+	if col.AINotPK {
+		if m.AI != col || col.AutoRandom {
+			return propertyError(col.Name, "notpk", KindConflict, "只能与 ai 一起使用")
+		}
+		for _, c := range m.PK {
+			if c == col {
+				return propertyError(col.Name, "notpk", KindConflict, "不能与 pk 并存")
+			}
+		}
+	} else if m.AI == col && !col.AutoRandom {
+		// 去掉其它主键，将自增列设置为主键
+		m.PK = append(m.PK[:0], col)
+	}
+
 	// col.Name 可能在上面的 for 循环中被更改，所以要在最后再添加到 m.Cols 中
-	m.Cols[col.Name] = col
+	return m.addColumn(col)
+}
+
+// 为 index、unique 和 fk 属性中的约束名 vals[0] 加上前缀 prefix，
+// 否则多次以 prefix 嵌入同一结构体时，这些约束名会重复。
+func prefixConstraint(prefix string, vals []string) []string {
+	if prefix == "" || len(vals) == 0 {
+		return vals
+	}
+
+	ret := make([]string, len(vals))
+	copy(ret, vals)
+	ret[0] = prefix + ret[0]
+	return ret
+}
+
+// 分析带 prefix 属性的字段，将其结构体中的字段以 prefix(billing_) 指定的前缀展开到 m 中。
+//
+// 该字段只能是结构体类型，且除了 prefix 之外不能再有其它属性。
+// 展开后的列对应的 GoName 以点号分隔各级字段名，比如 Billing.Street。
+func (m *Model) parsePrefix(field reflect.StructField, tagTxt, prefix, goPrefix string) error {
+	ts := tags.Parse(tagTxt)
+	if len(ts) != 1 {
+		return propertyError(field.Name, "prefix", KindConflict, "不能与其它属性同时使用")
+	}
+
+	vals := ts["prefix"]
+	if len(vals) != 1 || vals[0] == "" {
+		return propertyError(field.Name, "prefix", KindArgs, "只能带一个非空的参数")
+	}
+
+	if field.Type.Kind() != reflect.Struct || field.Type == timeType ||
+		field.Type.PkgPath() == "database/sql" {
+		return propertyError(field.Name, "prefix", KindType, "只能作用于结构体类型")
+	}
+
+	return m.parseColumns(field.Type, prefix+vals[0], goPrefix+field.Name+".")
+}
+
+// 将 col 添加到 m.Cols 中，若已经存在同名的列，则返回错误信息。
+func (m *Model) addColumn(col *Column) error {
+	if c, found := m.FindColumn(col.Name); found {
+		msg := fmt.Sprintf("与 %s 的列名 %s 相同", c.GoName, c.Name)
+		return propertyError(col.GoName, "name", KindDuplicate, msg)
+	}
 
+	m.Cols[col.Name] = col
+	m.ColsOrder = append(m.ColsOrder, col)
 	return nil
 }
 
+// FindColumn 查找与 name 同名的列
+//
+// 若通过 Cache.SetCaseInsensitive 指定了不区分大小写，则查找时也不区分大小写。
+func (m *Model) FindColumn(name string) (*Column, bool) {
+	if c, found := m.Cols[name]; found {
+		return c, true
+	}
+
+	if m.caseInsensitive {
+		for n, c := range m.Cols {
+			if strings.EqualFold(n, name) {
+				return c, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
 // 分析 meta 接口数据。
+//
+// 同时会分析 TableNamer 接口，其指定的表名会被 Metaer 中的 name 属性覆盖。
 func (m *Model) parseMeta(obj interface{}) error {
+	if namer, ok := obj.(TableNamer); ok {
+		name := namer.TableName()
+		if name == "" {
+			return propertyError("TableNamer", "name", KindValue, "不能为空")
+		}
+		m.Name = name
+	}
+
 	meta, ok := obj.(Metaer)
 	if !ok {
 		return nil
@@ -178,52 +330,125 @@ func (m *Model) parseMeta(obj interface{}) error {
 		return nil
 	}
 
+	var partition, partitionValues []string
 	for k, v := range tags {
 		switch k {
 		case "name":
 			if len(v) != 1 {
-				return propertyError("Metaer", "name", "太多的值")
+				return propertyError("Metaer", "name", KindArgs, "太多的值")
 			}
 
 			m.Name = v[0]
-		case "check":
-			if len(v) != 2 {
-				return propertyError("Metaer", "check", "参数个数不正确")
+		case "schema":
+			if len(v) != 1 || v[0] == "" {
+				return propertyError("Metaer", "schema", KindArgs, "只能带一个参数")
 			}
 
-			if _, found := m.Check[v[0]]; found {
-				return propertyError("Metaer", "check", "已经存在相同名称的 check 约束")
+			m.Schema = v[0]
+		case "partition":
+			partition = v
+		case "partition_values":
+			partitionValues = v
+		case "check":
+			if err := m.setCheck("Metaer", v); err != nil {
+				return err
 			}
-
-			if typ := m.hasConstraint(v[0], check); typ != none {
-				return propertyError("Metaer", "check", "与其它约束名称相同")
+		case "engine", "charset":
+			if len(v) != 1 {
+				return propertyError("Metaer", k, KindArgs, "只能带一个参数")
 			}
 
-			m.constraints[v[0]] = check
-			m.Check[v[0]] = v[1]
+			m.Meta[k] = v
 		default:
 			m.Meta[k] = v
 		}
 	}
 
+	if partition != nil || partitionValues != nil {
+		return m.setPartition(partition, partitionValues)
+	}
+
+	return nil
+}
+
+// partition(range,col);partition_values(p2017:2018-01-01,pmax:MAXVALUE)
+func (m *Model) setPartition(partition, values []string) error {
+	if len(partition) != 2 {
+		return propertyError("Metaer", "partition", KindArgs, "参数个数不正确")
+	}
+
+	typ := strings.ToLower(partition[0])
+	if typ != "range" {
+		return propertyError("Metaer", "partition", KindValue, "仅支持 range 类型的分区")
+	}
+
+	col, found := m.Cols[partition[1]]
+	if !found {
+		return propertyError("Metaer", "partition", KindValue, "不存在的列 "+partition[1])
+	}
+
+	if len(values) == 0 {
+		return propertyError("Metaer", "partition_values", KindArgs, "未指定分区")
+	}
+
+	p := &Partition{
+		Type:   typ,
+		Column: col,
+		Values: make([]PartitionValue, 0, len(values)),
+	}
+	names := make(map[string]bool, len(values))
+	for _, v := range values {
+		index := strings.IndexByte(v, ':')
+		if index <= 0 || index == len(v)-1 {
+			return propertyError("Metaer", "partition_values", KindValue, "格式必须为 name:value")
+		}
+
+		name := v[:index]
+		if names[name] {
+			return propertyError("Metaer", "partition_values", KindDuplicate, "重复的分区名 "+name)
+		}
+		names[name] = true
+
+		p.Values = append(p.Values, PartitionValue{Name: name, LessThan: v[index+1:]})
+	}
+
+	m.Partition = p
+	return nil
+}
+
+// check(name,expr)
+//
+// 可同时用于 Metaer 和列，field 表示出错时的字段名称。
+func (m *Model) setCheck(field string, vals []string) error {
+	if len(vals) != 2 {
+		return propertyError(field, "check", KindArgs, "参数个数不正确")
+	}
+
+	if _, found := m.Check[vals[0]]; found {
+		return propertyError(field, "check", KindDuplicate, "已经存在相同名称的 check 约束")
+	}
+
+	if typ := m.hasConstraint(vals[0], check); typ != none {
+		return propertyError(field, "check", KindDuplicate, "与其它约束名称相同")
+	}
+
+	m.constraints[vals[0]] = check
+	m.Check[vals[0]] = vals[1]
 	return nil
 }
 
 // occ(true) or occ
 func (m *Model) setOCC(c *Column, vals []string) error {
-	if c.IsAI() || c.Nullable {
-		return propertyError(c.Name, "occ", "自增列和允许为空的列不能作为乐观锁列")
+	if c.IsAI() || c.Geometry != "" {
+		return propertyError(c.Name, "occ", KindConflict, "自增列和空间数据类型不能作为乐观锁列")
 	}
 
 	if m.OCC != nil {
-		return propertyError(c.Name, "occ", "已经指定了一个乐观锁")
+		return propertyError(c.Name, "occ", KindDuplicate, "已经指定了一个乐观锁")
 	}
 
-	switch c.GoType.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-	default:
-		return propertyError(c.Name, "occ", "值只能是数值")
+	if !c.IsInteger() {
+		return propertyError(c.Name, "occ", KindType, "值只能是数值")
 	}
 
 	switch len(vals) {
@@ -232,32 +457,178 @@ func (m *Model) setOCC(c *Column, vals []string) error {
 	case 1:
 		val, err := strconv.ParseBool(vals[0])
 		if err != nil {
-			return err
+			return propertyError(c.Name, "occ", KindValue, err.Error())
 		}
 		if val {
 			m.OCC = c
 		}
 	default:
-		return propertyError(c.Name, "occ", "指定了太多的值")
+		return propertyError(c.Name, "occ", KindArgs, "指定了太多的值")
+	}
+
+	return nil
+}
+
+// updated
+func (m *Model) setUpdated(col *Column, vals []string) error {
+	if len(vals) != 0 {
+		return propertyError(col.Name, "updated", KindArgs, "太多的值")
+	}
+
+	if m.Updated != nil {
+		return propertyError(col.Name, "updated", KindDuplicate, "已经指定了一个自动更新时间的列")
+	}
+
+	if !col.IsTime() {
+		return propertyError(col.Name, "updated", KindType, "类型只能是 time.Time")
+	}
+
+	m.Updated = col
+	return nil
+}
+
+// softdelete
+func (m *Model) setSoftDelete(col *Column, vals []string) error {
+	if len(vals) != 0 {
+		return propertyError(col.Name, "softdelete", KindArgs, "太多的值")
+	}
+
+	if m.SoftDelete != nil {
+		return propertyError(col.Name, "softdelete", KindDuplicate, "已经指定了一个软删除列")
+	}
+
+	if !col.IsTime() && col.GoType.Kind() != reflect.Bool {
+		return propertyError(col.Name, "softdelete", KindType, "类型只能是 time.Time 或是 bool")
+	}
+
+	m.SoftDelete = col
+	return nil
+}
+
+// IsSoftDelete 是否为软删除
+//
+// 软删除的表，删除操作仅是设置 SoftDelete 列的值，
+// 查询时需要自行过滤已经被删除的记录，比如 time.Time 类型的 WHERE deleted_at IS NULL。
+func (m *Model) IsSoftDelete() bool {
+	return m.SoftDelete != nil
+}
+
+// WritableColumns 返回可以写入数据的列
+//
+// 即过滤掉自增列和只读列之后的列，按列名排序。
+func (m *Model) WritableColumns() []*Column {
+	cols := make([]*Column, 0, len(m.Cols))
+	for _, col := range m.Columns() {
+		if col.IsAI() || col.Readonly {
+			continue
+		}
+		cols = append(cols, col)
+	}
+
+	return cols
+}
+
+// HasAutoIncrement 是否包含自增列
+func (m *Model) HasAutoIncrement() bool {
+	return m.AI != nil
+}
+
+// HasOCC 是否包含乐观锁列
+func (m *Model) HasOCC() bool {
+	return m.OCC != nil
+}
+
+// OCCColumn 返回乐观锁列，第二个返回值表示是否存在该列。
+func (m *Model) OCCColumn() (*Column, bool) {
+	return m.OCC, m.OCC != nil
+}
+
+// 支持的空间数据类型
+var geometryTypes = []string{
+	"geometry",
+	"point",
+	"linestring",
+	"polygon",
+	"multipoint",
+	"multilinestring",
+	"multipolygon",
+	"geometrycollection",
+}
+
+// geometry(point)
+func (m *Model) setGeometry(col *Column, vals []string) error {
+	if len(vals) != 1 {
+		return propertyError(col.Name, "geometry", KindArgs, "只能带一个参数")
+	}
+
+	if col.IsAI() || col.HasDefault || m.OCC == col {
+		return propertyError(col.Name, "geometry", KindConflict, "空间数据类型不能是自增列、乐观锁或是带默认值")
+	}
+
+	typ := strings.ToLower(vals[0])
+	for _, t := range geometryTypes {
+		if t == typ {
+			col.Geometry = typ
+			return nil
+		}
 	}
 
+	return propertyError(col.Name, "geometry", KindValue, "不支持的空间数据类型")
+}
+
+// generated(expr) 或是 generated(expr,stored)
+func (m *Model) setGenerated(col *Column, vals []string) error {
+	if len(vals) == 0 || len(vals) > 2 {
+		return propertyError(col.Name, "generated", KindArgs, "参数个数不正确")
+	}
+
+	if col.IsAI() || col.HasDefault {
+		return propertyError(col.Name, "generated", KindConflict, "生成列不能是自增列或是带默认值")
+	}
+
+	if vals[0] == "" {
+		return propertyError(col.Name, "generated", KindValue, "表达式不能为空")
+	}
+
+	stored := false
+	if len(vals) == 2 {
+		switch strings.ToLower(vals[1]) {
+		case "stored":
+			stored = true
+		case "virtual":
+		default:
+			return propertyError(col.Name, "generated", KindValue, "只能是 stored 或是 virtual")
+		}
+	}
+
+	col.Generated = vals[0]
+	col.GeneratedStored = stored
+	col.Readonly = true
 	return nil
 }
 
 // default(5)
 func (m *Model) setDefault(col *Column, vals []string) error {
 	if m.AI == col {
-		return propertyError(col.Name, "default", "自增列不能设置默认值")
+		return propertyError(col.Name, "default", KindConflict, "自增列不能设置默认值")
+	}
+
+	if col.Generated != "" {
+		return propertyError(col.Name, "default", KindConflict, "生成列不能设置默认值")
+	}
+
+	if col.Geometry != "" {
+		return propertyError(col.Name, "default", KindConflict, "空间数据类型不能设置默认值")
 	}
 
 	for _, c := range m.PK {
 		if c == col {
-			return propertyError(col.Name, "default", "不能为主键设置默认值")
+			return propertyError(col.Name, "default", KindConflict, "不能为主键设置默认值")
 		}
 	}
 
 	if len(vals) != 1 {
-		return propertyError(col.Name, "default", "太多的值")
+		return propertyError(col.Name, "default", KindArgs, "太多的值")
 	}
 
 	col.HasDefault = true
@@ -267,13 +638,30 @@ func (m *Model) setDefault(col *Column, vals []string) error {
 }
 
 // index(idx_name)
+// index(idx_name,collate:utf8mb4_general_ci)
 func (m *Model) setIndex(col *Column, vals []string) error {
-	if len(vals) != 1 {
-		return propertyError(col.Name, "index", "太多的值")
+	if len(vals) != 1 && len(vals) != 2 {
+		return propertyError(col.Name, "index", KindArgs, "参数个数不正确")
 	}
 
 	if typ := m.hasConstraint(vals[0], index); typ != none {
-		return propertyError(col.Name, "index", "已经存在相同的约束名")
+		return propertyError(col.Name, "index", KindDuplicate, duplicateConstraintMsg(typ))
+	}
+
+	if len(vals) == 2 {
+		if !strings.HasPrefix(vals[1], "collate:") {
+			return propertyError(col.Name, "index", KindValue, "排序规则必须以 collate: 开头")
+		}
+
+		collate := strings.TrimSpace(strings.TrimPrefix(vals[1], "collate:"))
+		if collate == "" {
+			return propertyError(col.Name, "index", KindValue, "排序规则不能为空")
+		}
+
+		if m.IndexCollates[vals[0]] == nil {
+			m.IndexCollates[vals[0]] = map[*Column]string{}
+		}
+		m.IndexCollates[vals[0]][col] = collate
 	}
 
 	m.constraints[vals[0]] = index
@@ -284,15 +672,15 @@ func (m *Model) setIndex(col *Column, vals []string) error {
 // pk
 func (m *Model) setPK(col *Column, vals []string) error {
 	if col.HasDefault {
-		return propertyError(col.Name, "pk", "不能将一个含有默认值的列设置为主键")
+		return propertyError(col.Name, "pk", KindConflict, "不能将一个含有默认值的列设置为主键")
 	}
 
 	if len(vals) != 0 {
-		return propertyError(col.Name, "pk", "太多的值")
+		return propertyError(col.Name, "pk", KindArgs, "太多的值")
 	}
 
-	if m.AI != nil {
-		return propertyError(col.Name, "pk", "已经存在自增列，不需要再次指定主键")
+	if m.AI != nil && !m.AI.AINotPK {
+		return propertyError(col.Name, "pk", KindConflict, "已经存在自增列，不需要再次指定主键")
 	}
 
 	m.PK = append(m.PK, col)
@@ -300,13 +688,37 @@ func (m *Model) setPK(col *Column, vals []string) error {
 }
 
 // unique(unique_name)
+// unique(name) or unique(name,where:deleted_at IS NULL)
+//
+// 逗号和括号是属性的分隔符，条件中包含这些字符时会被拆分成多个参数，
+// 无法还原出原来的条件，所以直接返回错误。
 func (m *Model) setUnique(col *Column, vals []string) error {
-	if len(vals) != 1 {
-		return propertyError(col.Name, "unique", "只能带一个参数")
+	if len(vals) > 2 && strings.HasPrefix(vals[1], "where:") {
+		return propertyError(col.Name, "unique", KindValue, "条件中不能包含逗号和括号")
+	}
+
+	if len(vals) != 1 && len(vals) != 2 {
+		return propertyError(col.Name, "unique", KindArgs, "参数个数不正确")
 	}
 
 	if typ := m.hasConstraint(vals[0], unique); typ != none {
-		return propertyError(col.Name, "unique", "已经存在相同的约束名")
+		return propertyError(col.Name, "unique", KindDuplicate, duplicateConstraintMsg(typ))
+	}
+
+	if len(vals) == 2 {
+		if !strings.HasPrefix(vals[1], "where:") {
+			return propertyError(col.Name, "unique", KindValue, "条件必须以 where: 开头")
+		}
+
+		cond := strings.TrimSpace(strings.TrimPrefix(vals[1], "where:"))
+		if cond == "" {
+			return propertyError(col.Name, "unique", KindValue, "条件不能为空")
+		}
+
+		if c, found := m.UniqueConds[vals[0]]; found && c != cond {
+			return propertyError(col.Name, "unique", KindConflict, "同一唯一约束的条件不同")
+		}
+		m.UniqueConds[vals[0]] = cond
 	}
 
 	m.constraints[vals[0]] = unique
@@ -318,63 +730,397 @@ func (m *Model) setUnique(col *Column, vals []string) error {
 // fk(fk_name,refTable,refColName,updateRule,deleteRule)
 func (m *Model) setFK(col *Column, vals []string) error {
 	if len(vals) < 3 {
-		return propertyError(col.Name, "fk", "参数不够")
+		return propertyError(col.Name, "fk", KindArgs, "参数不够")
 	}
 
 	if typ := m.hasConstraint(vals[0], fk); typ != none {
-		return propertyError(col.Name, "fk", "已经存在相同的约束名")
-	}
-
-	if _, found := m.FK[vals[0]]; found {
-		return propertyError(col.Name, "fk", "重复的外键约束名")
-	}
-
-	fkInst := &ForeignKey{
-		Col:          col,
-		RefTableName: vals[1],
-		RefColName:   vals[2],
+		return propertyError(col.Name, "fk", KindDuplicate, "已经存在相同的约束名")
 	}
 
+	var updateRule, deleteRule string
+	var err error
 	if len(vals) > 3 { // 存在updateRule
-		fkInst.UpdateRule = vals[3]
+		if updateRule, err = fkRule(col, vals[3]); err != nil {
+			return err
+		}
 	}
 	if len(vals) > 4 { // 存在deleteRule
-		fkInst.DeleteRule = vals[4]
+		if deleteRule, err = fkRule(col, vals[4]); err != nil {
+			return err
+		}
+	}
+
+	// 相同约束名的多个列组成复合外键
+	if fkInst, found := m.FK[vals[0]]; found {
+		if fkInst.RefTableName != vals[1] {
+			return propertyError(col.Name, "fk", KindConflict, "复合外键引用的表名不同")
+		}
+
+		if (updateRule != "" && updateRule != fkInst.UpdateRule) ||
+			(deleteRule != "" && deleteRule != fkInst.DeleteRule) {
+			return propertyError(col.Name, "fk", KindConflict, "复合外键的更新或删除规则不同")
+		}
+
+		fkInst.Cols = append(fkInst.Cols, col)
+		fkInst.RefColNames = append(fkInst.RefColNames, vals[2])
+		return nil
 	}
 
 	m.constraints[vals[0]] = fk
-	m.FK[vals[0]] = fkInst
+	m.FK[vals[0]] = &ForeignKey{
+		Cols:         []*Column{col},
+		RefTableName: vals[1],
+		RefColNames:  []string{vals[2]},
+		UpdateRule:   updateRule,
+		DeleteRule:   deleteRule,
+	}
 	return nil
 }
 
-// ai(colName,start,step)
-func (m *Model) setAI(col *Column, vals []string) (err error) {
-	if col.HasDefault {
-		return propertyError(col.Name, "ai", "不能将一个含有默认值的列设置为自增")
+// 外键允许的更新和删除规则
+var fkRules = []string{"CASCADE", "SET NULL", "RESTRICT", "NO ACTION", "SET DEFAULT"}
+
+// 检测外键规则 rule 是否合法，不区分大小写，返回的是大写形式的规则。
+// 空值表示未指定，采用数据库的默认值。
+func fkRule(col *Column, rule string) (string, error) {
+	if rule == "" {
+		return "", nil
 	}
 
-	if len(vals) != 0 {
-		return propertyError(col.Name, "ai", "太多的值")
+	r := strings.ToUpper(strings.Join(strings.Fields(rule), " "))
+	for _, v := range fkRules {
+		if v == r {
+			return r, nil
+		}
 	}
 
-	if col.Nullable {
-		return propertyError(col.Name, "ai", "不能与 nullable 并存")
+	return "", propertyError(col.Name, "fk", KindValue, "无效的规则 "+rule)
+}
+
+// ai or ai(start) or ai(start,step)
+func (m *Model) setAI(col *Column, vals []string) (err error) {
+	if col.AutoRandom {
+		return propertyError(col.Name, "ai", KindConflict, "不能与 autorandom 并存")
 	}
 
-	switch col.GoType.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	if err = m.checkAI(col, "ai"); err != nil {
+		return err
+	}
+
+	start, step := int64(1), int64(1)
+	switch len(vals) {
+	case 0:
+	case 2:
+		if step, err = strconv.ParseInt(vals[1], 10, 64); err != nil || step < 1 {
+			return propertyError(col.Name, "ai", KindValue, "步长必须为大于 0 的整数")
+		}
+		fallthrough
+	case 1:
+		if start, err = strconv.ParseInt(vals[0], 10, 64); err != nil || start < 1 {
+			return propertyError(col.Name, "ai", KindValue, "起始值必须为大于 0 的整数")
+		}
 	default:
-		return propertyError(col.Name, "ai", "类型只能是数值")
+		return propertyError(col.Name, "ai", KindArgs, "太多的值")
 	}
 
+	col.AIStart = start
+	col.AIStep = step
 	m.AI = col
+	return nil
+}
 
-	// 去掉其它主键，将自增列设置为主键
+// autorandom
+//
+// 作为一种特殊的自增列，同样保存在 Model.AI 中，仅 AutoRandom 的值不同。
+func (m *Model) setAutoRandom(col *Column, vals []string) (err error) {
+	if m.AI == col {
+		return propertyError(col.Name, "autorandom", KindConflict, "不能与 ai 并存")
+	}
+
+	if len(vals) != 0 {
+		return propertyError(col.Name, "autorandom", KindArgs, "太多的值")
+	}
+
+	if err = m.checkAI(col, "autorandom"); err != nil {
+		return err
+	}
+
+	col.AutoRandom = true
+	m.AI = col
 	m.PK = append(m.PK[:0], col)
 	return nil
 }
 
+// 检测 col 是否可以作为自增列，attr 为属性名称。
+func (m *Model) checkAI(col *Column, attr string) error {
+	if col.HasDefault {
+		return propertyError(col.Name, attr, KindConflict, "不能将一个含有默认值的列设置为自增")
+	}
+
+	if col.Geometry != "" {
+		return propertyError(col.Name, attr, KindConflict, "空间数据类型不能作为自增列")
+	}
+
+	if col.Generated != "" {
+		return propertyError(col.Name, attr, KindConflict, "生成列不能作为自增列")
+	}
+
+	if !col.IsInteger() {
+		return propertyError(col.Name, attr, KindType, "类型只能是数值")
+	}
+
+	return nil
+}
+
+// AddColumn 添加一个列
+//
+// 仅添加列本身，索引和约束等需要调用者自行处理。
+// 若已经存在同名的列，则返回错误信息。
+func (m *Model) AddColumn(col *Column) error {
+	if col.Name == "" || col.GoType == nil {
+		return errors.New("列名和 GoType 都不能为空")
+	}
+
+	if err := m.addColumn(col); err != nil {
+		return err
+	}
+
+	col.model = m
+	return nil
+}
+
+// RemoveColumn 删除名称为 name 的列
+//
+// 同时会删除与该列相关的索引、唯一约束、外键和主键等信息，
+// 若索引或是唯一约束中已经没有其它列，则该约束也会被删除；
+// 包含该列的外键会被整个删除，以该列分区的表也会去掉分区信息。
+// check 约束为表达式，无法判断是否与该列相关，所以不作处理。
+func (m *Model) RemoveColumn(name string) error {
+	col, found := m.Cols[name]
+	if !found {
+		return fmt.Errorf("%s 中不存在列 %s", m.Name, name)
+	}
+
+	delete(m.Cols, name)
+	m.ColsOrder = removeColumn(m.ColsOrder, col)
+	m.removeIndexesColumn(m.KeyIndexes, col)
+	m.removeIndexesColumn(m.UniqueIndexes, col)
+
+	for name, fk := range m.FK { // 复合外键去掉其中一列之后不再有效，直接删除整个外键
+		for _, c := range fk.Cols {
+			if c == col {
+				delete(m.FK, name)
+				delete(m.constraints, name)
+				break
+			}
+		}
+	}
+
+	m.PK = removeColumn(m.PK, col)
+
+	if m.AI == col {
+		m.AI = nil
+	}
+
+	if m.OCC == col {
+		m.OCC = nil
+	}
+
+	if m.Partition != nil && m.Partition.Column == col { // 分区依据的列被删除之后，分区不再有效
+		m.Partition = nil
+	}
+
+	if m.Updated == col {
+		m.Updated = nil
+	}
+
+	if m.SoftDelete == col {
+		m.SoftDelete = nil
+	}
+
+	col.model = nil
+	return nil
+}
+
+// 从 indexes 中删除 col，若某一索引已经没有列了，则删除该索引。
+func (m *Model) removeIndexesColumn(indexes map[string][]*Column, col *Column) {
+	for name, cols := range indexes {
+		cols = removeColumn(cols, col)
+		if len(cols) > 0 {
+			indexes[name] = cols
+			continue
+		}
+
+		delete(indexes, name)
+		delete(m.constraints, name)
+		delete(m.UniqueConds, name)
+		delete(m.IndexCollates, name)
+	}
+
+	for _, collates := range m.IndexCollates {
+		delete(collates, col)
+	}
+}
+
+// 返回从 cols 中去掉 col 之后的新数组
+func removeColumn(cols []*Column, col *Column) []*Column {
+	ret := make([]*Column, 0, len(cols))
+	for _, c := range cols {
+		if c != col {
+			ret = append(ret, c)
+		}
+	}
+
+	return ret
+}
+
+// SetColumnDefault 为名称为 colName 的列指定默认值
+//
+// 具体规则可参考 Column.SetDefault。
+func (m *Model) SetColumnDefault(colName string, v interface{}) error {
+	col, found := m.Cols[colName]
+	if !found {
+		return fmt.Errorf("%s 中不存在列 %s", m.Name, colName)
+	}
+
+	return col.SetDefault(v)
+}
+
+// Engine 返回 Metaer 中指定的存储引擎
+//
+// 第二个返回值表示是否有指定该值。
+func (m *Model) Engine() (string, bool) {
+	return m.metaValue("engine")
+}
+
+// Charset 返回 Metaer 中指定的字符集
+//
+// 第二个返回值表示是否有指定该值。
+func (m *Model) Charset() (string, bool) {
+	return m.metaValue("charset")
+}
+
+// 获取 Meta 中仅有一个值的属性，parseMeta 中已经保证了其值的个数。
+func (m *Model) metaValue(name string) (string, bool) {
+	if vals := m.Meta[name]; len(vals) == 1 {
+		return vals[0], true
+	}
+
+	return "", false
+}
+
+// PKColumns 返回可以唯一确定一条记录的列。
+//
+// 若存在主键(包括自增列)，则返回主键列；
+// 否则返回按约束名排序之后的第一个唯一约束的列；都不存在，则返回 nil。
+func (m *Model) PKColumns() []*Column {
+	if len(m.PK) > 0 {
+		return m.PK
+	}
+
+	if len(m.UniqueIndexes) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(m.UniqueIndexes))
+	for name := range m.UniqueIndexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return m.UniqueIndexes[names[0]]
+}
+
+// IndexColumns 返回名为 name 的普通索引所包含的列
+//
+// 第二个返回值表示该索引是否存在，name 不区分大小写。
+func (m *Model) IndexColumns(name string) ([]*Column, bool) {
+	return findIndex(m.KeyIndexes, name)
+}
+
+// UniqueColumns 返回名为 name 的唯一约束所包含的列
+//
+// 第二个返回值表示该约束是否存在，name 不区分大小写。
+func (m *Model) UniqueColumns(name string) ([]*Column, bool) {
+	return findIndex(m.UniqueIndexes, name)
+}
+
+// ForeignKeyByColumn 返回包含列 colName 的外键
+//
+// 复合外键中只要包含该列即可。若该列同时属于多个外键，
+// 则返回约束名排序之后的第一个。第二个返回值表示是否找到。
+func (m *Model) ForeignKeyByColumn(colName string) (*ForeignKey, bool) {
+	names := make([]string, 0, len(m.FK))
+	for name := range m.FK {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fk := m.FK[name]
+		for _, col := range fk.Cols {
+			if col.Name == colName {
+				return fk, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func findIndex(indexes map[string][]*Column, name string) ([]*Column, bool) {
+	if cols, found := indexes[name]; found {
+		return cols, true
+	}
+
+	// 约束名不区分大小写
+	for n, cols := range indexes {
+		if strings.EqualFold(n, name) {
+			return cols, true
+		}
+	}
+
+	return nil, false
+}
+
+// FullName 返回在 SQL 中引用该表时使用的名称
+//
+// 表名带有 # 表名前缀并以 {} 包含，指定了 Schema 时，还会加上 schema，
+// 比如 {#user} 或是 {schema}.{#user}，执行时会被替换成当前数据库的引号和表名前缀。
+// 建表语句以及由 Model 生成的增删改查语句都通过此方法引用表名。
+func (m *Model) FullName() string {
+	if m.Schema == "" {
+		return "{#" + m.Name + "}"
+	}
+	return "{" + m.Schema + "}.{#" + m.Name + "}"
+}
+
+// PKName 返回主键的约束名，为表名加上 pk 后缀
+//
+// postgres 等约束名需要在 schema 中唯一的数据库使用该名称，
+// 其它数据库的主键约束名仅在表内有效，由各个 Dialect 自行决定。
+func (m *Model) PKName() string {
+	return m.Name + "pk"
+}
+
+// ConstraintNames 返回所有的约束名及其对应的约束类型。
+//
+// 键名为约束名，键值为约束类型，可以是 index, unique, fk, check 和 pk，
+// 其中主键的约束名为 PKName() 的返回值，仅在存在主键时才会包含。
+// 返回的是一个副本，对其修改并不会影响 Model 本身。
+func (m *Model) ConstraintNames() map[string]string {
+	ret := make(map[string]string, len(m.constraints)+1)
+	for name, typ := range m.constraints {
+		ret[name] = typ.name()
+	}
+
+	if len(m.PK) > 0 {
+		ret[m.PKName()] = ConstraintPK
+	}
+
+	return ret
+}
+
 // 是否存在指定名称的约束名，name 不区分大小写。
 // 若已经存在返回表示该约束类型的常量，否则返回 none。
 func (m *Model) hasConstraint(name string, except conType) conType {
@@ -383,13 +1129,225 @@ func (m *Model) hasConstraint(name string, except conType) conType {
 		return typ
 	}
 
+	// 严格模式下，index 和 unique 的名称以不区分大小写的方式比较。
+	// 仅大小写不同的同类型约束也被当作重复，否则会生成两个在数据库中同名的索引；
+	// 完全相同的同类型约束则表示由多列组成的同一约束。
+	if m.strictIndex && (except == index || except == unique) {
+		for n, typ := range m.constraints {
+			if (typ == index || typ == unique) && strings.EqualFold(n, name) && (n != name || typ != except) {
+				return typ
+			}
+		}
+	}
+
 	return none
 }
 
-// Clear 清除所有的 Model 缓存。
+// 约束名重复时的错误信息
+func duplicateConstraintMsg(typ conType) string {
+	switch typ {
+	case index:
+		return "已经存在同名的索引"
+	case unique:
+		return "已经存在同名的唯一约束"
+	default:
+		return "已经存在相同的约束名"
+	}
+}
+
+// CheckConstraintNames 检测多个 Model 之间是否存在相同的约束名。
+//
+// 部分数据库(比如 postgresql 和 sqlite3 的索引)要求约束名在整个数据库中是唯一的，
+// 可以通过此函数在创建表之前检测。约束名不区分大小写。
+// 若未指定 ms，则检测默认 Cache 中所有已经缓存的 Model。
+// 返回的错误信息中包含了所有冲突的约束名及其所在的表名。
+func CheckConstraintNames(ms ...*Model) error {
+	return defaultCache.CheckConstraintNames(ms...)
+}
+
+// 检测 ms 之间是否存在相同的约束名
+func checkConstraintNames(ms []*Model) error {
+	ms = append(make([]*Model, 0, len(ms)), ms...) // 排序不应该影响到调用者的数据
+
+	// 同名的 Model 表示同一张表，比如 NewExcluding 生成的不同实例，不作为冲突处理。
+	// 保证每次返回的错误信息是相同的
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name })
+
+	owners := make(map[string]*Model, 10) // 约束名与其所在的 Model
+	conflicts := make([]string, 0, 10)
+	for _, m := range ms {
+		names := make([]string, 0, len(m.constraints))
+		for name := range m.constraints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if owner, found := owners[key]; found && owner != m && owner.Name != m.Name {
+				conflicts = append(conflicts, fmt.Sprintf("%s 同时存在于 %s 和 %s", name, owner.Name, m.Name))
+				continue
+			}
+			owners[key] = m
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("存在重复的约束名: %s", strings.Join(conflicts, ";"))
+	}
+
+	return nil
+}
+
+// Clear 清除默认 Cache 中的所有 Model 缓存。
 func Clear() {
-	models.Lock()
-	defer models.Unlock()
+	defaultCache.Clear()
+}
+
+// SetCaching 设置默认 Cache 是否缓存解析之后的 Model
+//
+// 具体说明可参考 Cache.SetCaching。
+func SetCaching(enabled bool) {
+	defaultCache.SetCaching(enabled)
+}
+
+// SetStrictIndexNames 设置默认 Cache 是否以严格模式检测索引名和唯一约束名
+//
+// 具体说明可参考 Cache.SetStrictIndexNames。
+func SetStrictIndexNames(v bool) {
+	defaultCache.SetStrictIndexNames(v)
+}
+
+// SetNamingStrategy 设置默认 Cache 的命名策略
+//
+// 具体说明可参考 Cache.SetNamingStrategy。
+func SetNamingStrategy(ns NamingStrategy) {
+	defaultCache.SetNamingStrategy(ns)
+}
+
+// SetCaseInsensitive 设置默认 Cache 检测列名是否重复时是否不区分大小写
+//
+// 具体说明可参考 Cache.SetCaseInsensitive。
+func SetCaseInsensitive(v bool) {
+	defaultCache.SetCaseInsensitive(v)
+}
+
+// String 返回 Model 的文本描述
+//
+// 仅用于调试和日志等需要查看表结构的地方，并不是 SQL 语句。
+// 列、索引和约束都按名称排序，保证相同的 Model 总是返回相同的内容。
+func (m *Model) String() string {
+	buf := new(bytes.Buffer)
+	if m.Schema != "" {
+		fmt.Fprintf(buf, "table %s.%s\n", m.Schema, m.Name)
+	} else {
+		fmt.Fprintf(buf, "table %s\n", m.Name)
+	}
+
+	buf.WriteString("columns:\n")
+	for _, col := range m.Columns() {
+		fmt.Fprintf(buf, "  %s %s%s\n", col.Name, col.GoType, col.flags())
+	}
+
+	if len(m.PK) > 0 {
+		fmt.Fprintf(buf, "pk: %s\n", columnNames(m.PK))
+	}
+
+	for _, name := range sortedKeys(m.KeyIndexes) {
+		fmt.Fprintf(buf, "index %s: %s", name, columnNames(m.KeyIndexes[name]))
+		if collates := m.IndexCollates[name]; len(collates) > 0 {
+			for _, col := range m.KeyIndexes[name] {
+				if collate, found := collates[col]; found {
+					fmt.Fprintf(buf, " collate %s:%s", col.Name, collate)
+				}
+			}
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, name := range sortedKeys(m.UniqueIndexes) {
+		fmt.Fprintf(buf, "unique %s: %s", name, columnNames(m.UniqueIndexes[name]))
+		if cond, found := m.UniqueConds[name]; found {
+			fmt.Fprintf(buf, " where %s", cond)
+		}
+		buf.WriteByte('\n')
+	}
+
+	fks := make([]string, 0, len(m.FK))
+	for name := range m.FK {
+		fks = append(fks, name)
+	}
+	sort.Strings(fks)
+	for _, name := range fks {
+		fk := m.FK[name]
+		fmt.Fprintf(buf, "fk %s: %s references %s(%s)", name, columnNames(fk.Cols), fk.RefTableName, strings.Join(fk.RefColNames, ","))
+		if fk.UpdateRule != "" {
+			fmt.Fprintf(buf, " on update %s", fk.UpdateRule)
+		}
+		if fk.DeleteRule != "" {
+			fmt.Fprintf(buf, " on delete %s", fk.DeleteRule)
+		}
+		buf.WriteByte('\n')
+	}
+
+	checks := make([]string, 0, len(m.Check))
+	for name := range m.Check {
+		checks = append(checks, name)
+	}
+	sort.Strings(checks)
+	for _, name := range checks {
+		fmt.Fprintf(buf, "check %s: %s\n", name, m.Check[name])
+	}
+
+	metas := make([]string, 0, len(m.Meta))
+	for name := range m.Meta {
+		metas = append(metas, name)
+	}
+	sort.Strings(metas)
+	for _, name := range metas {
+		fmt.Fprintf(buf, "meta %s: %s\n", name, strings.Join(m.Meta[name], ","))
+	}
+
+	if p := m.Partition; p != nil {
+		fmt.Fprintf(buf, "partition %s(%s):", p.Type, p.Column.Name)
+		for _, v := range p.Values {
+			fmt.Fprintf(buf, " %s<%s", v.Name, v.LessThan)
+		}
+		buf.WriteByte('\n')
+	}
+
+	return buf.String()
+}
+
+// Columns 返回按列名排序之后的所有列，每次调用返回的顺序都是固定的。
+//
+// 返回值是一个新的切片，调用者可以随意修改。
+// 若需要按结构体中字段的声明顺序，可以直接使用 ColsOrder。
+func (m *Model) Columns() []*Column {
+	cols := make([]*Column, 0, len(m.Cols))
+	for _, col := range m.Cols {
+		cols = append(cols, col)
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].Name < cols[j].Name })
+
+	return cols
+}
+
+func sortedKeys(indexes map[string][]*Column) []string {
+	keys := make([]string, 0, len(indexes))
+	for name := range indexes {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func columnNames(cols []*Column) string {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, col.Name)
+	}
 
-	models.items = map[reflect.Type]*Model{}
+	return strings.Join(names, ",")
 }
@@ -5,7 +5,12 @@
 package model
 
 import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm/internal/modeltest"
@@ -15,27 +20,27 @@ func TestModels(t *testing.T) {
 	a := assert.New(t)
 
 	Clear()
-	a.Equal(0, len(models.items))
+	a.Equal(0, len(defaultCache.items))
 
 	m, err := New(&modeltest.User{})
 	a.NotError(err).
 		NotNil(m).
-		Equal(1, len(models.items))
+		Equal(1, len(defaultCache.items))
 
 	// 相同的 model 实例，不会增加数量
 	m, err = New(&modeltest.User{})
 	a.NotError(err).
 		NotNil(m).
-		Equal(1, len(models.items))
+		Equal(1, len(defaultCache.items))
 
 	// 添加新的 model
 	m, err = New(&modeltest.Admin{})
 	a.NotError(err).
 		NotNil(m).
-		Equal(2, len(models.items))
+		Equal(2, len(defaultCache.items))
 
 	Clear()
-	a.Equal(0, len(models.items))
+	a.Equal(0, len(defaultCache.items))
 }
 
 // 传递给 NewModel 是一个指针时的各种情况
@@ -76,9 +81,9 @@ func TestModel(t *testing.T) {
 
 	fk, found := m.FK["fk_name"]
 	a.True(found).
-		Equal(fk.Col, groupCol).
+		Equal(fk.Cols, []*Column{groupCol}).
 		Equal(fk.RefTableName, "#groups").
-		Equal(fk.RefColName, "id").
+		Equal(fk.RefColNames, []string{"id"}).
 		Equal(fk.UpdateRule, "NO ACTION").
 		Equal(fk.DeleteRule, "")
 
@@ -95,3 +100,1242 @@ func TestModel(t *testing.T) {
 	// Meta返回的name属性
 	a.Equal(m.Name, "administrators")
 }
+
+func TestModel_ConstraintNames(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&modeltest.Admin{})
+	a.NotError(err).NotNil(m)
+
+	names := m.ConstraintNames()
+	a.Equal(names, map[string]string{
+		"unique_username":  "unique",
+		"index_name":       "index",
+		"unique_email":     "unique",
+		"fk_name":          "fk",
+		"chk_name":         "check",
+		"administratorspk": "pk",
+	})
+
+	// 返回的是副本
+	names["unique_email"] = "index"
+	delete(names, "fk_name")
+	a.Equal(m.constraints["unique_email"], unique)
+	a.Equal(m.constraints["fk_name"], fk)
+}
+
+type metaEngine struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (m *metaEngine) Meta() string {
+	return "engine(innodb,myisam)"
+}
+
+func TestModel_Engine_Charset(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&modeltest.User{})
+	a.NotError(err).NotNil(m)
+	engine, found := m.Engine()
+	a.True(found).Equal(engine, "innodb")
+	charset, found := m.Charset()
+	a.True(found).Equal(charset, "utf-8")
+
+	// 未指定
+	m, err = New(&modeltest.Group{})
+	a.NotError(err).NotNil(m)
+	engine, found = m.Engine()
+	a.False(found).Empty(engine)
+	_, found = m.Charset()
+	a.False(found)
+
+	// 多个值
+	m, err = New(&metaEngine{})
+	a.Error(err).Nil(m)
+}
+
+type tableNamer struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (t *tableNamer) TableName() string {
+	return "namers"
+}
+
+type tableNamerMeta struct {
+	tableNamer
+	Name string `orm:"name(name);len(20)"`
+}
+
+func (t *tableNamerMeta) Meta() string {
+	return "name(metas)"
+}
+
+type tableNamerEmpty struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (t *tableNamerEmpty) TableName() string {
+	return ""
+}
+
+func TestModel_TableNamer(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&tableNamer{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "namers")
+
+	// Metaer 中的 name 优先
+	m, err = New(&tableNamerMeta{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "metas")
+
+	m, err = New(&tableNamerEmpty{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_PKColumns(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	// 自增列
+	m, err := New(&modeltest.Admin{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.PKColumns(), []*Column{m.Cols["id"]})
+
+	// 主键
+	m, err = New(&modeltest.UserInfo{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.PKColumns(), []*Column{m.Cols["uid"]})
+
+	// 唯一约束
+	type unique struct {
+		Email     string `orm:"name(email);unique(u_email)"`
+		FirstName string `orm:"name(first);unique(u_name)"`
+		LastName  string `orm:"name(last);unique(u_name)"`
+	}
+	m, err = New(&unique{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.PKColumns(), []*Column{m.Cols["email"]})
+
+	// 都不存在
+	type noKey struct {
+		Name string `orm:"name(name)"`
+	}
+	m, err = New(&noKey{})
+	a.NotError(err).NotNil(m)
+	a.Nil(m.PKColumns())
+}
+
+func TestModel_IndexColumns_UniqueColumns(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&modeltest.User{})
+	a.NotError(err).NotNil(m)
+
+	cols, found := m.IndexColumns("index_name")
+	a.True(found).Equal(cols, []*Column{m.Cols["Username"]})
+	cols, found = m.IndexColumns("INDEX_NAME")
+	a.True(found).Equal(cols, []*Column{m.Cols["Username"]})
+	cols, found = m.IndexColumns("unique_username") // 唯一约束不是普通索引
+	a.False(found).Nil(cols)
+
+	cols, found = m.UniqueColumns("unique_username")
+	a.True(found).Equal(cols, []*Column{m.Cols["Username"]})
+	cols, found = m.UniqueColumns("not-exists")
+	a.False(found).Nil(cols)
+}
+
+type schemaObj struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (s *schemaObj) Meta() string {
+	return "name(events);schema(analytics)"
+}
+
+type schemaInvalid struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (s *schemaInvalid) Meta() string {
+	return "schema(analytics,other)"
+}
+
+func TestModel_schema(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&schemaObj{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Schema, "analytics").
+		Equal(m.Name, "events").
+		Empty(m.Meta["schema"])
+	a.True(strings.HasPrefix(m.String(), "table analytics.events\n"))
+
+	// 未指定
+	m, err = New(&modeltest.User{})
+	a.NotError(err).NotNil(m)
+	a.Empty(m.Schema)
+
+	m, err = New(&schemaInvalid{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_setIndex_collate(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type indexCollate struct {
+		Name  string `orm:"name(name);len(20);index(index_name,collate:utf8mb4_general_ci)"`
+		Email string `orm:"name(email);len(20);index(index_name)"`
+	}
+	m, err := New(&indexCollate{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.IndexCollates, map[string]map[*Column]string{
+		"index_name": {m.Cols["name"]: "utf8mb4_general_ci"},
+	})
+	a.Equal(len(m.KeyIndexes["index_name"]), 2)
+	a.True(strings.Contains(m.String(), "index index_name: name,email collate name:utf8mb4_general_ci\n"), m.String())
+
+	// 删除列
+	a.NotError(m.RemoveColumn("name"))
+	a.Empty(m.IndexCollates["index_name"])
+
+	type indexCollatePrefix struct {
+		Name string `orm:"name(name);len(20);index(index_name,utf8mb4_general_ci)"`
+	}
+	m, err = New(&indexCollatePrefix{})
+	a.Error(err).Nil(m)
+
+	type indexCollateEmpty struct {
+		Name string `orm:"name(name);len(20);index(index_name,collate:)"`
+	}
+	m, err = New(&indexCollateEmpty{})
+	a.Error(err).Nil(m)
+}
+
+type partitionObj struct {
+	ID      int64     `orm:"name(id);pk"`
+	Created time.Time `orm:"name(created);pk"`
+}
+
+func (p *partitionObj) Meta() string {
+	return "name(events);partition(range,created);partition_values(p2017:2018-01-01,pmax:MAXVALUE)"
+}
+
+type partitionNoColumn struct {
+	ID int64 `orm:"name(id);ai"`
+}
+
+func (p *partitionNoColumn) Meta() string {
+	return "partition(range,created);partition_values(p2017:2018-01-01)"
+}
+
+type partitionNoValues struct {
+	Created time.Time `orm:"name(created)"`
+}
+
+func (p *partitionNoValues) Meta() string {
+	return "partition(range,created)"
+}
+
+type partitionInvalidValue struct {
+	Created time.Time `orm:"name(created)"`
+}
+
+func (p *partitionInvalidValue) Meta() string {
+	return "partition(range,created);partition_values(p2017)"
+}
+
+type partitionType struct {
+	Created time.Time `orm:"name(created)"`
+}
+
+func (p *partitionType) Meta() string {
+	return "partition(hash,created);partition_values(p2017:2018-01-01)"
+}
+
+func TestModel_setPartition(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&partitionObj{})
+	a.NotError(err).NotNil(m)
+	a.NotNil(m.Partition).
+		Equal(m.Partition.Type, "range").
+		True(m.Partition.Column == m.Cols["created"]).
+		Equal(m.Partition.Values, []PartitionValue{
+			{Name: "p2017", LessThan: "2018-01-01"},
+			{Name: "pmax", LessThan: "MAXVALUE"},
+		})
+	a.Empty(m.Meta["partition"]).Empty(m.Meta["partition_values"])
+	a.True(strings.Contains(m.String(), "partition range(created): p2017<2018-01-01 pmax<MAXVALUE\n"), m.String())
+
+	// 删除分区列
+	a.NotError(m.RemoveColumn("created"))
+	a.Nil(m.Partition)
+
+	for _, obj := range []interface{}{&partitionNoColumn{}, &partitionNoValues{}, &partitionInvalidValue{}, &partitionType{}} {
+		m, err = New(obj)
+		a.Error(err).Nil(m)
+	}
+}
+
+func TestModel_ForeignKeyByColumn(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type fkByColumn struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName)"`
+		Last  string `orm:"name(last);len(20);fk(fk_name,#user_info,lastName)"`
+		GID   int64  `orm:"name(gid);fk(fk_gid,#groups,id)"`
+		Name  string `orm:"name(name);len(20)"`
+	}
+	m, err := New(&fkByColumn{})
+	a.NotError(err).NotNil(m)
+
+	fk, found := m.ForeignKeyByColumn("first")
+	a.True(found).True(fk == m.FK["fk_name"])
+	fk, found = m.ForeignKeyByColumn("last") // 复合外键中的列
+	a.True(found).True(fk == m.FK["fk_name"])
+	fk, found = m.ForeignKeyByColumn("gid")
+	a.True(found).True(fk == m.FK["fk_gid"])
+
+	fk, found = m.ForeignKeyByColumn("name")
+	a.False(found).Nil(fk)
+	fk, found = m.ForeignKeyByColumn("not-exists")
+	a.False(found).Nil(fk)
+}
+
+func TestModel_ColsOrder(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&modeltest.Admin{})
+	a.NotError(err).NotNil(m)
+
+	names := make([]string, 0, len(m.ColsOrder))
+	for _, col := range m.ColsOrder {
+		names = append(names, col.Name)
+	}
+	a.Equal(names, []string{"id", "Username", "password", "email", "group"})
+}
+
+func TestModel_Columns(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&modeltest.Admin{})
+	a.NotError(err).NotNil(m)
+
+	cols := m.Columns()
+	a.Equal(len(cols), len(m.Cols))
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		a.Equal(m.Cols[col.Name], col)
+		names = append(names, col.Name)
+	}
+	a.Equal(names, []string{"Username", "email", "group", "id", "password"})
+
+	// 返回值为新的切片，修改不影响 Model
+	cols[0] = nil
+	a.NotNil(m.Columns()[0])
+}
+
+func TestModel_AddColumn_RemoveColumn(t *testing.T) {
+	Clear()
+	defer Clear() // 修改了缓存中的 Model
+	a := assert.New(t)
+
+	m, err := New(&modeltest.Admin{})
+	a.NotError(err).NotNil(m)
+
+	// AddColumn
+	col := &Column{Name: "nickname", GoType: reflect.TypeOf(""), Len1: 20}
+	a.NotError(m.AddColumn(col))
+	a.Equal(m.Cols["nickname"], col)
+	a.Equal(m.ColsOrder[len(m.ColsOrder)-1], col)
+	a.Error(m.AddColumn(&Column{Name: "nickname", GoType: reflect.TypeOf("")}))
+	a.Error(m.AddColumn(&Column{Name: "", GoType: reflect.TypeOf("")}))
+	a.Error(m.AddColumn(&Column{Name: "name"}))
+
+	// 删除唯一约束与索引中的列
+	a.NotError(m.RemoveColumn("Username"))
+	a.Nil(m.Cols["Username"])
+	a.Equal(len(m.ColsOrder), len(m.Cols))
+	a.Nil(m.UniqueIndexes["unique_username"])
+	a.Nil(m.KeyIndexes["index_name"])
+	a.Empty(m.ConstraintNames()["unique_username"])
+	a.Empty(m.ConstraintNames()["index_name"])
+	a.NotNil(m.UniqueIndexes["unique_email"])
+
+	// 删除外键
+	a.NotError(m.RemoveColumn("group"))
+	a.Empty(m.FK)
+	a.Empty(m.ConstraintNames()["fk_name"])
+
+	// 删除自增列
+	a.NotError(m.RemoveColumn("id"))
+	a.Nil(m.AI).Empty(m.PK)
+
+	// 不存在的列
+	a.Error(m.RemoveColumn("not-exists"))
+}
+
+func TestModel_setFK(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	// 复合外键
+	type compositeFK struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName,NO ACTION)"`
+		Last  string `orm:"name(last);len(20);fk(fk_name,#user_info,lastName)"`
+	}
+	m, err := New(&compositeFK{})
+	a.NotError(err).NotNil(m)
+	fk := m.FK["fk_name"]
+	a.NotNil(fk).
+		Equal(fk.Cols, []*Column{m.Cols["first"], m.Cols["last"]}).
+		Equal(fk.RefColNames, []string{"firstName", "lastName"}).
+		Equal(fk.RefTableName, "#user_info").
+		Equal(fk.UpdateRule, "NO ACTION")
+
+	// 引用的表名不同
+	type compositeFKTable struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName)"`
+		Last  string `orm:"name(last);len(20);fk(fk_name,#users,lastName)"`
+	}
+	m, err = New(&compositeFKTable{})
+	a.Error(err).Nil(m)
+
+	// 规则不同
+	type compositeFKRule struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName,CASCADE)"`
+		Last  string `orm:"name(last);len(20);fk(fk_name,#user_info,lastName,NO ACTION)"`
+	}
+	m, err = New(&compositeFKRule{})
+	a.Error(err).Nil(m)
+
+	// 规则不区分大小写
+	type fkRuleCase struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName,set null,Cascade)"`
+	}
+	m, err = New(&fkRuleCase{})
+	a.NotError(err).NotNil(m)
+	fk = m.FK["fk_name"]
+	a.NotNil(fk).
+		Equal(fk.UpdateRule, "SET NULL").
+		Equal(fk.DeleteRule, "CASCADE")
+
+	// 无效的规则
+	type fkRuleInvalid struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName,CASCADEE)"`
+	}
+	m, err = New(&fkRuleInvalid{})
+	a.Error(err).Nil(m)
+	a.True(strings.Contains(err.Error(), "CASCADEE"))
+
+	type fkDeleteRuleInvalid struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info,firstName,,DELETE)"`
+	}
+	m, err = New(&fkDeleteRuleInvalid{})
+	a.Error(err).Nil(m)
+
+	// 参数不够
+	type fkInvalid struct {
+		First string `orm:"name(first);len(20);fk(fk_name,#user_info)"`
+	}
+	m, err = New(&fkInvalid{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_setUnique(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type cond struct {
+		UID     int64      `orm:"name(uid);unique(u_active,where:{deleted_at} IS NULL)"`
+		Name    string     `orm:"name(name);len(20);unique(u_active)"`
+		Deleted *time.Time `orm:"name(deleted_at)"`
+	}
+	m, err := New(&cond{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.UniqueConds, map[string]string{"u_active": "{deleted_at} IS NULL"})
+	a.Equal(len(m.UniqueIndexes["u_active"]), 2)
+
+	// 删除列之后，条件也一起删除
+	a.NotError(m.RemoveColumn("uid"))
+	a.NotEmpty(m.UniqueConds)
+	a.NotError(m.RemoveColumn("name"))
+	a.Empty(m.UniqueConds)
+	Clear()
+
+	// 条件格式不正确
+	type condInvalid struct {
+		UID int64 `orm:"name(uid);unique(u_active,deleted_at IS NULL)"`
+	}
+	m, err = New(&condInvalid{})
+	a.Error(err).Nil(m)
+
+	// 条件不同
+	type condDiff struct {
+		UID  int64  `orm:"name(uid);unique(u_active,where:uid>0)"`
+		Name string `orm:"name(name);len(20);unique(u_active,where:uid>1)"`
+	}
+	m, err = New(&condDiff{})
+	a.Error(err).Nil(m)
+
+	// 条件中包含逗号和括号
+	type condComma struct {
+		UID int64 `orm:"name(uid);unique(u_active,where:uid IN (1,2))"`
+	}
+	m, err = New(&condComma{})
+	a.Error(err).Nil(m)
+	var perr *ParseError
+	a.True(errors.As(err, &perr)).
+		Equal(perr.Attr, "unique").
+		Equal(perr.Kind, KindValue)
+
+	type condParen struct {
+		UID int64 `orm:"name(uid);unique(u_active,where:abs(uid)>0)"`
+	}
+	m, err = New(&condParen{})
+	a.True(errors.As(err, &perr)).Nil(m).
+		Equal(perr.Kind, KindValue)
+}
+
+// 小写字母开头的匿名字段，应该被忽略
+type unexportedEmbed struct {
+	Email string `orm:"name(email);len(20)"`
+}
+
+type withUnexportedEmbed struct {
+	unexportedEmbed
+
+	ID int64 `orm:"name(id);ai"`
+}
+
+func TestModel_unexportedEmbed(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&withUnexportedEmbed{})
+	a.NotError(err).NotNil(m)
+
+	_, found := m.Cols["id"]
+	a.True(found)
+
+	_, found = m.Cols["email"]
+	a.False(found)
+	a.Equal(1, len(m.Cols))
+}
+
+type embedCreated struct {
+	Created int64 `orm:"name(created)"`
+}
+
+type EmbedCreated struct {
+	Created int64 `orm:"name(created)"`
+}
+
+type EmbedTime struct {
+	Time int64 `orm:"name(created)"`
+}
+
+func TestModel_dupColumn(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	// 嵌入的结构体中存在相同的列名
+	type dup struct {
+		EmbedCreated
+		EmbedTime
+		ID int64 `orm:"name(id);ai"`
+	}
+	m, err := New(&dup{})
+	a.Error(err).Nil(m)
+	a.True(strings.Contains(err.Error(), "created"))
+
+	// 通过 name 修改为相同的列名
+	type dupName struct {
+		EmbedCreated
+		Time int64 `orm:"name(created)"`
+	}
+	m, err = New(&dupName{})
+	a.Error(err).Nil(m)
+	a.True(strings.Contains(err.Error(), "Time"))
+
+	// 未带 struct tag 的字段
+	type dupNoTag struct {
+		Created int64 `orm:"name(Time)"`
+		Time    int64
+	}
+	m, err = New(&dupNoTag{})
+	a.Error(err).Nil(m)
+
+	// 小写的嵌入字段被忽略
+	type noDup struct {
+		embedCreated
+		Created int64 `orm:"name(created)"`
+	}
+	m, err = New(&noDup{})
+	a.NotError(err).NotNil(m)
+}
+
+func TestModel_setUpdated(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type updated struct {
+		ID      int64     `orm:"name(id);ai"`
+		Updated time.Time `orm:"name(updated);updated"`
+	}
+	m, err := New(&updated{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Updated, m.Cols["updated"])
+
+	// 非 time.Time 类型
+	type updatedInt struct {
+		Updated int64 `orm:"name(updated);updated"`
+	}
+	m, err = New(&updatedInt{})
+	a.Error(err).Nil(m)
+
+	// 多个 updated 列
+	type updatedDup struct {
+		Updated1 time.Time `orm:"name(updated1);updated"`
+		Updated2 time.Time `orm:"name(updated2);updated"`
+	}
+	m, err = New(&updatedDup{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_setSoftDelete(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type softDelete struct {
+		ID      int64      `orm:"name(id);ai"`
+		Deleted *time.Time `orm:"name(deleted_at);softdelete"`
+	}
+	m, err := New(&softDelete{})
+	a.NotError(err).NotNil(m)
+	a.True(m.IsSoftDelete()).Equal(m.SoftDelete, m.Cols["deleted_at"])
+
+	type softDeleteNullable struct {
+		Deleted time.Time `orm:"name(deleted_at);softdelete;nullable"`
+	}
+	m, err = New(&softDeleteNullable{})
+	a.NotError(err).NotNil(m)
+	a.True(m.IsSoftDelete())
+
+	// time.Time 必须可以为 NULL
+	type softDeleteNotNull struct {
+		Deleted time.Time `orm:"name(deleted_at);softdelete"`
+	}
+	m, err = New(&softDeleteNotNull{})
+	var perr *ParseError
+	a.True(errors.As(err, &perr)).Nil(m)
+	a.Equal(perr.Attr, "softdelete").Equal(perr.Kind, KindConflict)
+
+	type softDeleteBool struct {
+		Deleted bool `orm:"name(deleted);softdelete"`
+	}
+	m, err = New(&softDeleteBool{})
+	a.NotError(err).NotNil(m)
+	a.True(m.IsSoftDelete())
+
+	// 类型错误
+	type softDeleteInt struct {
+		Deleted int64 `orm:"name(deleted);softdelete"`
+	}
+	m, err = New(&softDeleteInt{})
+	a.Error(err).Nil(m)
+
+	// 多个列
+	type softDeleteDup struct {
+		Deleted1 bool `orm:"name(deleted1);softdelete"`
+		Deleted2 bool `orm:"name(deleted2);softdelete"`
+	}
+	m, err = New(&softDeleteDup{})
+	a.Error(err).Nil(m)
+
+	// 普通的表
+	m, err = New(&modeltest.Group{})
+	a.NotError(err).NotNil(m)
+	a.False(m.IsSoftDelete())
+}
+
+func TestModel_HasAutoIncrement_HasOCC(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type occ struct {
+		ID      int64 `orm:"name(id);ai"`
+		Version int64 `orm:"name(version);occ"`
+	}
+	m, err := New(&occ{})
+	a.NotError(err).NotNil(m)
+	a.True(m.HasAutoIncrement()).True(m.HasOCC())
+	col, found := m.OCCColumn()
+	a.True(found).Equal(col, m.Cols["version"])
+
+	type noOCC struct {
+		ID int64 `orm:"name(id);pk"`
+	}
+	m, err = New(&noOCC{})
+	a.NotError(err).NotNil(m)
+	a.False(m.HasAutoIncrement()).False(m.HasOCC())
+	col, found = m.OCCColumn()
+	a.False(found).Nil(col)
+}
+
+func TestModel_setAutoRandom(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type autoRandom struct {
+		ID   int64  `orm:"name(id);autorandom"`
+		Name string `orm:"name(name);len(20)"`
+	}
+	m, err := New(&autoRandom{})
+	a.NotError(err).NotNil(m)
+	a.True(m.Cols["id"].AutoRandom).
+		Equal(m.AI, m.Cols["id"]).
+		Equal(m.PK, []*Column{m.Cols["id"]})
+
+	type withAI struct {
+		ID int64 `orm:"name(id);autorandom;ai"`
+	}
+	m, err = New(&withAI{})
+	a.Error(err).Nil(m)
+
+	type withDefault struct {
+		ID int64 `orm:"name(id);autorandom;default(1)"`
+	}
+	m, err = New(&withDefault{})
+	a.Error(err).Nil(m)
+
+	type invalidType struct {
+		ID string `orm:"name(id);autorandom;len(20)"`
+	}
+	m, err = New(&invalidType{})
+	a.Error(err).Nil(m)
+
+	type withArgs struct {
+		ID int64 `orm:"name(id);autorandom(5)"`
+	}
+	m, err = New(&withArgs{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_setAI(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type ai struct {
+		ID int64 `orm:"name(id);ai"`
+	}
+	m, err := New(&ai{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.AI.AIStart, 1).Equal(m.AI.AIStep, 1)
+
+	type aiStart struct {
+		ID int64 `orm:"name(id);ai(100)"`
+	}
+	m, err = New(&aiStart{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.AI.AIStart, 100).Equal(m.AI.AIStep, 1)
+
+	type aiStep struct {
+		ID int64 `orm:"name(id);ai(100,2)"`
+	}
+	m, err = New(&aiStep{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.AI.AIStart, 100).Equal(m.AI.AIStep, 2)
+
+	type invalidStart struct {
+		ID int64 `orm:"name(id);ai(0)"`
+	}
+	m, err = New(&invalidStart{})
+	a.Error(err).Nil(m)
+
+	type invalidStep struct {
+		ID int64 `orm:"name(id);ai(1,abc)"`
+	}
+	m, err = New(&invalidStep{})
+	a.Error(err).Nil(m)
+
+	type tooManyArgs struct {
+		ID int64 `orm:"name(id);ai(1,2,3)"`
+	}
+	m, err = New(&tooManyArgs{})
+	a.Error(err).Nil(m)
+}
+
+func TestTimestamps(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type obj struct {
+		ID int64 `orm:"name(id);ai"`
+		Timestamps
+	}
+	m, err := New(&obj{})
+	a.NotError(err).NotNil(m)
+
+	created := m.Cols["created_at"]
+	a.NotNil(created).Equal(created.GoName, "CreatedAt")
+	a.True(created.HasDefault).Equal(created.Default, "CURRENT_TIMESTAMP")
+	a.Equal(m.Updated, m.Cols["updated_at"])
+
+	// 不能再指定其它的 updated 列
+	type dupUpdated struct {
+		Timestamps
+		Modified time.Time `orm:"name(modified);updated"`
+	}
+	m, err = New(&dupUpdated{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_nullInt64(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	// sql.NullInt64 可以作为自增列和乐观锁列
+	type nullInt64 struct {
+		ID      sql.NullInt64 `orm:"name(id);ai"`
+		Version sql.NullInt64 `orm:"name(version);occ"`
+	}
+	m, err := New(&nullInt64{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.AI, m.Cols["id"]).Equal(m.OCC, m.Cols["version"])
+	a.Equal(m.PK, []*Column{m.Cols["id"]})
+
+	type autoRandom struct {
+		ID sql.NullInt64 `orm:"name(id);autorandom"`
+	}
+	m, err = New(&autoRandom{})
+	a.NotError(err).NotNil(m)
+	a.True(m.AI.AutoRandom)
+
+	// 依然不能与 nullable 并存
+	type nullableAI struct {
+		ID sql.NullInt64 `orm:"name(id);ai;nullable"`
+	}
+	m, err = New(&nullableAI{})
+	a.Error(err).Nil(m)
+
+	type nullableOCC struct {
+		Version sql.NullInt64 `orm:"name(version);occ;nullable"`
+	}
+	m, err = New(&nullableOCC{})
+	a.Error(err).Nil(m)
+
+	// 其它 Null 类型依然不可以
+	type nullFloatAI struct {
+		ID sql.NullFloat64 `orm:"name(id);ai"`
+	}
+	m, err = New(&nullFloatAI{})
+	a.Error(err).Nil(m)
+
+	type nullStringOCC struct {
+		Version sql.NullString `orm:"name(version);occ"`
+	}
+	m, err = New(&nullStringOCC{})
+	a.Error(err).Nil(m)
+}
+
+// tags 的处理顺序不固定，需要多次分析以确保结果与顺序无关。
+func TestModel_pointerNullable(t *testing.T) {
+	a := assert.New(t)
+
+	type ptrAI struct {
+		ID *int64 `orm:"name(id);ai;nullable(false)"`
+	}
+
+	type ptrOCC struct {
+		Version *int64 `orm:"name(version);occ;nullable(false)"`
+	}
+
+	type ptrNullableAI struct {
+		ID *int64 `orm:"name(id);ai"`
+	}
+
+	type ptrNullableOCC struct {
+		Version *int64 `orm:"name(version);occ;nullable(true)"`
+	}
+
+	for i := 0; i < 50; i++ {
+		m, err := NewUncached(&ptrAI{})
+		a.NotError(err).NotNil(m)
+		a.Equal(m.AI, m.Cols["id"]).False(m.AI.Nullable)
+
+		m, err = NewUncached(&ptrOCC{})
+		a.NotError(err).NotNil(m)
+		a.Equal(m.OCC, m.Cols["version"]).False(m.OCC.Nullable)
+
+		m, err = NewUncached(&ptrNullableAI{})
+		a.Error(err).Nil(m)
+		perr, ok := err.(*ParseError)
+		a.True(ok).Equal(perr.Kind, KindConflict)
+
+		m, err = NewUncached(&ptrNullableOCC{})
+		a.Error(err).Nil(m)
+		perr, ok = err.(*ParseError)
+		a.True(ok).Equal(perr.Kind, KindConflict)
+	}
+}
+
+func TestModel_setNotPK(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	// 自增列不会替换复合主键
+	type notPK struct {
+		Tenant int64 `orm:"name(tenant);pk"`
+		ID     int64 `orm:"name(id);ai;notpk;index(idx_id)"`
+		Seq    int64 `orm:"name(seq);pk"`
+	}
+	m, err := New(&notPK{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.AI, m.Cols["id"])
+	a.True(m.AI.AINotPK)
+	a.Equal(m.PK, []*Column{m.Cols["tenant"], m.Cols["seq"]})
+	a.Contains(m.String(), "id int64 ai notpk")
+
+	// 未指定 notpk，依然替换主键
+	type aiPK struct {
+		Tenant int64 `orm:"name(tenant);pk"`
+		ID     int64 `orm:"name(id);ai"`
+	}
+	m, err = New(&aiPK{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.PK, []*Column{m.Cols["id"]})
+
+	type withoutAI struct {
+		ID int64 `orm:"name(id);notpk"`
+	}
+	m, err = New(&withoutAI{})
+	a.Error(err).Nil(m)
+
+	type withPK struct {
+		ID int64 `orm:"name(id);ai;notpk;pk"`
+	}
+	m, err = New(&withPK{})
+	a.Error(err).Nil(m)
+
+	type withAutoRandom struct {
+		ID int64 `orm:"name(id);autorandom;notpk"`
+	}
+	m, err = New(&withAutoRandom{})
+	a.Error(err).Nil(m)
+
+	type withArgs struct {
+		ID int64 `orm:"name(id);ai;notpk(true)"`
+	}
+	m, err = New(&withArgs{})
+	a.Error(err).Nil(m)
+}
+
+type address struct {
+	Street string `orm:"name(street);len(50)"`
+	City   string `orm:"name(city);len(20)"`
+}
+
+func TestModel_prefix(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type order struct {
+		ID       int64   `orm:"name(id);ai"`
+		Billing  address `orm:"prefix(billing_)"`
+		Shipping address `orm:"prefix(shipping_)"`
+	}
+	m, err := New(&order{})
+	a.NotError(err).NotNil(m)
+	a.Equal(len(m.Cols), 5)
+
+	street, found := m.Cols["billing_street"]
+	a.True(found).Equal(street.GoName, "Billing.Street").Equal(street.Len1, 50)
+	_, found = m.Cols["shipping_city"]
+	a.True(found)
+
+	o := &order{Billing: address{Street: "street"}}
+	a.Equal(street.FieldValue(reflect.ValueOf(o).Elem()).Interface(), "street")
+
+	// 非结构体
+	type invalidType struct {
+		Billing string `orm:"prefix(billing_)"`
+	}
+	m, err = New(&invalidType{})
+	a.Error(err).Nil(m)
+
+	// 与其它属性同时使用
+	type withOtherAttr struct {
+		Billing address `orm:"prefix(billing_);name(billing)"`
+	}
+	m, err = New(&withOtherAttr{})
+	a.Error(err).Nil(m)
+
+	// 索引和约束名同样加上前缀
+	type indexedAddress struct {
+		Street string `orm:"name(street);len(50);index(idx_street)"`
+		Zip    string `orm:"name(zip);len(10);unique(u_zip)"`
+	}
+	type indexedOrder struct {
+		ID       int64          `orm:"name(id);ai"`
+		Billing  indexedAddress `orm:"prefix(billing_)"`
+		Shipping indexedAddress `orm:"prefix(shipping_)"`
+	}
+	m, err = New(&indexedOrder{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.KeyIndexes["billing_idx_street"], []*Column{m.Cols["billing_street"]}).
+		Equal(m.KeyIndexes["shipping_idx_street"], []*Column{m.Cols["shipping_street"]}).
+		Equal(m.UniqueIndexes["billing_u_zip"], []*Column{m.Cols["billing_zip"]}).
+		Equal(m.UniqueIndexes["shipping_u_zip"], []*Column{m.Cols["shipping_zip"]})
+
+	// 展开后列名相同
+	type dup struct {
+		BillingStreet string  `orm:"name(billing_street)"`
+		Billing       address `orm:"prefix(billing_)"`
+	}
+	m, err = New(&dup{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_WritableColumns(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type writable struct {
+		ID      int64  `orm:"name(id);ai"`
+		Name    string `orm:"name(name);len(20)"`
+		Age     int    `orm:"name(age)"`
+		Created int64  `orm:"name(created);readonly"`
+		Flag    bool   `orm:"name(flag);readonly(false)"`
+	}
+	m, err := New(&writable{})
+	a.NotError(err).NotNil(m)
+	a.True(m.Cols["created"].Readonly).False(m.Cols["flag"].Readonly)
+	a.Equal(m.WritableColumns(), []*Column{m.Cols["age"], m.Cols["flag"], m.Cols["name"]})
+
+	// 无效的值
+	type invalidReadonly struct {
+		Created int64 `orm:"name(created);readonly(abc)"`
+	}
+	m, err = New(&invalidReadonly{})
+	a.Error(err).Nil(m)
+
+	type readonlyArgs struct {
+		Created int64 `orm:"name(created);readonly(true,false)"`
+	}
+	m, err = New(&readonlyArgs{})
+	a.Error(err).Nil(m)
+}
+
+func TestModel_setGenerated(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type generated struct {
+		Price  int64 `orm:"name(price)"`
+		Total  int64 `orm:"name(total);generated({price}*2,stored)"`
+		Double int64 `orm:"name(double);generated({price}*2)"`
+	}
+	m, err := New(&generated{})
+	a.NotError(err).NotNil(m)
+	total := m.Cols["total"]
+	a.Equal(total.Generated, "{price}*2").True(total.GeneratedStored).True(total.Readonly)
+	double := m.Cols["double"]
+	a.Equal(double.Generated, "{price}*2").False(double.GeneratedStored).True(double.Readonly)
+	a.Equal(m.WritableColumns(), []*Column{m.Cols["price"]})
+
+	type withDefault struct {
+		Total int64 `orm:"name(total);generated({price}*2);default(5)"`
+	}
+	m, err = New(&withDefault{})
+	a.Error(err).Nil(m)
+
+	type withAI struct {
+		Total int64 `orm:"name(total);generated({price}*2);ai"`
+	}
+	m, err = New(&withAI{})
+	a.Error(err).Nil(m)
+
+	type invalidKind struct {
+		Total int64 `orm:"name(total);generated({price}*2,abc)"`
+	}
+	m, err = New(&invalidKind{})
+	a.Error(err).Nil(m)
+
+	// 生成列总是只读的
+	type notReadonly struct {
+		Total int64 `orm:"name(total);generated({price}*2);readonly(false)"`
+	}
+	m, err = New(&notReadonly{})
+	a.NotError(err).NotNil(m)
+	a.True(m.Cols["total"].Readonly)
+}
+
+func TestModel_setGeometry(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type geo struct {
+		ID       int64  `orm:"name(id);ai"`
+		Location []byte `orm:"name(location);geometry(POINT);index(idx_location)"`
+	}
+	m, err := New(&geo{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Cols["location"].Geometry, "point")
+
+	// 不支持的类型
+	type geoInvalid struct {
+		Location []byte `orm:"name(location);geometry(circle)"`
+	}
+	m, err = New(&geoInvalid{})
+	a.Error(err).Nil(m)
+
+	// 带默认值
+	type geoDefault struct {
+		Location string `orm:"name(location);geometry(point);default(abc)"`
+	}
+	m, err = New(&geoDefault{})
+	a.Error(err).Nil(m)
+
+	// 乐观锁
+	type geoOCC struct {
+		Location int64 `orm:"name(location);geometry(point);occ"`
+	}
+	m, err = New(&geoOCC{})
+	a.Error(err).Nil(m)
+}
+
+type colCheck struct {
+	Age int `orm:"name(age);check(age_chk,{age}>0)"`
+}
+
+func (c *colCheck) Meta() string {
+	return "check(age_chk,{age}<200)"
+}
+
+func TestModel_setCheck(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	type check struct {
+		ID  int64 `orm:"name(id);ai"`
+		Age int   `orm:"name(age);check(age_chk,{age}>0)"`
+	}
+	m, err := New(&check{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Check["age_chk"], "{age}>0")
+	a.Equal(m.ConstraintNames()["age_chk"], "check")
+
+	// 参数个数不正确
+	type checkInvalid struct {
+		Age int `orm:"name(age);check(age_chk)"`
+	}
+	m, err = New(&checkInvalid{})
+	a.Error(err).Nil(m)
+
+	// 与其它约束重名
+	type checkDupIndex struct {
+		Age int `orm:"name(age);index(age_chk);check(age_chk,{age}>0)"`
+	}
+	m, err = New(&checkDupIndex{})
+	a.Error(err).Nil(m)
+
+	// 与 Metaer 中的 check 重名
+	m, err = New(&colCheck{})
+	a.Error(err).Nil(m)
+}
+
+type conflictIndex struct {
+	Name string `orm:"name(name);len(20);index(index_name)"`
+}
+
+func TestCheckConstraintNames(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	user, err := New(&modeltest.User{})
+	a.NotError(err).NotNil(user)
+	group, err := New(&modeltest.Group{})
+	a.NotError(err).NotNil(group)
+	info, err := New(&modeltest.UserInfo{})
+	a.NotError(err).NotNil(info)
+
+	a.NotError(CheckConstraintNames(user, group))
+
+	// users 和 user_info 都有 chk_name 约束
+	err = CheckConstraintNames(user, group, info)
+	a.Error(err)
+	a.True(strings.Contains(err.Error(), "chk_name 同时存在于 user_info 和 users"))
+
+	// 与 users 中的 index_name 冲突
+	conflict, err := New(&conflictIndex{})
+	a.NotError(err).NotNil(conflict)
+	err = CheckConstraintNames(user, conflict)
+	a.Error(err)
+	a.True(strings.Contains(err.Error(), "index_name 同时存在于 conflictIndex 和 users"))
+
+	// 检测所有缓存的 Model
+	a.Error(CheckConstraintNames())
+
+	Clear()
+	_, err = New(&modeltest.Group{})
+	a.NotError(err)
+	a.NotError(CheckConstraintNames())
+}
+
+func TestModel_String(t *testing.T) {
+	Clear()
+	a := assert.New(t)
+
+	m, err := New(&modeltest.User{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.String(), `table users
+columns:
+  Username string len(50)
+  id int ai
+  password string len(20)
+pk: id
+index index_name: Username
+unique unique_username: Username
+check chk_name: id>0
+meta charset: utf-8
+meta engine: innodb
+`)
+
+	type full struct {
+		ID      int64      `orm:"name(id);pk"`
+		GID     int64      `orm:"name(gid);fk(fk_gid,groups,id,NO ACTION,CASCADE);unsigned"`
+		Name    string     `orm:"name(name);len(20);unique(u_name,where:{deleted} IS NULL);default(abc)"`
+		Version int64      `orm:"name(version);occ"`
+		Deleted *time.Time `orm:"name(deleted);softdelete"`
+		Total   int64      `orm:"name(total);generated({gid}*2,stored)"`
+	}
+	m, err = New(&full{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.String(), `table full
+columns:
+  deleted time.Time nullable softdelete
+  gid int64 unsigned
+  id int64
+  name string len(20) default(abc)
+  total int64 generated({gid}*2,stored) readonly
+  version int64 occ
+pk: id
+unique u_name: name where {deleted} IS NULL
+fk fk_gid: gid references groups(id) on update NO ACTION on delete CASCADE
+`)
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"bytes"
+	"unicode"
+)
+
+// NamingStrategy 命名策略
+//
+// 在未通过 name 属性指定名称时，用于将 Go 中的名称转换成数据库中的表名和列名。
+type NamingStrategy interface {
+	// 将结构体的字段名 goName 转换成列名
+	ColumnName(goName string) string
+
+	// 将结构体的类型名称 goName 转换成表名
+	TableName(goName string) string
+}
+
+// 内置的命名策略
+var (
+	// Identity 原样使用 Go 中的名称，默认的命名策略。
+	Identity NamingStrategy = identity{}
+
+	// SnakeCase 将 Go 中的名称转换成小写的下划线形式，比如 UserID 转换成 user_id。
+	SnakeCase NamingStrategy = snakeCase{}
+)
+
+type identity struct{}
+
+type snakeCase struct{}
+
+func (identity) ColumnName(goName string) string {
+	return goName
+}
+
+func (identity) TableName(goName string) string {
+	return goName
+}
+
+func (snakeCase) ColumnName(goName string) string {
+	return toSnakeCase(goName)
+}
+
+func (snakeCase) TableName(goName string) string {
+	return toSnakeCase(goName)
+}
+
+// 将 PascalCase 形式的名称转换成 snake_case 形式
+//
+// 连续的大写字母被当作一个单词，比如 HTTPServer 转换成 http_server。
+func toSnakeCase(name string) string {
+	rs := []rune(name)
+	buf := new(bytes.Buffer)
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				buf.WriteByte('_')
+			}
+		}
+		buf.WriteRune(unicode.ToLower(r))
+	}
+
+	return buf.String()
+}
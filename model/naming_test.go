@@ -0,0 +1,68 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/issue9/assert"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	a := assert.New(t)
+
+	data := map[string]string{
+		"":           "",
+		"ID":         "id",
+		"Name":       "name",
+		"name":       "name",
+		"UserID":     "user_id",
+		"UserInfo":   "user_info",
+		"HTTPServer": "http_server",
+		"Address2":   "address2",
+		"V2Name":     "v2_name",
+		"user_name":  "user_name",
+	}
+
+	for in, out := range data {
+		a.Equal(toSnakeCase(in), out, "%s 的转换结果为 %s", in, toSnakeCase(in))
+	}
+}
+
+type namingUser struct {
+	UserID    int64  `orm:"ai"`
+	FirstName string `orm:"len(20)"`
+	LastName  string `orm:"name(LastName);len(20)"`
+}
+
+func TestCache_SetNamingStrategy(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewCache()
+	m, err := c.New(&namingUser{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "namingUser").NotNil(m.Cols["UserID"])
+
+	// 修改之后会清除缓存
+	c.SetNamingStrategy(SnakeCase)
+	a.Equal(len(c.items), 0)
+	m, err = c.New(&namingUser{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "naming_user")
+	a.NotNil(m.Cols["user_id"]).NotNil(m.Cols["first_name"])
+	a.NotNil(m.Cols["LastName"]) // name 属性优先
+	a.Equal(m.AI, m.Cols["user_id"])
+
+	// 表名，Metaer 优先
+	m, err = c.New(&tableNamer{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "namers")
+
+	// nil 表示 Identity
+	c.SetNamingStrategy(nil)
+	m, err = c.New(&namingUser{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "namingUser").NotNil(m.Cols["UserID"])
+}
@@ -4,6 +4,8 @@
 
 package model
 
+import "time"
+
 // 预定的约束类型，方便 Model 中使用。
 const (
 	none conType = iota
@@ -15,17 +17,94 @@ const (
 
 type conType int8
 
+// 约束类型的名称，用于 Constraint.Type 以及 Dialect.DropConstraintSQL 等需要区分约束类型的地方。
+const (
+	ConstraintPK     = "pk"
+	ConstraintUnique = "unique"
+	ConstraintFK     = "fk"
+	ConstraintCheck  = "check"
+)
+
 // Metaer 用于指定一个表级别的元数据。如表名，存储引擎等：
 //  "name(tbl_name);engine(myISAM);charset(utf-8)"
 type Metaer interface {
 	Meta() string
 }
 
+// TableNamer 用于指定表名
+//
+// 表名的优先级从高到低依次为：Metaer 中的 name 属性、TableNamer 返回的值以及结构体的类型名称。
+type TableNamer interface {
+	TableName() string
+}
+
+// Timestamps 表示记录的创建时间和更新时间
+//
+// 以匿名字段的形式嵌入到对象中即可得到 created_at 和 updated_at 两列，
+// 两者的默认值都为 CURRENT_TIMESTAMP，插入时若为零值，则由数据库设置为当前时间；
+// UpdatedAt 同时为 updated 列，通过 DB.Update() 等更新数据时会自动设置为当前时间。
+// 生成的列类似于(mysql)：
+//  {created_at} TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+//  {updated_at} TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+// postgres 中的类型为 TIMESTAMPTZ。
+//
+// 由于 updated 每个表只能指定一个，嵌入之后不能再为其它列指定 updated。
+type Timestamps struct {
+	CreatedAt time.Time `orm:"name(created_at);type(timestamp);default(CURRENT_TIMESTAMP)"`
+	UpdatedAt time.Time `orm:"name(updated_at);type(timestamp);default(CURRENT_TIMESTAMP);updated"`
+}
+
 // ForeignKey 外键
+//
+// Cols 与 RefColNames 一一对应，多个元素时表示复合外键。
 type ForeignKey struct {
-	Col                      *Column
-	RefTableName, RefColName string
-	UpdateRule, DeleteRule   string
+	Cols                   []*Column
+	RefTableName           string
+	RefColNames            []string
+	UpdateRule, DeleteRule string
+}
+
+// Partition 表分区
+//
+// 通过 Metaer 中的 partition(range,col) 和 partition_values(name:value,...) 指定，
+// 目前仅支持 range 类型的分区。
+type Partition struct {
+	Type   string           // 分区类型
+	Column *Column          // 分区依据的列
+	Values []PartitionValue // 各个分区，按声明的顺序排列
+}
+
+// PartitionValue 表示 range 分区中的一个分区
+type PartitionValue struct {
+	Name     string // 分区名称
+	LessThan string // 该分区的上限，不包含此值，MAXVALUE 表示无上限
+}
+
+// Constraint 描述一个独立于 Model 的约束
+//
+// 用于在已经存在的表上添加约束，根据 Type 的不同，使用不同的字段：
+// ConstraintPK 和 ConstraintUnique 使用 Cols，ConstraintFK 使用 FK，ConstraintCheck 使用 Check。
+type Constraint struct {
+	Type  string
+	Cols  []*Column
+	FK    *ForeignKey
+	Check string // check 约束的表达式
+}
+
+// 约束类型的简短名称，用于 Model.ConstraintNames 的返回值。
+func (t conType) name() string {
+	switch t {
+	case index:
+		return "index"
+	case unique:
+		return ConstraintUnique
+	case fk:
+		return ConstraintFK
+	case check:
+		return ConstraintCheck
+	default:
+		return ""
+	}
 }
 
 func (t conType) String() string {
@@ -26,3 +26,13 @@ func TestConType_String(t *testing.T) {
 	c1 = 100
 	a.Equal("<unknown>", c1.String())
 }
+
+func TestConType_name(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("", none.name()).
+		Equal("index", index.name()).
+		Equal("unique", unique.name()).
+		Equal("fk", fk.name()).
+		Equal("check", check.name())
+}
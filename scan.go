@@ -0,0 +1,107 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/issue9/orm/fetch"
+	"github.com/issue9/orm/model"
+)
+
+// ScanRows 将 rows 中的所有记录依次追加到 dest 中
+//
+// dest 必须为结构体切片的指针，切片元素可以是结构体或是结构体指针。
+// 结果集中的列与字段的对应关系由 model.New 解析的 Model 决定，
+// 即以列名对应 Model.Cols，再通过 Column.GoName 找到字段，
+// 同样支持匿名字段和通过 prefix 嵌入的字段。
+// 字段可以是 sql.NullString 等实现了 sql.Scanner 的类型或是指针，
+// 结果集中无法与字段对应的列会被忽略。
+//
+// 不会关闭 rows，由调用者负责关闭。
+func ScanRows(rows *sql.Rows, dest interface{}) error {
+	rval := reflect.ValueOf(dest)
+	if rval.Kind() != reflect.Ptr || rval.Elem().Kind() != reflect.Slice {
+		return fetch.ErrInvalidKind
+	}
+
+	slice := rval.Elem()
+	itemType := slice.Type().Elem()
+	isPtr := itemType.Kind() == reflect.Ptr
+	if isPtr {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct {
+		return fetch.ErrInvalidKind
+	}
+
+	m, err := model.New(reflect.New(itemType).Interface())
+	if err != nil {
+		return err
+	}
+
+	cols, err := rowsColumns(rows, m)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		item := reflect.New(itemType)
+		elem := item.Elem()
+		if err := scanRow(rows, cols, elem); err != nil {
+			return err
+		}
+
+		if isPtr {
+			slice = reflect.Append(slice, item)
+		} else {
+			slice = reflect.Append(slice, elem)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rval.Elem().Set(slice)
+	return nil
+}
+
+// 获取 rows 中各列在 m 中对应的 Column，无法对应的列为 nil。
+func rowsColumns(rows *sql.Rows, m *model.Model) ([]*model.Column, error) {
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]*model.Column, len(names))
+	for i, name := range names {
+		cols[i] = m.Cols[name]
+	}
+
+	return cols, nil
+}
+
+// 将 rows 的当前行写入结构体 elem 中，cols 为 rowsColumns 的返回值。
+func scanRow(rows *sql.Rows, cols []*model.Column, elem reflect.Value) error {
+	var discard interface{}
+	targets := make([]interface{}, len(cols))
+	for i, col := range cols {
+		if col == nil {
+			targets[i] = &discard
+			continue
+		}
+
+		field := col.FieldValue(elem)
+		if !field.IsValid() || !field.CanAddr() {
+			return fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+		}
+		targets[i] = field.Addr().Interface()
+	}
+
+	return rows.Scan(targets...)
+}
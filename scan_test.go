@@ -0,0 +1,63 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/fetch"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+func TestScanRows(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	// 结构体切片，包含无法对应的列
+	rows, err := db.Query("SELECT {uid},{sex},1 AS {extra} FROM {#user_info} ORDER BY {uid}")
+	a.NotError(err).NotNil(rows)
+	infos := []modeltest.UserInfo{}
+	a.NotError(orm.ScanRows(rows, &infos))
+	a.NotError(rows.Close())
+	a.Equal(len(infos), 2)
+	a.Equal(infos[0].UID, 1).Equal(infos[0].Sex, "female")
+	a.Equal(infos[1].UID, 2).Equal(infos[1].Sex, "male")
+
+	// 结构体指针切片，包含匿名字段
+	rows, err = db.Query("SELECT * FROM {#administrators}")
+	a.NotError(err).NotNil(rows)
+	admins := []*modeltest.Admin{}
+	a.NotError(orm.ScanRows(rows, &admins))
+	a.NotError(rows.Close())
+	a.Equal(len(admins), 1)
+	a.Equal(admins[0].Username, "username1").Equal(admins[0].Email, "email1")
+
+	// sql.NullString 和指针
+	type nullable struct {
+		UID   int            `orm:"name(uid)"`
+		Note  sql.NullString `orm:"name(note)"`
+		Count *int           `orm:"name(cnt)"`
+	}
+	rows, err = db.Query("SELECT {uid},NULL AS {note},NULL AS {cnt} FROM {#user_info} WHERE {uid}=1")
+	a.NotError(err).NotNil(rows)
+	ns := []nullable{}
+	a.NotError(orm.ScanRows(rows, &ns))
+	a.NotError(rows.Close())
+	a.Equal(len(ns), 1)
+	a.Equal(ns[0].UID, 1).False(ns[0].Note.Valid).Nil(ns[0].Count)
+
+	// 无效的 dest
+	rows, err = db.Query("SELECT {uid} FROM {#user_info}")
+	a.NotError(err).NotNil(rows)
+	a.Equal(orm.ScanRows(rows, infos), fetch.ErrInvalidKind)
+	a.Equal(orm.ScanRows(rows, &[]int{}), fetch.ErrInvalidKind)
+	a.NotError(rows.Close())
+}
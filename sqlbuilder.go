@@ -5,10 +5,18 @@
 package orm
 
 import (
+	"bytes"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/issue9/orm/model"
 	"github.com/issue9/orm/sqlbuilder"
@@ -28,6 +36,87 @@ func getModel(v interface{}) (*model.Model, reflect.Value, error) {
 	return m, rval, nil
 }
 
+// 获取列 col 对应的字段值 field，用于传递给 database/sql。
+//
+// 指定了 serialize 的列，返回序列化之后的文本，
+// 其中 gob 序列化之后的内容以 base64 编码保存；
+// 指定了 pgarray 的列，返回 postgres 数组的文本形式，比如 {1,2,3}。
+func columnValue(col *model.Column, field reflect.Value) (interface{}, error) {
+	if col.PGArray {
+		return pgArrayValue(field), nil
+	}
+
+	if col.Serialize == "" {
+		return field.Interface(), nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, nil
+		}
+		field = field.Elem()
+	}
+
+	switch col.Serialize {
+	case "json":
+		data, err := json.Marshal(field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case "gob":
+		buf := new(bytes.Buffer)
+		if err := gob.NewEncoder(buf).Encode(field.Interface()); err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	default:
+		return nil, fmt.Errorf("列 %s 不支持的序列化方式 %s", col.Name, col.Serialize)
+	}
+}
+
+// 将切片转换成 postgres 数组的文本形式，nil 切片对应 NULL。
+//
+// 字符串和时间类型的元素会被双引号包含，时间统一转换成 UTC。
+// 实现了 driver.Valuer 的类型，比如 pq.Int64Array，原样返回。
+func pgArrayValue(field reflect.Value) interface{} {
+	if v, ok := field.Interface().(driver.Valuer); ok {
+		return v
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if field.IsNil() {
+		return nil
+	}
+
+	elems := make([]string, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		switch v := field.Index(i).Interface().(type) {
+		case time.Time:
+			elems = append(elems, `"`+v.UTC().Format(time.RFC3339Nano)+`"`)
+		case string:
+			v = strings.ReplaceAll(v, `\`, `\\`)
+			elems = append(elems, `"`+strings.ReplaceAll(v, `"`, `\"`)+`"`)
+		case bool:
+			elems = append(elems, strconv.FormatBool(v))
+		case float32:
+			elems = append(elems, strconv.FormatFloat(float64(v), 'g', -1, 32))
+		case float64:
+			elems = append(elems, strconv.FormatFloat(v, 'g', -1, 64))
+		default: // 其它都是整数
+			elems = append(elems, fmt.Sprint(v))
+		}
+	}
+
+	return "{" + strings.Join(elems, ",") + "}"
+}
+
 // 根据 model 中的主键或是唯一索引为 sql 产生 where 语句，
 // 若两者都不存在，则返回错误信息。rval 为 struct 的 reflect.Value
 func where(sql sqlbuilder.WhereStmter, m *model.Model, rval reflect.Value) error {
@@ -35,25 +124,36 @@ func where(sql sqlbuilder.WhereStmter, m *model.Model, rval reflect.Value) error
 	keys := make([]string, 0, 3)
 
 	// 获取构成 where 的键名和键值
-	getKV := func(cols []*model.Column) bool {
+	getKV := func(cols []*model.Column) (bool, error) {
 		for _, col := range cols {
-			field := rval.FieldByName(col.GoName)
+			field := col.FieldValue(rval)
 
-			if !field.IsValid() || col.Zero == field.Interface() {
+			if !field.IsValid() || col.IsZero(field) {
 				vals = vals[:0]
 				keys = keys[:0]
-				return false
+				return false, nil
+			}
+
+			val, err := columnValue(col, field)
+			if err != nil {
+				return false, err
 			}
 
 			keys = append(keys, col.Name)
-			vals = append(vals, field.Interface())
+			vals = append(vals, val)
 		}
-		return len(keys) > 0 // 如果 keys 中有数据，表示已经采集成功，否则表示 cols 的长度为 0
+		return len(keys) > 0, nil // 如果 keys 中有数据，表示已经采集成功，否则表示 cols 的长度为 0
 	}
 
-	if !getKV(m.PK) { // 没有主键，则尝试唯一约束
+	ok, err := getKV(m.PK)
+	if err != nil {
+		return err
+	}
+	if !ok { // 没有主键，则尝试唯一约束
 		for _, cols := range m.UniqueIndexes {
-			if getKV(cols) {
+			if ok, err = getKV(cols); err != nil {
+				return err
+			} else if ok {
 				break
 			}
 		}
@@ -75,15 +175,20 @@ func whereAny(sql sqlbuilder.WhereStmter, m *model.Model, rval reflect.Value) er
 	vals := make([]interface{}, 0, 3)
 	keys := make([]string, 0, 3)
 
-	for _, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
+	for _, col := range m.ColsOrder {
+		field := col.FieldValue(rval)
 
-		if !field.IsValid() || col.Zero == field.Interface() {
+		if !field.IsValid() || col.IsZero(field) {
 			continue
 		}
 
+		val, err := columnValue(col, field)
+		if err != nil {
+			return err
+		}
+
 		keys = append(keys, col.Name)
-		vals = append(vals, field.Interface())
+		vals = append(vals, val)
 	}
 
 	if len(keys) == 0 {
@@ -104,7 +209,7 @@ func count(e Engine, v interface{}) (int64, error) {
 		return 0, err
 	}
 
-	sql := sqlbuilder.Select(e, e.Dialect()).Count("COUNT(*) AS count").From("{#" + m.Name + "}")
+	sql := sqlbuilder.Select(e, e.Dialect()).Count("COUNT(*) AS count").From(m.FullName())
 	if err = whereAny(sql, m, rval); err != nil {
 		return 0, err
 	}
@@ -116,13 +221,13 @@ func count(e Engine, v interface{}) (int64, error) {
 //
 // 部分数据库可能并没有提供在 CREATE TABLE 中直接指定 index 约束的功能。
 // 所以此处把创建表和创建索引分成两步操作。
-func create(e Engine, v interface{}) error {
+func create(e Engine, v interface{}, ifNotExists bool) error {
 	m, _, err := getModel(v)
 	if err != nil {
 		return err
 	}
 
-	sqls, err := e.Dialect().CreateTableSQL(m)
+	sqls, err := e.Dialect().CreateTableSQL(m, ifNotExists)
 	if err != nil {
 		return err
 	}
@@ -143,7 +248,7 @@ func drop(e Engine, v interface{}) error {
 		return err
 	}
 
-	_, err = sqlbuilder.DropTable(e).Table("{#" + m.Name + "}").Exec()
+	_, err = sqlbuilder.DropTable(e).Table(m.FullName()).Exec()
 	return err
 }
 
@@ -154,8 +259,13 @@ func truncate(e Engine, v interface{}) error {
 		return err
 	}
 
-	sql := sqlbuilder.Truncate(e, e.Dialect()).Table("#" + m.Name)
-	if m.AI != nil {
+	table := "#" + m.Name
+	if m.Schema != "" {
+		table = m.Schema + "." + table
+	}
+
+	sql := sqlbuilder.Truncate(e, e.Dialect()).Table(table)
+	if m.HasAutoIncrement() {
 		sql.AI("{" + m.AI.Name + "}")
 	}
 
@@ -163,31 +273,139 @@ func truncate(e Engine, v interface{}) error {
 	return err
 }
 
+// 以数据库中表的当前状态生成 v 的 Model。
+//
+// 目前仅读取自增列下一个值，并将其作为自增列的起始值，
+// 以该 Model 重新创建表时，自增列会接着原来的值继续计数。
+func reverseTable(e Engine, v interface{}) (*model.Model, error) {
+	m, err := model.NewUncached(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.HasAutoIncrement() {
+		return m, nil
+	}
+
+	query, args, err := e.Dialect().NextAIValueSQL(m.Schema, "#"+m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var next sql.NullInt64
+	if rows.Next() {
+		if err = rows.Scan(&next); err != nil {
+			return nil, err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if !next.Valid {
+		return nil, fmt.Errorf("无法获取表 %s 中自增列的值", m.Name)
+	}
+
+	// 以实际的值代替 auto_increment 和 ai(start) 指定的起始值
+	delete(m.Meta, "auto_increment")
+	m.AI.AIStart = next.Int64
+	return m, nil
+}
+
 func insert(e Engine, v interface{}) (sql.Result, error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return nil, err
 	}
 
-	sql := sqlbuilder.Insert(e).Table("{#" + m.Name + "}")
-	for name, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
+	sql := sqlbuilder.Insert(e).Table(m.FullName())
+	returning := false // 是否需要通过 RETURNING 获取自增列的值
+	for _, col := range m.ColsOrder {
+		name := col.Name
+		field := col.FieldValue(rval)
 		if !field.IsValid() {
 			return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
 		}
 
+		if col.Readonly { // 只读列由数据库维护
+			continue
+		}
+
 		// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-		if col.Zero == field.Interface() &&
+		if col.IsZero(field) &&
 			(col.IsAI() || col.HasDefault) {
+			returning = returning || (col.IsAI() && !col.AutoRandom)
 			continue
 		}
 
-		sql.KeyValue("{"+name+"}", field.Interface())
+		val, err := columnValue(col, field)
+		if err != nil {
+			return nil, err
+		}
+		sql.KeyValue("{"+name+"}", val)
 	}
 
+	if returning && !e.Dialect().LastInsertIDSupported() {
+		return insertReturning(e, sql, m.AI.Name)
+	}
 	return sql.Exec()
 }
 
+// 以 INSERT ... RETURNING {ai} 的形式执行插入语句，
+// 用于不支持 sql.Result.LastInsertId() 的数据库。
+func insertReturning(e Engine, stmt *sqlbuilder.InsertStmt, ai string) (sql.Result, error) {
+	if vd, ok := e.Dialect().(VersionedDialect); ok && !vd.SupportsFeature(FeatureReturning, vd.Version()) {
+		return nil, fmt.Errorf("%s %s 不支持 RETURNING 语句", vd.Name(), vd.Version())
+	}
+
+	query, args, err := stmt.SQL()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(query+" RETURNING {"+ai+"}", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("RETURNING 未返回自增列的值")
+	}
+
+	ret := &returningResult{}
+	if err = rows.Scan(&ret.id); err != nil {
+		return nil, err
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// 通过 RETURNING 获取自增列的值的插入结果
+type returningResult struct {
+	id int64
+}
+
+func (r *returningResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+// 单条记录插入，成功即表示影响了一行。
+func (r *returningResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
 // 查找数据。
 //
 // 根据 v 的 pk 或中唯一索引列查找一行数据，并赋值给 v。
@@ -200,13 +418,35 @@ func find(e Engine, v interface{}) error {
 
 	sql := sqlbuilder.Select(e, e.Dialect()).
 		Select("*").
-		From("{#" + m.Name + "}")
+		From(m.FullName())
 	if err = where(sql, m, rval); err != nil {
 		return err
 	}
 
-	_, err = sql.QueryObj(v)
-	return err
+	return queryOne(sql, m, rval)
+}
+
+// 执行查询语句 sql，并将第一行数据写入 rval 中，
+// 列与字段的对应关系由 m 决定，若没有数据，则不作任何修改。
+func queryOne(sql *sqlbuilder.SelectStmt, m *model.Model, rval reflect.Value) error {
+	rows, err := sql.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cols, err := rowsColumns(rows, m)
+	if err != nil {
+		return err
+	}
+
+	if rows.Next() {
+		if err = scanRow(rows, cols, rval); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
 // for update 只能作用于事务
@@ -218,14 +458,13 @@ func forUpdate(tx *Tx, v interface{}) error {
 
 	sql := sqlbuilder.Select(tx, tx.Dialect()).
 		Select("*").
-		From("{#" + m.Name + "}").
+		From(m.FullName()).
 		ForUpdate()
 	if err = where(sql, m, rval); err != nil {
 		return err
 	}
 
-	_, err = sql.QueryObj(v)
-	return err
+	return queryOne(sql, m, rval)
 }
 
 // 更新 v 到数据库，默认情况下不更新零值。
@@ -239,29 +478,47 @@ func update(e Engine, v interface{}, cols ...string) (sql.Result, error) {
 		return nil, err
 	}
 
-	sql := sqlbuilder.Update(e).Table("{#" + m.Name + "}")
+	sql := sqlbuilder.Update(e).Table(m.FullName())
 	var occValue interface{}
-	for name, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
+	for _, col := range m.ColsOrder {
+		name := col.Name
+		field := col.FieldValue(rval)
 		if !field.IsValid() {
 			return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
 		}
 
+		if col.Readonly { // 只读列由数据库维护
+			if inStrSlice(name, cols) {
+				return nil, fmt.Errorf("不能更新只读列 %s", name)
+			}
+			continue
+		}
+
 		// 零值，但是不属于指定需要更新的列
-		if !inStrSlice(name, cols) && col.Zero == field.Interface() {
+		if !inStrSlice(name, cols) && col.IsZero(field) {
 			continue
 		}
 
 		if m.OCC == col { // 乐观锁
 			occValue = field.Interface()
 			continue
+		} else if m.Updated == col && !inStrSlice(name, cols) { // 由 sql.Updated 自动设置
+			continue
 		} else {
-			sql.Set("{"+name+"}", field.Interface())
+			val, err := columnValue(col, field)
+			if err != nil {
+				return nil, err
+			}
+			sql.Set("{"+name+"}", val)
 		}
 	}
 
-	if m.OCC != nil {
-		sql.OCC("{"+m.OCC.Name+"}", occValue)
+	if occ, found := m.OCCColumn(); found {
+		sql.OCC("{"+occ.Name+"}", occValue)
+	}
+
+	if m.Updated != nil {
+		sql.Updated("{" + m.Updated.Name + "}")
 	}
 
 	if err := where(sql, m, rval); err != nil {
@@ -287,7 +544,7 @@ func del(e Engine, v interface{}) (sql.Result, error) {
 		return nil, err
 	}
 
-	sql := sqlbuilder.Delete(e).Table("{#" + m.Name + "}")
+	sql := sqlbuilder.Delete(e).Table(m.FullName())
 	if err = where(sql, m, rval); err != nil {
 		return nil, err
 	}
@@ -311,21 +568,30 @@ func buildInsertManySQL(e *Tx, rval reflect.Value) (*sqlbuilder.InsertStmt, erro
 
 		if i == 0 { // 第一个元素，需要从中获取列信息。
 			firstType = irval.Type()
-			sql.Table("{#" + m.Name + "}")
+			sql.Table(m.FullName())
 
-			for name, col := range m.Cols {
-				field := irval.FieldByName(col.GoName)
+			for _, col := range m.ColsOrder {
+				name := col.Name
+				field := col.FieldValue(irval)
 				if !field.IsValid() {
 					return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
 				}
 
+				if col.Readonly { // 只读列由数据库维护
+					continue
+				}
+
 				// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-				if col.Zero == field.Interface() &&
+				if col.IsZero(field) &&
 					(col.IsAI() || col.HasDefault) {
 					continue
 				}
 
-				sql.KeyValue("{"+name+"}", field.Interface())
+				val, err := columnValue(col, field)
+				if err != nil {
+					return nil, err
+				}
+				sql.KeyValue("{"+name+"}", val)
 				keys = append(keys, name)
 			}
 		} else { // 之后的元素，只需要获取其对应的值就行
@@ -340,18 +606,22 @@ func buildInsertManySQL(e *Tx, rval reflect.Value) (*sqlbuilder.InsertStmt, erro
 					return nil, fmt.Errorf("不存在的列名 %s", name)
 				}
 
-				field := irval.FieldByName(col.GoName)
+				field := col.FieldValue(irval)
 				if !field.IsValid() {
 					return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
 				}
 
 				// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-				if col.Zero == field.Interface() &&
+				if col.IsZero(field) &&
 					(col.IsAI() || col.HasDefault) {
 					continue
 				}
 
-				vals = append(vals, field.Interface())
+				val, err := columnValue(col, field)
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, val)
 			}
 			sql.Values(vals...)
 		}
@@ -15,6 +15,8 @@ type InsertStmt struct {
 	table  string
 	cols   []string
 	args   [][]interface{}
+
+	defaults []string // 使用 DEFAULT 关键字作为值的列
 }
 
 // Insert 声明一条插入语句
@@ -64,11 +66,33 @@ func (stmt *InsertStmt) Values(vals ...interface{}) *InsertStmt {
 	return stmt
 }
 
+// UseDefault 指定这些列在 VALUES 中直接使用 DEFAULT 关键字，
+// 而不是占位符，即采用数据库中定义的默认值。
+//
+// 若 cols 中的列已经通过 Columns 或是 KeyValue 指定，
+// 则其对应的值会被忽略；否则会将这些列添加到插入的列中。
+//
+// NOTE: sqlite3 并不支持在 VALUES 中使用 DEFAULT 关键字。
+func (stmt *InsertStmt) UseDefault(cols ...string) *InsertStmt {
+	stmt.defaults = append(stmt.defaults, cols...)
+	return stmt
+}
+
 // Reset 重置语句
 func (stmt *InsertStmt) Reset() {
 	stmt.table = ""
 	stmt.cols = stmt.cols[:0]
 	stmt.args = stmt.args[:0]
+	stmt.defaults = stmt.defaults[:0]
+}
+
+func inStrSlice(key string, slice []string) bool {
+	for _, v := range slice {
+		if v == key {
+			return true
+		}
+	}
+	return false
 }
 
 // SQL 获取 SQL 的语句及参数部分
@@ -94,11 +118,23 @@ func (stmt *InsertStmt) SQL() (string, []interface{}, error) {
 	buffer := New("INSERT INTO ")
 	buffer.WriteString(stmt.table)
 
+	// 未在 cols 中指定的 DEFAULT 列，需要追加到列的最后。
+	extra := make([]string, 0, len(stmt.defaults))
+	for _, col := range stmt.defaults {
+		if !inStrSlice(col, stmt.cols) && !inStrSlice(col, extra) {
+			extra = append(extra, col)
+		}
+	}
+
 	buffer.WriteByte('(')
 	for _, col := range stmt.cols {
 		buffer.WriteString(col)
 		buffer.WriteByte(',')
 	}
+	for _, col := range extra {
+		buffer.WriteString(col)
+		buffer.WriteByte(',')
+	}
 	buffer.TruncateLast(1)
 	buffer.WriteByte(')')
 
@@ -106,7 +142,12 @@ func (stmt *InsertStmt) SQL() (string, []interface{}, error) {
 	buffer.WriteString(" VALUES ")
 	for _, vals := range stmt.args {
 		buffer.WriteByte('(')
-		for _, v := range vals {
+		for index, v := range vals {
+			if inStrSlice(stmt.cols[index], stmt.defaults) {
+				buffer.WriteString("DEFAULT,")
+				continue
+			}
+
 			if named, ok := v.(sql.NamedArg); ok && named.Name != "" {
 				buffer.WriteByte('@')
 				buffer.WriteString(named.Name)
@@ -116,6 +157,9 @@ func (stmt *InsertStmt) SQL() (string, []interface{}, error) {
 			buffer.WriteByte(',')
 			args = append(args, v)
 		}
+		for range extra {
+			buffer.WriteString("DEFAULT,")
+		}
 		buffer.TruncateLast(1) // 去掉最后的逗号
 		buffer.WriteString("),")
 	}
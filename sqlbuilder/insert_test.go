@@ -77,3 +77,30 @@ func TestInsertError(t *testing.T) {
 	query, args, err = i.Columns("c1", "c2").Values(1).SQL()
 	a.Error(err).Nil(args).Empty(query)
 }
+
+func TestInsert_UseDefault(t *testing.T) {
+	a := assert.New(t)
+	i := Insert(nil).Table("table")
+
+	// c2 已经在 Columns 中指定，其值会被忽略
+	i.Columns("c1", "c2", "c3").
+		Values(1, 2, 3).
+		Values(4, 5, 6).
+		UseDefault("c2")
+	query, args, err := i.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 3, 4, 6})
+	sqltest.Equal(a, query, "insert into table (c1,c2,c3) values (?,DEFAULT,?),(?,DEFAULT,?)")
+
+	// c4 未在 Columns 中指定，追加到最后
+	i.Reset()
+	a.Empty(i.defaults)
+	i.Table("table").
+		KeyValue("c1", 1).
+		KeyValue("c2", sql.Named("c2", 2)).
+		UseDefault("c4")
+	query, args, err = i.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, sql.Named("c2", 2)})
+	sqltest.Equal(a, query, "insert into table (c1,c2,c4) values (?,@c2,DEFAULT)")
+}
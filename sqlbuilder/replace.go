@@ -0,0 +1,42 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package sqlbuilder
+
+// ReplaceQuotes 替换 SQL 语句中的占位符。
+//
+// 将 { 和 } 分别替换成 left 和 right，将 # 替换成表名前缀 prefix，比如：
+//  select * from {#user} where {group}=1
+//  // 以 mysql 为例，prefix 为 p_，转换后
+//  select * from `p_user` where `group`=1
+//
+// 以单引号包含的字符串字面量中的 { 和 } 不会被替换，
+// 但是 # 依然会被替换成表名前缀，以便在字面量中引用表名，比如：
+//  DELETE FROM SQLITE_SEQUENCE WHERE name='#user'
+//
+// 所以包含 # 的字符串值不能直接写在语句中，比如 {tag}='#go' 会被替换成 `tag`='p_go'，
+// 此类值应该以参数的形式传递。
+func ReplaceQuotes(sql string, left, right byte, prefix string) string {
+	buf := New("")
+	buf.buffer().Grow(len(sql) + len(prefix))
+
+	literal := false // 是否处于字符串字面量中
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'':
+			literal = !literal
+			buf.WriteByte(c)
+		case c == '#':
+			buf.WriteString(prefix)
+		case c == '{' && !literal:
+			buf.WriteByte(left)
+		case c == '}' && !literal:
+			buf.WriteByte(right)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/issue9/assert"
+)
+
+func TestReplaceQuotes(t *testing.T) {
+	a := assert.New(t)
+
+	eq := func(sql, want string) {
+		a.Equal(ReplaceQuotes(sql, '`', '`', "p_"), want)
+	}
+
+	eq("", "")
+	eq("select * from user", "select * from user")
+	eq("select * from {#user} where {group}=1", "select * from `p_user` where `group`=1")
+	eq("select * from #user", "select * from p_user")
+
+	// 相邻和嵌套的括号
+	eq("{a}{b}", "`a``b`")
+	eq("{{a}}", "``a``")
+
+	// 字符串字面量中的括号不会被替换，# 依然会被替换
+	eq("update {#user} set {name}='{\"a\":1}' where {id}=1", "update `p_user` set `name`='{\"a\":1}' where `id`=1")
+	eq("{name}='O''{x}'", "`name`='O''{x}'")
+	eq("{name}='{abc", "`name`='{abc")
+	eq("delete from sqlite_sequence where name='#user'", "delete from sqlite_sequence where name='p_user'")
+
+	// 字面量中的 # 无法与表名区分，同样会被替换
+	eq("select * from {#user} where {tag}='#go'", "select * from `p_user` where `tag`='p_go'")
+	a.Equal(ReplaceQuotes("{tag}='#go'", '`', '`', ""), "`tag`='go'")
+
+	a.Equal(ReplaceQuotes("{#user}", '[', ']', ""), "[user]")
+}
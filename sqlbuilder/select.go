@@ -7,6 +7,7 @@ package sqlbuilder
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"strconv"
 
 	"github.com/issue9/orm/fetch"
@@ -34,6 +35,8 @@ type SelectStmt struct {
 
 	limitQuery string
 	limitVals  []interface{}
+
+	err error // 在构建语句过程中产生的错误，由 SQL() 返回
 }
 
 type join struct {
@@ -80,10 +83,16 @@ func (stmt *SelectStmt) Reset() {
 
 	stmt.limitQuery = ""
 	stmt.limitVals = nil
+
+	stmt.err = nil
 }
 
 // SQL 获取 SQL 语句及对应的参数
 func (stmt *SelectStmt) SQL() (string, []interface{}, error) {
+	if stmt.err != nil {
+		return "", nil, stmt.err
+	}
+
 	if stmt.table == "" {
 		return "", nil, ErrTableIsEmpty
 	}
@@ -213,6 +222,26 @@ func (stmt *SelectStmt) Or(cond string, args ...interface{}) *SelectStmt {
 	return stmt
 }
 
+// JSONContains 指定 where ... AND ... 语句，判断 JSON 列 col 中是否包含 val。
+//
+// val 会被编码成 JSON 之后作为参数传递；
+// 若当前数据库不支持该功能，则在调用 SQL() 时返回错误信息。
+func (stmt *SelectStmt) JSONContains(col string, val interface{}) *SelectStmt {
+	expr, err := stmt.dialect.JSONContainsSQL(col)
+	if err != nil {
+		stmt.err = err
+		return stmt
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		stmt.err = err
+		return stmt
+	}
+
+	return stmt.And(expr, string(data))
+}
+
 // Join 添加一条 Join 语句
 func (stmt *SelectStmt) Join(typ, table, on string) *SelectStmt {
 	if stmt.joins == nil {
@@ -287,6 +316,38 @@ func (stmt *SelectStmt) Count(expr string) *SelectStmt {
 	return stmt
 }
 
+// CountSQL 生成查询当前语句结果总数的 SQL 语句及对应的参数
+//
+// 会去掉语句中的 ORDER BY、LIMIT 和 FOR UPDATE 部分，再通过 CountSQL 函数包装。
+// 返回的语句经过 d.SQL() 处理，占位符已经转换成 d 对应的格式。
+func (stmt *SelectStmt) CountSQL(d Dialect) (string, []interface{}, error) {
+	orders, limitQuery, limitVals, forupdate := stmt.orders, stmt.limitQuery, stmt.limitVals, stmt.forupdate
+	stmt.orders, stmt.limitQuery, stmt.limitVals, stmt.forupdate = nil, "", nil, false
+	defer func() {
+		stmt.orders, stmt.limitQuery, stmt.limitVals, stmt.forupdate = orders, limitQuery, limitVals, forupdate
+	}()
+
+	query, args, err := stmt.SQL()
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, err = d.SQL(CountSQL(query))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return query, args, nil
+}
+
+// CountSQL 将查询语句 innerSelect 包装成查询其结果总数的语句
+//  SELECT COUNT(*) FROM (innerSelect) AS _c
+//
+// innerSelect 会原样输出，其中的占位符及对应的参数都不受影响。
+func CountSQL(innerSelect string) string {
+	return "SELECT COUNT(*) FROM (" + innerSelect + ") AS _c"
+}
+
 // Prepare 预编译
 func (stmt *SelectStmt) Prepare() (*sql.Stmt, error) {
 	return prepare(stmt.engine, stmt)
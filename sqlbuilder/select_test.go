@@ -71,3 +71,81 @@ func TestSelect_sqlite3(t *testing.T) {
 	a.NotError(err).Empty(args)
 	sqltest.Equal(a, query, "select c1,c2 from #tb1")
 }
+
+func TestCountSQL(t *testing.T) {
+	a := assert.New(t)
+
+	sqltest.Equal(a, sqlbuilder.CountSQL("SELECT * FROM tbl WHERE id>?"), "SELECT COUNT(*) FROM (SELECT * FROM tbl WHERE id>?) AS _c")
+}
+
+func TestSelect_CountSQL(t *testing.T) {
+	a := assert.New(t)
+
+	s := sqlbuilder.Select(nil, dialect.Sqlite3()).
+		Select("c1", "c2").
+		From("table").
+		Where("c1>?", 1).
+		And("c2=?", 2).
+		Desc("c1").
+		Limit(10, 5).
+		ForUpdate()
+
+	query, args, err := s.CountSQL(dialect.Sqlite3())
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 2})
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM (SELECT c1,c2 FROM table WHERE c1>? AND c2=?) AS _c")
+
+	// 占位符
+	query, args, err = s.CountSQL(dialect.Postgres())
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 2})
+	sqltest.Equal(a, query, "SELECT COUNT(*) FROM (SELECT c1,c2 FROM table WHERE c1>$1 AND c2=$2) AS _c")
+
+	// 原语句不受影响
+	query, args, err = s.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 2, 10, 5})
+	sqltest.Equal(a, query, "SELECT c1,c2 FROM table WHERE c1>? AND c2=? ORDER BY c1 DESC LIMIT ? OFFSET ? FOR UPDATE")
+
+	// 错误
+	s.Reset()
+	query, args, err = s.CountSQL(dialect.Sqlite3())
+	a.Error(err).Empty(query).Nil(args)
+}
+
+func TestSelect_JSONContains(t *testing.T) {
+	a := assert.New(t)
+
+	s := sqlbuilder.Select(nil, dialect.Postgres()).
+		Select("*").
+		From("table").
+		Where("id>?", 5).
+		JSONContains("tags", []string{"go"})
+	query, args, err := s.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{5, `["go"]`})
+	sqltest.Equal(a, query, "select * from table where id>? and tags @> ?")
+
+	s = sqlbuilder.Select(nil, dialect.Mysql()).
+		Select("*").
+		From("table").
+		JSONContains("tags", []string{"go"})
+	query, args, err = s.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{`["go"]`})
+	sqltest.Equal(a, query, "select * from table where JSON_CONTAINS(tags,?)")
+
+	// 不支持的数据库
+	s = sqlbuilder.Select(nil, dialect.Sqlite3()).
+		Select("*").
+		From("table").
+		JSONContains("tags", []string{"go"})
+	query, args, err = s.SQL()
+	a.Equal(err, sqlbuilder.ErrNotSupported).Empty(query).Nil(args)
+
+	// reset 会清除错误信息
+	s.Reset()
+	query, _, err = s.Select("*").From("table").SQL()
+	a.NotError(err)
+	sqltest.Equal(a, query, "select * from table")
+}
@@ -8,6 +8,7 @@ package sqlbuilder
 import (
 	"bytes"
 	"errors"
+	"strconv"
 )
 
 var (
@@ -16,7 +17,7 @@ var (
 	ErrTableIsEmpty = errors.New("表名为空")
 
 	// ErrValueIsEmpty 在 Update 和 Insert 语句中，
-	// 若未指定任何值，则返回此错误
+	// 若未指定任何值，则返回此错误。WhereStmt.In 的值为空时，也返回此错误。
 	ErrValueIsEmpty = errors.New("值为空")
 
 	// ErrColumnsIsEmpty 在 Insert 和 Select 语句中，
@@ -28,6 +29,9 @@ var (
 
 	// ErrArgsNotMatch 在生成的 SQL 语句中，传递的参数与语句的占位符数量不匹配。
 	ErrArgsNotMatch = errors.New("列与值的数量不匹配")
+
+	// ErrNotSupported 当前数据库不支持该功能时，返回此错误。
+	ErrNotSupported = errors.New("当前数据库不支持该功能")
 )
 
 // SQLBuilder 对 bytes.Buffer 的一个简单封装。
@@ -35,6 +39,8 @@ var (
 type SQLBuilder bytes.Buffer
 
 // New 声明一个新的 SQLBuilder 实例
+//
+// New("") 即表示一个全新的空白实例。
 func New(str string) *SQLBuilder {
 	return (*SQLBuilder)(bytes.NewBufferString(str))
 }
@@ -52,6 +58,49 @@ func (b *SQLBuilder) WriteString(str string) *SQLBuilder {
 	return b
 }
 
+// WriteStrings 依次写入多个字符串
+func (b *SQLBuilder) WriteStrings(str ...string) *SQLBuilder {
+	for _, s := range str {
+		b.WriteString(s)
+	}
+
+	return b
+}
+
+// WriteInt 写入一个整数
+//
+// 直接将整数转换后的内容写入缓存，不会产生中间的字符串。
+func (b *SQLBuilder) WriteInt(n int) *SQLBuilder {
+	var arr [20]byte // int64 最长为 20 个字符，包含负号
+	if _, err := b.buffer().Write(strconv.AppendInt(arr[:0], int64(n), 10)); err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// WriteQuotedString 写入一个以单引号包含的字符串字面量
+//
+// s 中的单引号会被转义成两个单引号，其它字符原样输出。
+// 部分数据库(比如 mysql)还会将反斜杠当作转义字符，需要调用者自行处理。
+//  b.WriteQuotedString("O'Brien") // 相当于 b.WriteString("'O''Brien'")
+func (b *SQLBuilder) WriteQuotedString(s string) *SQLBuilder {
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			b.WriteByte('\'')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.WriteByte('\'')
+}
+
+// Quote 写入一个以 {} 包含的名称，在执行时会被替换成当前数据库的引号。
+//  b.Quote("group") // 相当于 b.WriteString("{group}")
+func (b *SQLBuilder) Quote(name string) *SQLBuilder {
+	return b.WriteByte('{').WriteString(name).WriteByte('}')
+}
+
 // WriteByte 写入一字符
 func (b *SQLBuilder) WriteByte(c byte) *SQLBuilder {
 	if err := b.buffer().WriteByte(c); err != nil {
@@ -62,6 +111,9 @@ func (b *SQLBuilder) WriteByte(c byte) *SQLBuilder {
 }
 
 // Reset 重置内容
+//
+// 重置之后的内容长度为 0，但是会保留已经分配的内存，
+// 可以配合 sync.Pool 等重复使用同一个实例，以减少内存分配。
 func (b *SQLBuilder) Reset() *SQLBuilder {
 	b.buffer().Reset()
 	return b
@@ -80,6 +132,10 @@ func (b *SQLBuilder) String() string {
 }
 
 // Bytes 获取表示的字符串
+//
+// 返回值直接引用内部的缓存，不会产生复制操作。
+// 在下一次写入或是 Reset 之后，返回值的内容可能会被改变，
+// 调用者不应该长期持有该值。
 func (b *SQLBuilder) Bytes() []byte {
 	return b.buffer().Bytes()
 }
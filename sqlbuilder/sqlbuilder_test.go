@@ -5,6 +5,7 @@
 package sqlbuilder
 
 import (
+	"math"
 	"testing"
 
 	"github.com/issue9/assert"
@@ -29,4 +30,55 @@ func TestSQLBuilder(t *testing.T) {
 
 	b.TruncateLast(1)
 	a.Equal(b.String(), "32").Equal(2, b.Len())
+
+	b.Reset()
+	b.WriteStrings("1", "", "23").WriteStrings()
+	a.Equal(b.String(), "123")
+
+	b.Reset()
+	b.WriteString("select ").Quote("group").WriteByte(',').Quote("#user")
+	a.Equal(b.String(), "select {group},{#user}")
+
+	// WriteInt
+	b.Reset()
+	b.WriteInt(0).WriteByte(',').WriteInt(-15).WriteByte(',').WriteInt(math.MaxInt32)
+	a.Equal(b.String(), "0,-15,2147483647")
+
+	// WriteQuotedString
+	b.Reset()
+	b.WriteQuotedString("abc").WriteByte(',').
+		WriteQuotedString("O'Brien").WriteByte(',').
+		WriteQuotedString("").WriteByte(',').
+		WriteQuotedString(`c:\dir`)
+	a.Equal(b.String(), `'abc','O''Brien','','c:\dir'`)
+
+	// Bytes 与 Len
+	b.Reset()
+	b.WriteString("abc")
+	a.Equal(b.Bytes(), []byte("abc")).Equal(b.Len(), len(b.Bytes()))
+	a.Equal(b.Bytes()[:b.Len()], []byte(b.String()))
+}
+
+// 模拟 CreateTableSQL 中的语句生成
+func writeCreateTable(b *SQLBuilder) {
+	b.WriteString("CREATE TABLE IF NOT EXISTS ").Quote("#users").WriteByte('(')
+	for _, col := range []string{"id", "username", "password", "email", "group"} {
+		b.Quote(col).WriteString(" VARCHAR(50) NOT NULL,")
+	}
+	b.TruncateLast(1).WriteByte(')')
+}
+
+func BenchmarkSQLBuilder_New(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		writeCreateTable(New(""))
+	}
+}
+
+func BenchmarkSQLBuilder_Reset(b *testing.B) {
+	b.ReportAllocs()
+	buf := New("")
+	for i := 0; i < b.N; i++ {
+		writeCreateTable(buf.Reset())
+	}
 }
@@ -27,11 +27,11 @@ func TestTruncate_sqlite3(t *testing.T) {
 	sql.Table("#tb1")
 	query, args, err := sql.SQL()
 	a.NotError(err).Empty(args)
-	sqltest.Equal(a, query, "delete from #tb1;delete from SQLITE_SEQUENCE WHERE name='#tb1';")
+	sqltest.Equal(a, query, "delete from {#tb1}")
 
 	sql.Reset()
 	sql.Table("#tb1").Table("#tb2").AI("c1")
 	query, args, err = sql.SQL()
 	a.NotError(err).Empty(args)
-	sqltest.Equal(a, query, "delete from #tb2;delete from SQLITE_SEQUENCE WHERE name='#tb2';")
+	sqltest.Equal(a, query, "delete from {#tb2};delete from SQLITE_SEQUENCE WHERE name='#tb2';")
 }
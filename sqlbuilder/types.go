@@ -55,15 +55,38 @@ type Dialect interface {
 	// 比如占位符 postgresql 可以使用 $1 等形式。
 	SQL(sql string) (string, error)
 
+	// 返回第 index 个参数的占位符，index 从 1 开始。
+	//
+	// mysql 和 sqlite3 忽略 index，始终返回 ?；postgres 返回 $1 等形式。
+	// 拼接 SQL 时可以直接使用其返回值，经过 SQL() 时不会再被转换，
+	// 但是同一语句中不能同时出现 ? 和 $1 两种形式的占位符。
+	Placeholder(index int) string
+
 	// 生成 `LIMIT N OFFSET M` 或是相同的语意的语句。
 	//
 	// offset 值为一个可选参数，若不指定，则表示 `LIMIT N` 语句。
+	// limit 为 nil 表示仅指定 offset，不支持单独 OFFSET 语句的数据库，
+	// 会以一个足够大的值代替 limit；两者都未指定时，返回空的语句。
 	// 返回的是对应数据库的 limit 语句以及语句中占位符对应的值。
 	//
 	// limit 和 offset 可以是 sql.NamedArg 类型。
+	//
+	// limit 和 offset 的值都应该以占位符的形式出现在语句中，以便复用预编译的语句；
+	// 唯一的例外是代替 limit 的最大值，该值为固定的常量，直接写在语句中。
+	// 像 SQL Server 的 TOP 子句这类不支持占位符的语法，不应该用于实现此方法，
+	// 而应该采用 OFFSET ... FETCH NEXT 的形式。
 	LimitSQL(limit interface{}, offset ...interface{}) (string, []interface{})
 
+	// 生成判断 JSON 列 col 中是否包含某一值的表达式，该值以占位符 ? 表示。
+	//
+	// 比如 postgresql 中的 `col @> ?`，mysql 中的 `JSON_CONTAINS(col,?)`，
+	// 若当前数据库不支持该功能，则返回 ErrNotSupported。
+	JSONContainsSQL(col string) (string, error)
+
 	// 清空表内容，重置 AI。
+	//
+	// table 为未经引号包含的表名，可以带 # 表名前缀，也可以是 schema.#table 的形式，
+	// 由实现者负责将各段分别包含在 {} 中，以防止与关键字冲突。
 	TruncateTableSQL(table, aiColumn string) string
 
 	// 是否允许在事务中执行 DDL
@@ -8,6 +8,7 @@ import (
 	"context"
 	"database/sql"
 	"sort"
+	"time"
 )
 
 // UpdateStmt 更新语句
@@ -19,6 +20,8 @@ type UpdateStmt struct {
 
 	occColumn string      // 乐观锁的列名
 	occValue  interface{} // 乐观锁的当前值
+
+	updatedColumn string // 自动设置为当前时间的列名
 }
 
 // 表示一条 SET 语句。比如 set key=val
@@ -83,6 +86,14 @@ func (stmt *UpdateStmt) OCC(col string, val interface{}) *UpdateStmt {
 	return stmt
 }
 
+// Updated 指定一个在更新时自动设置为当前时间的列。
+//
+// 若已经通过 Set 等方法指定了该列的值，则不会再自动设置。
+func (stmt *UpdateStmt) Updated(col string) *UpdateStmt {
+	stmt.updatedColumn = col
+	return stmt
+}
+
 // WhereStmt 实现 WhereStmter 接口
 func (stmt *UpdateStmt) WhereStmt() *WhereStmt {
 	return stmt.where
@@ -113,6 +124,8 @@ func (stmt *UpdateStmt) Reset() {
 
 	stmt.occColumn = ""
 	stmt.occValue = nil
+
+	stmt.updatedColumn = ""
 }
 
 // SQL 获取 SQL 语句以及对应的参数
@@ -125,9 +138,17 @@ func (stmt *UpdateStmt) SQL() (string, []interface{}, error) {
 	buf.WriteString(stmt.table)
 	buf.WriteString(" SET ")
 
-	args := make([]interface{}, 0, len(stmt.values))
+	values := stmt.values
+	if stmt.updatedColumn != "" && !stmt.hasColumn(stmt.updatedColumn) {
+		values = append(values[:len(values):len(values)], &updateSet{
+			column: stmt.updatedColumn,
+			value:  time.Now(),
+		})
+	}
 
-	for _, val := range stmt.values {
+	args := make([]interface{}, 0, len(values))
+
+	for _, val := range values {
 		buf.WriteString(val.column)
 		buf.WriteByte('=')
 
@@ -197,6 +218,16 @@ func (stmt *UpdateStmt) checkErrors() error {
 	return nil
 }
 
+// 是否已经通过 Set 等方法指定了 col 列
+func (stmt *UpdateStmt) hasColumn(col string) bool {
+	for _, val := range stmt.values {
+		if val.column == col {
+			return true
+		}
+	}
+	return false
+}
+
 // 检测列名是否存在重复，先排序，再与后一元素比较。
 func (stmt *UpdateStmt) columnsHasDup() bool {
 	sort.SliceStable(stmt.values, func(i, j int) bool {
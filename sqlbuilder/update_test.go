@@ -7,6 +7,7 @@ package sqlbuilder
 import (
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm/internal/sqltest"
@@ -156,3 +157,32 @@ func TestUpdate_occ(t *testing.T) {
 	a.Equal(args, []interface{}{1, 2, 1, 4, sql.Named("c3", 3)})
 	sqltest.Equal(a, query, "update table set c1=?,c2=?, c3=c3+? where (c4=?) and (c3=@c3)")
 }
+
+func TestUpdate_Updated(t *testing.T) {
+	a := assert.New(t)
+	u := Update(nil).Table("table")
+
+	// 自动添加 updated 列
+	u.Set("c1", 1).Updated("updated").Where("id>?", 5)
+	query, args, err := u.SQL()
+	a.NotError(err)
+	a.Equal(3, len(args)).Equal(args[0], 1).Equal(args[2], 5)
+	_, ok := args[1].(time.Time)
+	a.True(ok)
+	sqltest.Equal(a, query, "update table set c1=?,updated=? where id>?")
+
+	// 多次调用 SQL 不会重复添加
+	query, _, err = u.SQL()
+	a.NotError(err)
+	sqltest.Equal(a, query, "update table set c1=?,updated=? where id>?")
+
+	// 手动指定了 updated 列
+	u.Reset()
+	a.Empty(u.updatedColumn)
+	now := time.Now()
+	u.Table("table").Set("c1", 1).Set("updated", now).Updated("updated")
+	query, args, err = u.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, now})
+	sqltest.Equal(a, query, "update table set c1=?,updated=?")
+}
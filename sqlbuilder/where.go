@@ -12,6 +12,15 @@ import (
 type WhereStmt struct {
 	buffer *SQLBuilder
 	args   []interface{}
+	err    error
+}
+
+// Where 声明一个 WhereStmt 实例
+//
+// 一般用于构建子条件语句，再通过 AndWhere 和 OrWhere 添加到其它语句中，
+// 子条件语句会被包含在括号中，以保证 AND 和 OR 混合使用时的优先级。
+func Where() *WhereStmt {
+	return newWhereStmt()
 }
 
 func newWhereStmt() *WhereStmt {
@@ -25,13 +34,20 @@ func newWhereStmt() *WhereStmt {
 func (stmt *WhereStmt) Reset() {
 	stmt.buffer.Reset()
 	stmt.args = stmt.args[:0]
+	stmt.err = nil
 }
 
 // SQL 生成 SQL 语句和对应的参数返回
 func (stmt *WhereStmt) SQL() (string, []interface{}, error) {
+	if stmt.err != nil {
+		return "", nil, stmt.err
+	}
+
 	cnt := 0
-	for _, c := range stmt.buffer.Bytes() {
-		if c == '?' || c == '@' {
+	bs := stmt.buffer.Bytes()
+	for i, c := range bs {
+		// @ 之后必须是命名参数的名称，像 postgresql 中的 @> 操作符不作为占位符
+		if c == '?' || (c == '@' && i+1 < len(bs) && isNameByte(bs[i+1])) {
 			cnt++
 		}
 	}
@@ -42,6 +58,14 @@ func (stmt *WhereStmt) SQL() (string, []interface{}, error) {
 	return stmt.buffer.String(), stmt.args, nil
 }
 
+// 是否为命名参数中可以使用的字符
+func isNameByte(c byte) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
+
 func (stmt *WhereStmt) writeAnd(and bool) {
 	if stmt.buffer.Len() == 0 {
 		stmt.buffer.WriteByte(' ')
@@ -76,7 +100,43 @@ func (stmt *WhereStmt) Or(cond string, args ...interface{}) *WhereStmt {
 	return stmt.where(false, cond, args...)
 }
 
+func (stmt *WhereStmt) in(and bool, col string, vals ...interface{}) *WhereStmt {
+	if len(vals) == 0 {
+		stmt.err = ErrValueIsEmpty
+		return stmt
+	}
+
+	stmt.writeAnd(and)
+	stmt.buffer.WriteString(col).WriteString(" IN(")
+	for range vals {
+		stmt.buffer.WriteString("?,")
+	}
+	stmt.buffer.TruncateLast(1).WriteByte(')')
+	stmt.args = append(stmt.args, vals...)
+
+	return stmt
+}
+
+// In 添加一条 and col IN(...) 语句
+//
+// vals 不能为空，否则 SQL() 会返回 ErrValueIsEmpty。
+func (stmt *WhereStmt) In(col string, vals ...interface{}) *WhereStmt {
+	return stmt.in(true, col, vals...)
+}
+
+// OrIn 添加一条 or col IN(...) 语句
+//
+// vals 不能为空，否则 SQL() 会返回 ErrValueIsEmpty。
+func (stmt *WhereStmt) OrIn(col string, vals ...interface{}) *WhereStmt {
+	return stmt.in(false, col, vals...)
+}
+
 func (stmt *WhereStmt) addWhere(and bool, w *WhereStmt) *WhereStmt {
+	if w.err != nil {
+		stmt.err = w.err
+		return stmt
+	}
+
 	cond := w.buffer.String()
 	if strings.TrimSpace(cond) == "" {
 		return stmt
@@ -43,6 +43,13 @@ func TestWhere(t *testing.T) {
 	w.And("id=?", 5, 7)
 	sql, args, err = w.SQL()
 	a.Equal(err, ErrArgsNotMatch).Nil(args).Empty(sql)
+
+	// @> 不作为命名参数
+	w.Reset()
+	w.And("tags @> ?", "[1]").And("id=@id", 5)
+	sql, args, err = w.SQL()
+	a.NotError(err).Equal(args, []interface{}{"[1]", 5})
+	sqltest.Equal(a, sql, "tags @> ? and id=@id")
 }
 
 func TestWhere_addWhere(t *testing.T) {
@@ -69,3 +76,35 @@ func TestWhere_addWhere(t *testing.T) {
 	a.Equal(args, []interface{}{2, 3, 4, 4})
 	sqltest.Equal(a, query, "(id=? or id=? or(id=?)) or (id=?)")
 }
+
+func TestWhere_In(t *testing.T) {
+	a := assert.New(t)
+
+	w := Where().And("id>?", 1).In("type", 1, 2, 3).OrIn("name", "n1")
+	query, args, err := w.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 1, 2, 3, "n1"})
+	sqltest.Equal(a, query, "id>? and type in(?,?,?) or name in(?)")
+
+	// 与 AND 混合使用
+	w = Where().And("id>?", 1).AndWhere(Where().In("type", 1, 2).Or("type IS NULL"))
+	query, args, err = w.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 1, 2})
+	sqltest.Equal(a, query, "id>? and(type in(?,?) or type is null)")
+
+	// 空值
+	w = Where().In("type")
+	query, args, err = w.SQL()
+	a.Equal(err, ErrValueIsEmpty).Empty(query).Nil(args)
+
+	// 子语句中的错误
+	w = Where().AndWhere(Where().In("type"))
+	_, _, err = w.SQL()
+	a.Equal(err, ErrValueIsEmpty)
+
+	w.Reset()
+	query, _, err = w.And("id=?", 1).SQL()
+	a.NotError(err)
+	sqltest.Equal(a, query, "id=?")
+}
@@ -10,6 +10,7 @@ import (
 	"reflect"
 
 	"github.com/issue9/orm/fetch"
+	"github.com/issue9/orm/model"
 )
 
 // Tx 事务对象
@@ -21,7 +22,14 @@ type Tx struct {
 
 // Begin 开始一个新的事务
 func (db *DB) Begin() (*Tx, error) {
-	tx, err := db.stdDB.Begin()
+	return db.BeginTx(context.Background(), nil)
+}
+
+// BeginTx 开始一个新的事务
+//
+// ctx 和 opts 的说明可参考标准库 database/sql 的 DB.BeginTx()。
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.stdDB.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -42,18 +50,12 @@ func (tx *Tx) StdTx() *sql.Tx {
 
 // Query 执行一条查询语句。
 func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return tx.stdTx.Query(query, args...)
+	return tx.QueryContext(context.Background(), query, args...)
 }
 
 // QueryContext 执行一条查询语句。
 func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	query = tx.db.replacer.Replace(query)
+	query = tx.db.replace(query)
 	query, err := tx.db.dialect.SQL(query)
 	if err != nil {
 		return nil, err
@@ -64,18 +66,12 @@ func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{
 
 // Exec 执行一条 SQL 语句。
 func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return tx.stdTx.Exec(query, args...)
+	return tx.ExecContext(context.Background(), query, args...)
 }
 
 // ExecContext 执行一条 SQL 语句。
 func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	query = tx.db.replacer.Replace(query)
+	query = tx.db.replace(query)
 	query, err := tx.db.dialect.SQL(query)
 	if err != nil {
 		return nil, err
@@ -86,18 +82,12 @@ func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}
 
 // Prepare 将一条 SQL 语句进行预编译。
 func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return tx.stdTx.Prepare(query)
+	return tx.PrepareContext(context.Background(), query)
 }
 
 // PrepareContext 将一条 SQL 语句进行预编译。
 func (tx *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
-	query = tx.db.replacer.Replace(query)
+	query = tx.db.replace(query)
 	query, err := tx.db.dialect.SQL(query)
 	if err != nil {
 		return nil, err
@@ -190,13 +180,20 @@ func (tx *Tx) Count(v interface{}) (int64, error) {
 	return count(tx, v)
 }
 
-// Create 创建数据表。
+// Create 创建数据表，表已经存在时不作任何操作。
 func (tx *Tx) Create(v interface{}) error {
+	return tx.CreateTable(v, true)
+}
+
+// CreateTable 创建数据表
+//
+// 具体说明可参考 DB.CreateTable。
+func (tx *Tx) CreateTable(v interface{}, ifNotExists bool) error {
 	if !tx.db.Dialect().TransactionalDDL() {
-		return tx.db.Create(v)
+		return tx.db.CreateTable(v, ifNotExists)
 	}
 
-	return create(tx, v)
+	return create(tx, v, ifNotExists)
 }
 
 // Drop 删除表结构及数据。
@@ -209,6 +206,13 @@ func (tx *Tx) Truncate(v interface{}) error {
 	return truncate(tx, v)
 }
 
+// ReverseTable 以数据库中表的当前状态生成 v 的 Model
+//
+// 具体说明可参考 DB.ReverseTable。
+func (tx *Tx) ReverseTable(v interface{}) (*model.Model, error) {
+	return reverseTable(tx, v)
+}
+
 // SQL 返回 SQL 实例
 func (tx *Tx) SQL() *SQL {
 	return tx.sql
@@ -6,11 +6,39 @@ package orm
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/issue9/orm/model"
 	"github.com/issue9/orm/sqlbuilder"
 )
 
+// VersionedDialect.SupportsFeature 可用的特性名称
+const (
+	FeatureJSON      = "json"      // JSON 类型
+	FeatureCheck     = "check"     // 会被实际执行的 CHECK 约束
+	FeatureGenerated = "generated" // 生成列
+	FeatureUpsert    = "upsert"    // INSERT ... ON CONFLICT 等插入或更新的语句
+	FeatureReturning = "returning" // INSERT ... RETURNING 语句
+
+	FeatureIndexCollate = "index-collate" // 为索引中的列单独指定排序规则
+)
+
+// VersionedDialect 可以根据数据库的版本判断是否支持某一特性的 Dialect
+//
+// 生成 SQL 时，若当前版本不支持相关的特性，应该返回错误或是以其它方式代替，
+// 而不是生成一条会被数据库静默忽略的语句。
+type VersionedDialect interface {
+	Dialect
+
+	// 当前实例对应的数据库版本号，为空表示最新版本。
+	Version() string
+
+	// 版本号为 version 的数据库是否支持 feature 特性
+	//
+	// version 为空表示最新版本；feature 为未知的特性时返回 false。
+	SupportsFeature(feature, version string) bool
+}
+
 // Engine 是 DB 与 Tx 的共有接口。
 type Engine interface {
 	sqlbuilder.Engine
@@ -30,10 +58,14 @@ type Engine interface {
 
 	Create(v interface{}) error
 
+	CreateTable(v interface{}, ifNotExists bool) error
+
 	Drop(v interface{}) error
 
 	Truncate(v interface{}) error
 
+	ReverseTable(v interface{}) (*model.Model, error)
+
 	MultInsert(objs ...interface{}) error
 
 	MultSelect(objs ...interface{}) error
@@ -55,10 +87,157 @@ type Engine interface {
 type Dialect interface {
 	sqlbuilder.Dialect
 
+	// 当前数据库的名称，比如 mysql, postgres 和 sqlite3 等。
+	//
+	// 可用于在调用者中区分当前使用的数据库。
+	Name() string
+
 	// 生成创建表的 SQL 语句。
 	//
 	// 创建表可能生成多条语句，比如创建表，以及相关的创建索引语句。
-	CreateTableSQL(m *model.Model) ([]string, error)
+	// ifNotExists 表示是否生成 CREATE TABLE IF NOT EXISTS 语句，
+	// 为 false 时生成 CREATE TABLE 语句，表已经存在时，执行会返回错误。
+	CreateTableSQL(m *model.Model, ifNotExists bool) ([]string, error)
+
+	// 返回列 col 在当前数据库中的类型，比如 VARCHAR(20)、BIGINT 等。
+	//
+	// 与 CreateTableSQL 中使用的类型相同，但不包含 NOT NULL、默认值、字符集、
+	// 排序规则以及 CHECK 约束等其它信息。
+	// 可用于与 information_schema 等数据库中实际的类型进行比较。
+	TypeForColumn(col *model.Column) (string, error)
+
+	// 生成创建索引的 SQL 语句。
+	//
+	// tableName 为表名，会原样输出，调用者可以自行决定是否需要 # 等占位符；
+	// unique 表示是否为唯一索引。
+	CreateIndexSQL(tableName, indexName string, cols []*model.Column, unique bool) string
+
+	// 生成删除索引的 SQL 语句。
+	//
+	// tableName 与 CreateIndexSQL 中的相同，会原样输出。
+	// 部分数据库(比如 postgres 和 sqlite3)的索引名在整个数据库中是唯一的，
+	// 此时仅会从 {schema}.{#table} 形式的 tableName 中取出 schema 用于限定索引名。
+	DropIndexSQL(tableName, indexName string) string
+
+	// 生成创建视图的 SQL 语句。
+	//
+	// name 为视图名称，会自动加上表名前缀；selectSQL 为视图的查询语句，会原样输出。
+	// orReplace 表示在视图已经存在的情况下，是否替换该视图。
+	CreateViewSQL(name, selectSQL string, orReplace bool) string
+
+	// 生成在表 table 上添加名为 name 的约束的 SQL 语句。
+	//
+	// table 为未经引号包含的表名，可以带 # 表名前缀；
+	// con.Type 可以是 pk, unique, fk 和 check，
+	// 若当前数据库不支持在已有的表上添加约束，则返回 sqlbuilder.ErrNotSupported。
+	AddConstraintSQL(table, name string, con *model.Constraint) (string, error)
+
+	// 生成删除表 table 上名为 name 的约束的 SQL 语句。
+	//
+	// typ 为约束的类型，取值与 model.Constraint.Type 相同，
+	// 部分数据库删除不同类型的约束时，语法并不相同。
+	// 若当前数据库不支持删除约束，则返回 sqlbuilder.ErrNotSupported。
+	DropConstraintSQL(table, name, typ string) (string, error)
+
+	// 生成在事务中创建名为 name 的保存点的 SQL 语句。
+	//
+	// 通过保存点可以在一个事务中实现嵌套事务的效果，
+	// 目前支持的 mysql、postgres 和 sqlite3 都可以使用保存点，且保存点可以多层嵌套。
+	// name 会原样输出。
+	SavepointSQL(name string) string
+
+	// 生成释放名为 name 的保存点的 SQL 语句。
+	//
+	// 释放之后，保存点之后的修改成为外层事务的一部分。
+	ReleaseSavepointSQL(name string) string
+
+	// 生成回滚到名为 name 的保存点的 SQL 语句。
+	//
+	// 仅撤销保存点之后的修改，外层事务依然有效。
+	RollbackToSavepointSQL(name string) string
+
+	// 以 QuoteTuple() 返回的引号对包含标识符 name。
+	//
+	// 用于在手写的 SQL 中引用表名和列名等，与 {name} 占位符不同，不会添加表名前缀。
+	// schema.table 形式的名称，每一段都会被单独包含；名称中的引号会被转义成两个引号。
+	QuoteIdentifier(name string) string
+
+	// 将 s 转换成当前数据库的字符串字面量，包含两边的单引号。
+	//
+	// 用于在 DDL 中输出默认值等字符串内容，会对其中的特殊字符进行转义。
+	QuoteString(s string) string
+
+	// 将 Go 中的值 v 转换成当前数据库的字面量。
+	//
+	// 用于生成初始数据或是 DEFAULT 表达式等无法使用占位符的场景。
+	// 支持字符串、整数、浮点数、布尔值、time.Time 和 nil(输出 NULL)，
+	// 以及实现了 driver.Valuer 接口的类型，其它类型返回错误。
+	// time.Time 会被转换成 UTC 时间之后输出，且不包含时区信息。
+	FormatValue(v interface{}) (string, error)
+
+	// 生成查询表是否存在的 SQL 语句。
+	//
+	// 返回的语句仅有一行一列，表示表的数量，为 0 表示不存在。
+	// schema 为表所在的 schema，与 model.Model.Schema 相同，为空表示当前默认的 schema；
+	// tableName 为表名，可以带 # 表名前缀，
+	// 会以字符串字面量的形式输出，表名前缀在执行时依然会被替换。
+	ExistsTableSQL(schema, tableName string) (string, []interface{})
+
+	// 生成查询表中自增列下一个值的 SQL 语句。
+	//
+	// 返回的语句仅有一行一列，即下一次插入数据时自增列将使用的值，
+	// 表不存在或是没有自增列时，该值为 NULL 或是没有返回任何行。
+	// schema 和 tableName 与 ExistsTableSQL 中的相同。
+	// 若当前数据库不支持读取该值，则返回 sqlbuilder.ErrNotSupported。
+	NextAIValueSQL(schema, tableName string) (string, []interface{}, error)
+
+	// 是否支持通过 sql.Result.LastInsertId() 获取自增列的值
+	//
+	// 若不支持，则 InsertSQL 生成的语句会通过 RETURNING 等方式返回自增列的值，
+	// 需要以查询的方式执行该语句。
+	LastInsertIDSupported() bool
+
+	// 生成插入一条 m 记录的 SQL 语句。
+	//
+	// 返回值分别为 SQL 语句和需要绑定的字段名称，字段名称为 Go 中的名称，
+	// 其顺序与 SQL 语句中占位符的顺序相同。
+	// includeAI 表示是否包含自增列，一般情况下不需要，由数据库自动生成。
+	// 若 LastInsertIDSupported() 为 false 且未包含自增列，
+	// 则语句会以 RETURNING {ai} 等形式返回自增列的值。
+	// 没有可插入的列时，生成以默认值插入一行数据的语句，比如 DEFAULT VALUES。
+	InsertSQL(m *model.Model, includeAI bool) (string, []string)
+
+	// 生成插入 rowCount 条 m 记录的 SQL 语句。
+	//
+	// 返回的字段名称仅为一行数据的字段，调用者需要按行依次绑定所有数据，
+	// 即总共 rowCount*len(names) 个参数。rowCount 必须大于 0。
+	// 没有可插入的列时，部分数据库只能插入一行数据，rowCount 大于 1 时返回错误。
+	MultiInsertSQL(m *model.Model, rowCount int, includeAI bool) (string, []string, error)
+
+	// 生成插入一条 m 记录的 SQL 语句，若与已有记录冲突，则改为更新该记录。
+	//
+	// conflictCols 为判断冲突的列名，为空表示主键；
+	// updateCols 为冲突时需要更新的列名，为空表示除冲突列、自增列和只读列之外的所有列。
+	// 若存在乐观锁，则冲突时会将其值加 1。
+	// mysql 由所有的主键和唯一约束判断冲突，conflictCols 仅作检测用。
+	// 返回的字段名称为 Go 中的名称，其顺序与 SQL 语句中占位符的顺序相同。
+	// 若当前数据库不支持该语法，则返回 sqlbuilder.ErrNotSupported。
+	UpsertSQL(m *model.Model, conflictCols, updateCols []string) (string, []string, error)
+
+	// 生成根据主键更新一条 m 记录的 SQL 语句。
+	//
+	// cols 为需要更新的列名，为空表示除主键和乐观锁之外的所有列；
+	// 若存在乐观锁，则会将其作为条件之一，同时将其值加 1。
+	// 返回的字段名称为 Go 中的名称，其顺序与 SQL 语句中占位符的顺序相同。
+	// 若不存在主键，则返回错误信息。
+	UpdateSQL(m *model.Model, cols []string) (string, []string, error)
+
+	// 生成根据主键删除一条 m 记录的 SQL 语句。
+	//
+	// 若 m 为软删除，则生成的是更新 SoftDelete 列的 UPDATE 语句。
+	// 返回的字段名称为 Go 中的名称，其顺序与 SQL 语句中占位符的顺序相同。
+	// 若不存在主键，则返回错误信息。
+	DeleteSQL(m *model.Model) (string, []string, error)
 }
 
 // SQL 用于生成 SQL 语句
@@ -81,11 +260,60 @@ func (sql *SQL) Insert() *sqlbuilder.InsertStmt {
 	return sqlbuilder.Insert(sql.engine)
 }
 
+// UpdateWhere 生成针对 v 所对应表的批量更新语句，更新条件需要调用者自行指定。
+//
+// 若 v 中指定了 updated 列，则会自动将该列更新为当前时间，
+// 除非调用者通过 Set 等方法手动指定了该列的值。
+func (sql *SQL) UpdateWhere(v interface{}) (*sqlbuilder.UpdateStmt, error) {
+	m, err := model.New(v)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sqlbuilder.Update(sql.engine).Table(m.FullName())
+	if m.Updated != nil {
+		stmt.Updated("{" + m.Updated.Name + "}")
+	}
+
+	return stmt, nil
+}
+
 // Select 生成插入语句
 func (sql *SQL) Select() *sqlbuilder.SelectStmt {
 	return sqlbuilder.Select(sql.engine, sql.engine.Dialect())
 }
 
+// SelectWhere 生成针对 v 所对应表的查询语句，查询条件、排序和分页需要调用者自行指定。
+//
+// cols 为需要查询的列名，必须是 v 中存在的列，否则返回错误信息，
+// 列名的查找规则与 model.Model.FindColumn 相同；
+// 若未指定 cols，则按 v 中字段的顺序查询所有列。
+func (sql *SQL) SelectWhere(v interface{}, cols ...string) (*sqlbuilder.SelectStmt, error) {
+	m, err := model.New(v)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sqlbuilder.Select(sql.engine, sql.engine.Dialect()).From(m.FullName())
+
+	if len(cols) == 0 {
+		for _, col := range m.ColsOrder {
+			stmt.Select("{" + col.Name + "}")
+		}
+		return stmt, nil
+	}
+
+	for _, name := range cols {
+		col, found := m.FindColumn(name)
+		if !found {
+			return nil, fmt.Errorf("%s 中不存在列 %s", m.Name, name)
+		}
+		stmt.Select("{" + col.Name + "}")
+	}
+
+	return stmt, nil
+}
+
 // CreateIndex 生成创建索引的语句
 func (sql *SQL) CreateIndex() *sqlbuilder.CreateIndexStmt {
 	return sqlbuilder.CreateIndex(sql.engine)